@@ -1,38 +1,159 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
+	"github.com/Hexes-rgb/employee-service/internal/auth"
 	"github.com/Hexes-rgb/employee-service/internal/config"
+	"github.com/Hexes-rgb/employee-service/internal/migrations"
+	"github.com/Hexes-rgb/employee-service/internal/outbox"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
 	"github.com/Hexes-rgb/employee-service/internal/repository/postgres"
 	"github.com/Hexes-rgb/employee-service/internal/server"
 	"github.com/Hexes-rgb/employee-service/internal/service"
 	"github.com/Hexes-rgb/employee-service/internal/transport/rest"
+	"github.com/Hexes-rgb/employee-service/internal/webhook"
 )
 
+type repositories struct {
+	employees   service.EmployeeRepository
+	departments service.DepartmentRepository
+	companies   service.CompanyRepository
+	audit       service.AuditRepository
+	outbox      outboxRepository
+	webhooks    webhookRepository
+	documents   service.DocumentRepository
+	assignments service.AssignmentRepository
+	tx          service.Transactor
+}
+
+// outboxRepository is written to by the services and read by the relay.
+type outboxRepository interface {
+	service.OutboxRepository
+	outbox.Store
+}
+
+// webhookRepository is used by the webhook service and the dispatcher.
+type webhookRepository interface {
+	service.WebhookRepository
+	webhook.Store
+}
+
 func main() {
 	logger := log.New(os.Stdout, "EMPLOYEE-SERVICE: ", log.LstdFlags|log.Lshortfile)
 
 	cfg := config.Load()
 
-	db, err := config.InitDB(cfg.Database, logger)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, logger, os.Args[2:]); err != nil {
+			logger.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	var repos repositories
+	switch cfg.Storage {
+	case config.StorageMemory:
+		logger.Println("Using in-memory storage, data will be lost on restart")
+		store := memory.NewStore()
+		repos = repositories{
+			employees:   memory.NewEmployeeRepo(store),
+			departments: memory.NewDepartmentRepo(store),
+			companies:   memory.NewCompanyRepo(store),
+			audit:       memory.NewAuditRepo(store),
+			outbox:      memory.NewOutboxRepo(store),
+			webhooks:    memory.NewWebhookRepo(store),
+			documents:   memory.NewDocumentRepo(store),
+			assignments: memory.NewAssignmentRepo(store),
+			tx:          store,
+		}
+	case config.StoragePostgres:
+		db, err := config.InitDB(cfg.Database, logger)
+		if err != nil {
+			logger.Fatalf("Database initialization failed: %v", err)
+		}
+		defer func() {
+			if err := db.Close(); err != nil {
+				logger.Printf("Error closing database connection: %v", err)
+			}
+		}()
+
+		if cfg.Database.MigrateOnStart {
+			migrator, err := migrations.New(db, logger)
+			if err != nil {
+				logger.Fatalf("Loading migrations failed: %v", err)
+			}
+			if err := migrator.Up(context.Background()); err != nil {
+				logger.Fatalf("Migration failed: %v", err)
+			}
+		}
+
+		repos = repositories{
+			employees:   postgres.NewEmployeeRepo(db),
+			departments: postgres.NewDepartmentRepo(db),
+			companies:   postgres.NewCompanyRepo(db),
+			audit:       postgres.NewAuditRepo(db),
+			outbox:      postgres.NewOutboxRepo(db),
+			webhooks:    postgres.NewWebhookRepo(db),
+			documents:   postgres.NewDocumentRepo(db),
+			assignments: postgres.NewAssignmentRepo(db),
+			tx:          postgres.NewTransactor(db),
+		}
+	default:
+		logger.Fatalf("Unknown storage %q, expected %q or %q", cfg.Storage, config.StoragePostgres, config.StorageMemory)
+	}
+
+	publisher, err := newPublisher(cfg.Outbox, logger)
 	if err != nil {
-		logger.Fatalf("Database initialization failed: %v", err)
+		logger.Fatalf("Event publisher initialization failed: %v", err)
 	}
 	defer func() {
-		if err := db.Close(); err != nil {
-			logger.Printf("Error closing database connection: %v", err)
+		if err := publisher.Close(); err != nil {
+			logger.Printf("Error closing event publisher: %v", err)
 		}
 	}()
 
-	empRepo := postgres.NewEmployeeRepo(db)
-	deptRepo := postgres.NewDepartmentRepo(db)
+	empService := service.NewEmployeeService(repos.employees, repos.departments, repos.companies, repos.audit, repos.outbox, repos.assignments, repos.documents, repos.tx)
+	deptService := service.NewDepartmentService(repos.departments, repos.companies, repos.outbox, repos.tx)
+	companyService := service.NewCompanyService(repos.companies)
+	importService := service.NewImportService(empService, repos.companies, repos.tx)
+	webhookService := service.NewWebhookService(repos.webhooks, repos.companies)
+	documentService := service.NewDocumentService(repos.documents, repos.employees, repos.companies, repos.tx)
+
+	// Relayed events go to the broker and become webhook deliveries.
+	relay := outbox.NewRelay(repos.outbox, outbox.Fanout{publisher, outbox.PublisherFunc(webhookService.EnqueueEvent)},
+		logger, cfg.Outbox.PollInterval, cfg.Outbox.BatchSize)
+	defer startWorker(relay.Run)()
+
+	dispatcher := webhook.NewDispatcher(repos.webhooks, webhook.NewClient(), logger, cfg.Webhook)
+	defer startWorker(dispatcher.Run)()
 
-	empService := service.NewEmployeeService(empRepo, deptRepo)
-	deptService := service.NewDepartmentService(deptRepo)
+	defer startWorker(func(ctx context.Context) {
+		runTransfers(ctx, empService, logger, cfg.TransferPollInterval)
+	})()
 
-	router := rest.NewRouter(empService, deptService)
+	// A nil verifier leaves the API open; it must be an untyped nil, not a
+	// nil *auth.Verifier.
+	var verifier rest.TokenVerifier
+	switch {
+	case cfg.Auth.Disabled:
+		logger.Printf("WARNING: AUTH_DISABLED is set, the API accepts unauthenticated requests")
+	case !cfg.Auth.Enabled():
+		logger.Fatalf("No token verification keys configured: set AUTH_JWKS_FILE, AUTH_PUBLIC_KEY_FILE or AUTH_HS256_SECRET, or AUTH_DISABLED=true to run without authentication")
+	default:
+		v, err := auth.NewVerifier(cfg.Auth, logger)
+		if err != nil {
+			logger.Fatalf("Token verifier initialization failed: %v", err)
+		}
+		defer startWorker(v.Run)()
+		verifier = v
+	}
+
+	router := rest.NewRouter(empService, deptService, companyService, importService, webhookService, documentService, verifier, cfg.Server.RequestTimeout)
 
 	srv := server.New(cfg.Server, router, logger)
 	if err := srv.Run(); err != nil {
@@ -40,4 +161,75 @@ func main() {
 	}
 
 	srv.WaitForShutdown()
+
+	// Background imports still use the database, so they are drained before
+	// the deferred workers stop and the connections close.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := importService.Shutdown(ctx); err != nil {
+		logger.Printf("Import jobs cancelled on shutdown: %v", err)
+	}
+}
+
+// startWorker runs fn in the background and returns a function that cancels
+// it and waits for it to return.
+func startWorker(fn func(ctx context.Context)) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// transferBatchSize is how many due transfers are applied per poll.
+const transferBatchSize = 100
+
+// runTransfers applies department transfers once their effective date has
+// come, until ctx is cancelled.
+func runTransfers(ctx context.Context, empService *service.EmployeeService, logger *log.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := empService.ApplyDueTransfers(ctx, transferBatchSize)
+		if err != nil && ctx.Err() == nil {
+			logger.Printf("Applying transfers failed: %v", err)
+		}
+		if applied > 0 {
+			logger.Printf("Applied %d department transfers", applied)
+		}
+		// A full batch means more transfers may be due right away.
+		if applied == transferBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func newPublisher(cfg config.OutboxConfig, logger *log.Logger) (outbox.Publisher, error) {
+	switch cfg.Publisher {
+	case config.PublisherLog:
+		return outbox.NewLogPublisher(logger), nil
+	case config.PublisherKafka:
+		logger.Printf("Publishing events to kafka topic %s", cfg.KafkaTopic)
+		return outbox.NewKafkaPublisher(cfg.KafkaBrokers, cfg.KafkaTopic), nil
+	case config.PublisherNATS:
+		logger.Printf("Publishing events to nats stream %s", cfg.NATSStream)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return outbox.NewNATSPublisher(ctx, cfg.NATSURL, cfg.NATSStream, cfg.NATSSubject)
+	default:
+		return nil, fmt.Errorf("unknown event publisher %q, expected %q, %q or %q",
+			cfg.Publisher, config.PublisherLog, config.PublisherKafka, config.PublisherNATS)
+	}
 }
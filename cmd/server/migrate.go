@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Hexes-rgb/employee-service/internal/config"
+	"github.com/Hexes-rgb/employee-service/internal/migrations"
+)
+
+const migrateUsage = "usage: server migrate up | down [steps] | status"
+
+// runMigrate implements the "migrate" subcommand. Down rolls back a single
+// migration unless a number of steps is given.
+func runMigrate(cfg *config.AppConfig, logger *log.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := config.InitDB(cfg.Database, logger)
+	if err != nil {
+		return fmt.Errorf("database initialization failed: %w", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrations.New(db, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied at " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			logger.Printf("%04d_%s: %s", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Signing algorithms accepted in tokens. Every key verifies exactly one of
+// them, so a token cannot choose a different check than its key was meant
+// for, such as HS256 keyed with an RSA public key.
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+	HS256 = "HS256"
+)
+
+const (
+	minRSABits       = 2048
+	minHMACKeyLength = 32
+)
+
+type key struct {
+	// id matches the kid header of tokens; keys from PEM files have none
+	// and are tried for every token of their algorithm.
+	id  string
+	alg string
+	// pub is an *rsa.PublicKey, an *ecdsa.PublicKey or the []byte secret of
+	// an HMAC key.
+	pub interface{}
+}
+
+func (k *key) verify(signingInput, sig []byte) bool {
+	digest := sha256.Sum256(signingInput)
+	switch pub := k.pub.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	case *ecdsa.PublicKey:
+		// JWS encodes ES256 signatures as r || s, 32 bytes each.
+		if len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	case []byte:
+		mac := hmac.New(sha256.New, pub)
+		mac.Write(signingInput)
+		return hmac.Equal(mac.Sum(nil), sig)
+	}
+	return false
+}
+
+// keySet is an immutable set of verification keys.
+type keySet struct {
+	keys []*key
+}
+
+// candidates returns the keys that may have signed a token with the kid and
+// alg headers: the key with that ID if there is one, otherwise keys without
+// an ID, or every key of the algorithm for tokens without a kid.
+func (s *keySet) candidates(kid, alg string) []*key {
+	var matched []*key
+	for _, k := range s.keys {
+		if k.alg != alg {
+			continue
+		}
+		if kid != "" && k.id == kid {
+			return []*key{k}
+		}
+		if kid == "" || k.id == "" {
+			matched = append(matched, k)
+		}
+	}
+	return matched
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS reads the signature keys of a JSON Web Key Set (RFC 7517). Keys
+// for encryption or for algorithms other than RS256, ES256 and HS256 are
+// skipped, so a shared key set can carry keys meant for other services.
+func parseJWKS(data []byte) ([]*key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	var keys []*key
+	for i, j := range set.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		k, err := j.key()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d (kid %q): %w", i, j.Kid, err)
+		}
+		if k != nil {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// key converts j, returning nil for keys of unsupported algorithms.
+func (j *jwk) key() (*key, error) {
+	switch {
+	case j.Kty == "RSA" && (j.Alg == "" || j.Alg == RS256):
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		return &key{id: j.Kid, alg: RS256, pub: pub}, nil
+
+	case j.Kty == "EC" && j.Crv == "P-256" && (j.Alg == "" || j.Alg == ES256):
+		x, errX := base64.RawURLEncoding.DecodeString(j.X)
+		y, errY := base64.RawURLEncoding.DecodeString(j.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 coordinates")
+		}
+		// crypto/ecdh rejects points that are not on the curve.
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, fmt.Errorf("invalid P-256 point: %w", err)
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return &key{id: j.Kid, alg: ES256, pub: pub}, nil
+
+	case j.Kty == "oct" && (j.Alg == "" || j.Alg == HS256):
+		secret, err := base64.RawURLEncoding.DecodeString(j.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret: %w", err)
+		}
+		if len(secret) < minHMACKeyLength {
+			return nil, fmt.Errorf("HMAC keys must have at least %d bytes", minHMACKeyLength)
+		}
+		return &key{id: j.Kid, alg: HS256, pub: secret}, nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// parsePEM reads every public key and certificate in a PEM file. RSA keys
+// verify RS256 and P-256 keys ES256.
+func parsePEM(data []byte) ([]*key, error) {
+	var keys []*key
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var pub interface{}
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			cert, err = x509.ParseCertificate(block.Bytes)
+			if err == nil {
+				pub = cert.PublicKey
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid PEM block %q: %w", block.Type, err)
+		}
+
+		switch pub := pub.(type) {
+		case *rsa.PublicKey:
+			if pub.N.BitLen() < minRSABits {
+				return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+			}
+			keys = append(keys, &key{alg: RS256, pub: pub})
+		case *ecdsa.PublicKey:
+			if pub.Curve != elliptic.P256() {
+				return nil, fmt.Errorf("unsupported curve %s, expected P-256", pub.Curve.Params().Name)
+			}
+			keys = append(keys, &key{alg: ES256, pub: pub})
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", pub)
+		}
+	}
+	return keys, nil
+}
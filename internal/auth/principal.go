@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Principal is whoever a verified token was issued to.
+type Principal struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	Scopes    []string
+	ExpiresAt time.Time
+	// Claims holds every claim of the token, numbers as json.Number.
+	Claims map[string]interface{}
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by WithPrincipal, or nil
+// for unauthenticated requests.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
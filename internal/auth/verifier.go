@@ -0,0 +1,308 @@
+// Package auth verifies the JSON Web Tokens (RFC 7519) that API requests
+// carry as bearer tokens.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidToken is matched by every error Verify returns.
+var ErrInvalidToken = errors.New("invalid token")
+
+type Config struct {
+	// JWKSFile is a JSON Web Key Set with RS256, ES256 or HS256 keys.
+	JWKSFile string
+	// PublicKeyFile holds PEM encoded RSA or P-256 public keys or
+	// certificates.
+	PublicKeyFile string
+	// HS256Secret is a shared secret for development setups without a key
+	// infrastructure.
+	HS256Secret string
+	// Issuer and Audience, if set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// ClockSkew is the leeway allowed when checking exp and nbf.
+	ClockSkew time.Duration
+	// ReloadInterval is how often the key files are checked for changes.
+	ReloadInterval time.Duration
+	// Disabled turns token verification off. Without it, a configuration
+	// with no key source is refused rather than leaving the API open.
+	Disabled bool
+}
+
+// Enabled reports whether any key source is configured.
+func (c Config) Enabled() bool {
+	return c.JWKSFile != "" || c.PublicKeyFile != "" || c.HS256Secret != ""
+}
+
+// Verifier checks token signatures and claims. Its keys can be reloaded
+// while it is in use: by Reload, or by Run when the key files change.
+type Verifier struct {
+	cfg    Config
+	logger *log.Logger
+	now    func() time.Time
+
+	keys atomic.Pointer[keySet]
+	// mu serialises reloads; stamps are the key files as last loaded.
+	mu     sync.Mutex
+	stamps map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewVerifier loads the configured keys. It fails if they cannot be read or
+// there are none.
+func NewVerifier(cfg Config, logger *log.Logger) (*Verifier, error) {
+	v := &Verifier{cfg: cfg, logger: logger, now: time.Now}
+	if err := v.Reload(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Reload reads the key files again. On failure the keys in use are kept.
+func (v *Verifier) Reload() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var keys []*key
+	stamps := make(map[string]fileStamp)
+	load := func(path string, parse func([]byte) ([]*key, error)) error {
+		if path == "" {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read keys: %w", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read keys: %w", err)
+		}
+		parsed, err := parse(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse keys in %s: %w", path, err)
+		}
+		keys = append(keys, parsed...)
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	}
+
+	if err := load(v.cfg.JWKSFile, parseJWKS); err != nil {
+		return err
+	}
+	if err := load(v.cfg.PublicKeyFile, parsePEM); err != nil {
+		return err
+	}
+	if v.cfg.HS256Secret != "" {
+		if len(v.cfg.HS256Secret) < minHMACKeyLength {
+			return fmt.Errorf("HS256 secret must have at least %d bytes", minHMACKeyLength)
+		}
+		keys = append(keys, &key{alg: HS256, pub: []byte(v.cfg.HS256Secret)})
+	}
+	if len(keys) == 0 {
+		return errors.New("no token verification keys configured")
+	}
+
+	v.keys.Store(&keySet{keys: keys})
+	v.stamps = stamps
+	return nil
+}
+
+// Run reloads the keys whenever a key file changes, checking every
+// ReloadInterval until ctx is cancelled. A zero interval disables it.
+func (v *Verifier) Run(ctx context.Context) {
+	if v.cfg.ReloadInterval <= 0 || (v.cfg.JWKSFile == "" && v.cfg.PublicKeyFile == "") {
+		return
+	}
+
+	ticker := time.NewTicker(v.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed := v.changedFiles()
+		if len(changed) == 0 {
+			continue
+		}
+		if err := v.Reload(); err != nil {
+			v.logger.Printf("Reloading token keys failed, keeping the previous ones: %v", err)
+			continue
+		}
+		v.logger.Printf("Reloaded token keys from %s", strings.Join(changed, ", "))
+	}
+}
+
+// changedFiles returns the key files that differ from when they were loaded.
+func (v *Verifier) changedFiles() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var changed []string
+	for _, path := range []string{v.cfg.JWKSFile, v.cfg.PublicKeyFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			// A file that is being replaced is picked up on a later tick.
+			continue
+		}
+		if stamp := v.stamps[path]; !info.ModTime().Equal(stamp.modTime) || info.Size() != stamp.size {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Crit []string `json:"crit"`
+}
+
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	Scope     string   `json:"scope"`
+}
+
+// audience is the aud claim, which is either a string or an array of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// Verify checks the signature and claims of a compact serialized JWT and
+// returns its principal. Tokens must carry sub and exp.
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("token is malformed")
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, invalid("token header is malformed")
+	}
+	if h.Alg != RS256 && h.Alg != ES256 && h.Alg != HS256 {
+		return nil, invalid("unsupported signing algorithm")
+	}
+	if len(h.Crit) > 0 {
+		return nil, invalid("unsupported critical header")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("token signature is malformed")
+	}
+	candidates := v.keys.Load().candidates(h.Kid, h.Alg)
+	if len(candidates) == 0 {
+		return nil, invalid("no key to verify the token with")
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, k := range candidates {
+		if k.verify(signingInput, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, invalid("token signature is invalid")
+	}
+
+	var c claims
+	var all map[string]interface{}
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, invalid("token claims are malformed")
+	}
+	if err := decodeSegment(parts[1], &all); err != nil {
+		return nil, invalid("token claims are malformed")
+	}
+
+	now := v.now()
+	switch {
+	case c.Subject == "":
+		return nil, invalid("token has no subject")
+	case c.ExpiresAt == nil:
+		return nil, invalid("token has no expiry")
+	case now.Add(-v.cfg.ClockSkew).After(unixTime(*c.ExpiresAt)):
+		return nil, invalid("token has expired")
+	case c.NotBefore != nil && now.Add(v.cfg.ClockSkew).Before(unixTime(*c.NotBefore)):
+		return nil, invalid("token is not valid yet")
+	case v.cfg.Issuer != "" && c.Issuer != v.cfg.Issuer:
+		return nil, invalid("token issuer is not accepted")
+	case v.cfg.Audience != "" && !contains(c.Audience, v.cfg.Audience):
+		return nil, invalid("token audience is not accepted")
+	}
+
+	return &Principal{
+		Subject:   c.Subject,
+		Issuer:    c.Issuer,
+		Audience:  c.Audience,
+		Scopes:    strings.Fields(c.Scope),
+		ExpiresAt: unixTime(*c.ExpiresAt),
+		Claims:    all,
+	}, nil
+}
+
+func invalid(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, reason)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
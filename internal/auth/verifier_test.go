@@ -0,0 +1,253 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+var discard = log.New(io.Discard, "", 0)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign builds a compact JWT; signer is an *rsa.PrivateKey, an
+// *ecdsa.PrivateKey or an HMAC secret.
+func sign(t *testing.T, header, claims map[string]interface{}, signer interface{}) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+	input := b64(h) + "." + b64(c)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch k := signer.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	}
+	return input + "." + b64(sig)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "alice",
+		"iss":   "https://idp.example.com",
+		"aud":   []string{"employee-service", "other"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "employees:read employees:write",
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func rsaJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) []byte {
+	t.Helper()
+	var set []map[string]string
+	for kid, k := range keys {
+		set = append(set, map[string]string{
+			"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+			"n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	// Keys for encryption are skipped.
+	set = append(set, map[string]string{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"})
+	data, err := json.Marshal(map[string]interface{}{"keys": set})
+	require.NoError(t, err)
+	return data
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	v, err := auth.NewVerifier(auth.Config{
+		HS256Secret: secret,
+		Issuer:      "https://idp.example.com",
+		Audience:    "employee-service",
+		ClockSkew:   time.Minute,
+	}, discard)
+	require.NoError(t, err)
+	hs := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+
+	p, err := v.Verify(sign(t, hs, validClaims(), []byte(secret)))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Subject)
+	assert.Equal(t, "https://idp.example.com", p.Issuer)
+	assert.Equal(t, []string{"employee-service", "other"}, p.Audience)
+	assert.Equal(t, []string{"employees:read", "employees:write"}, p.Scopes)
+	assert.Equal(t, "employees:read employees:write", p.Claims["scope"])
+
+	singleAudience := validClaims()
+	singleAudience["aud"] = "employee-service"
+	_, err = v.Verify(sign(t, hs, singleAudience, []byte(secret)))
+	assert.NoError(t, err)
+
+	skewed := validClaims()
+	skewed["exp"] = time.Now().Add(-30 * time.Second).Unix()
+	skewed["nbf"] = time.Now().Add(30 * time.Second).Unix()
+	_, err = v.Verify(sign(t, hs, skewed, []byte(secret)))
+	assert.NoError(t, err, "within the clock skew")
+
+	reject := func(name string, token string) {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+	with := func(claim string, value interface{}) map[string]interface{} {
+		c := validClaims()
+		if value == nil {
+			delete(c, claim)
+		} else {
+			c[claim] = value
+		}
+		return c
+	}
+
+	reject("expired", sign(t, hs, with("exp", time.Now().Add(-2*time.Minute).Unix()), []byte(secret)))
+	reject("not yet valid", sign(t, hs, with("nbf", time.Now().Add(2*time.Minute).Unix()), []byte(secret)))
+	reject("no expiry", sign(t, hs, with("exp", nil), []byte(secret)))
+	reject("no subject", sign(t, hs, with("sub", nil), []byte(secret)))
+	reject("wrong issuer", sign(t, hs, with("iss", "https://evil.example.com"), []byte(secret)))
+	reject("wrong audience", sign(t, hs, with("aud", "billing"), []byte(secret)))
+	reject("wrong secret", sign(t, hs, validClaims(), []byte("fedcba9876543210fedcba9876543210")))
+	reject("critical header", sign(t, map[string]interface{}{"alg": "HS256", "crit": []string{"b64"}}, validClaims(), []byte(secret)))
+	reject("malformed", "not-a-token")
+
+	none := strings.Split(sign(t, map[string]interface{}{"alg": "none"}, validClaims(), []byte(secret)), ".")
+	reject("alg none", none[0]+"."+none[1]+".")
+
+	parts := strings.Split(sign(t, hs, validClaims(), []byte(secret)), ".")
+	tampered, err := json.Marshal(with("sub", "mallory"))
+	require.NoError(t, err)
+	reject("tampered", parts[0]+"."+b64(tampered)+"."+parts[2])
+}
+
+func TestVerifier_RS256FromJWKS(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeFile(t, path, rsaJWKS(t, map[string]*rsa.PrivateKey{"first": first}))
+
+	v, err := auth.NewVerifier(auth.Config{JWKSFile: path, ReloadInterval: 10 * time.Millisecond}, discard)
+	require.NoError(t, err)
+
+	p, err := v.Verify(sign(t, map[string]interface{}{"alg": "RS256", "kid": "first"}, validClaims(), first))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Subject)
+
+	secondToken := sign(t, map[string]interface{}{"alg": "RS256", "kid": "second"}, validClaims(), second)
+	_, err = v.Verify(secondToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken, "unknown kid")
+
+	_, err = v.Verify(sign(t, map[string]interface{}{"alg": "RS256", "kid": "first"}, validClaims(), second))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken, "signed by another key")
+
+	// An RSA public key must not be usable as an HMAC secret.
+	n := b64(first.N.Bytes())
+	_, err = v.Verify(sign(t, map[string]interface{}{"alg": "HS256", "kid": "first"}, validClaims(), []byte(n)))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken, "algorithm confusion")
+
+	// A broken file keeps the keys in use.
+	writeFile(t, path, []byte("{"))
+	assert.Error(t, v.Reload())
+	_, err = v.Verify(sign(t, map[string]interface{}{"alg": "RS256", "kid": "first"}, validClaims(), first))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	writeFile(t, path, rsaJWKS(t, map[string]*rsa.PrivateKey{"first": first, "second": second}))
+	assert.Eventually(t, func() bool {
+		_, err := v.Verify(secondToken)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "rotated key picked up without a restart")
+}
+
+func TestVerifier_ES256FromPEM(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keys.pem")
+	writeFile(t, path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	v, err := auth.NewVerifier(auth.Config{PublicKeyFile: path}, discard)
+	require.NoError(t, err)
+
+	p, err := v.Verify(sign(t, map[string]interface{}{"alg": "ES256"}, validClaims(), key))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Subject)
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = v.Verify(sign(t, map[string]interface{}{"alg": "ES256"}, validClaims(), other))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	_, err = v.Verify(sign(t, map[string]interface{}{"alg": "RS256"}, validClaims(), key))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestNewVerifier_RejectsWeakKeys(t *testing.T) {
+	_, err := auth.NewVerifier(auth.Config{HS256Secret: "short"}, discard)
+	assert.Error(t, err)
+
+	_, err = auth.NewVerifier(auth.Config{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}, discard)
+	assert.Error(t, err)
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeFile(t, path, rsaJWKS(t, map[string]*rsa.PrivateKey{"weak": weak}))
+	_, err = auth.NewVerifier(auth.Config{JWKSFile: path}, discard)
+	assert.Error(t, err)
+}
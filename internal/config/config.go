@@ -1,13 +1,42 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/auth"
+	"github.com/Hexes-rgb/employee-service/internal/webhook"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+const (
+	PublisherLog   = "log"
+	PublisherKafka = "kafka"
+	PublisherNATS  = "nats"
 )
 
 type AppConfig struct {
+	// Storage selects the repository backend: StoragePostgres or
+	// StorageMemory. The memory backend is meant for local development and
+	// loses all data on restart.
+	Storage  string
 	Server   ServerConfig
 	Database DatabaseConfig
+	Outbox   OutboxConfig
+	Webhook  webhook.Config
+	// Auth configures bearer token verification. The server refuses to
+	// start without keys unless it is explicitly disabled.
+	Auth auth.Config
+	// TransferPollInterval is how often pending department transfers are
+	// checked for having become effective.
+	TransferPollInterval time.Duration
 }
 
 type ServerConfig struct {
@@ -15,6 +44,10 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// RequestTimeout bounds the context of every API request, so database
+	// queries are cancelled before WriteTimeout drops the connection.
+	RequestTimeout  time.Duration
+	ShutdownTimeout time.Duration
 }
 
 type DatabaseConfig struct {
@@ -26,15 +59,36 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// MigrateOnStart applies pending schema migrations before the server
+	// starts accepting requests.
+	MigrateOnStart bool
+}
+
+type OutboxConfig struct {
+	// Publisher selects where outbox events are relayed: PublisherLog,
+	// PublisherKafka or PublisherNATS.
+	Publisher    string
+	PollInterval time.Duration
+	BatchSize    int
+	KafkaBrokers []string
+	KafkaTopic   string
+	NATSURL      string
+	NATSStream   string
+	// NATSSubject prefixes the subjects events are published to; the event
+	// type is appended.
+	NATSSubject string
 }
 
 func Load() *AppConfig {
 	return &AppConfig{
+		Storage: getEnv("STORAGE", StoragePostgres),
 		Server: ServerConfig{
-			Port:         getEnv("APP_PORT", "8080"),
-			ReadTimeout:  10 * time.Second,
-			WriteTimeout: 10 * time.Second,
-			IdleTimeout:  15 * time.Second,
+			Port:            getEnv("APP_PORT", "8080"),
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     15 * time.Second,
+			RequestTimeout:  getEnvDuration("REQUEST_TIMEOUT", 8*time.Second),
+			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "employee-service-db"),
@@ -45,7 +99,37 @@ func Load() *AppConfig {
 			MaxOpenConns:    25,
 			MaxIdleConns:    25,
 			ConnMaxLifetime: 5 * time.Minute,
+			MigrateOnStart:  getEnvBool("MIGRATE_ON_START", false),
+		},
+		Outbox: OutboxConfig{
+			Publisher:    getEnv("EVENT_PUBLISHER", PublisherLog),
+			PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 100),
+			KafkaBrokers: strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ","),
+			KafkaTopic:   getEnv("KAFKA_TOPIC", "employee-events"),
+			NATSURL:      getEnv("NATS_URL", "nats://localhost:4222"),
+			NATSStream:   getEnv("NATS_STREAM", "EMPLOYEE_EVENTS"),
+			NATSSubject:  getEnv("NATS_SUBJECT", "employees.events"),
+		},
+		Webhook: webhook.Config{
+			PollInterval: getEnvDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+			Timeout:      getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryDelay:   getEnvDuration("WEBHOOK_RETRY_DELAY", 30*time.Second),
+			BatchSize:    100,
+			Workers:      getEnvInt("WEBHOOK_WORKERS", 4),
 		},
+		Auth: auth.Config{
+			JWKSFile:       getEnv("AUTH_JWKS_FILE", ""),
+			PublicKeyFile:  getEnv("AUTH_PUBLIC_KEY_FILE", ""),
+			HS256Secret:    getEnv("AUTH_HS256_SECRET", ""),
+			Issuer:         getEnv("AUTH_ISSUER", ""),
+			Audience:       getEnv("AUTH_AUDIENCE", ""),
+			ClockSkew:      getEnvDuration("AUTH_CLOCK_SKEW", time.Minute),
+			ReloadInterval: getEnvDuration("AUTH_KEYS_RELOAD_INTERVAL", time.Minute),
+			Disabled:       getEnvBool("AUTH_DISABLED", false),
+		},
+		TransferPollInterval: getEnvDuration("TRANSFER_POLL_INTERVAL", time.Minute),
 	}
 }
 
@@ -55,3 +139,42 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration %q for %s, using %s", value, key, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid positive integer %q for %s, using %d", value, key, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean %q for %s, using %t", value, key, defaultValue)
+		return defaultValue
+	}
+	return b
+}
@@ -0,0 +1,26 @@
+package domain
+
+// Assignment is a period an employee spent, spends or will spend in a
+// department. Periods are half-open: EndDate is the day the employee left,
+// which is the StartDate of their next assignment if they moved. The last
+// assignment of an employee who is still in a department has no EndDate; it
+// may start in the future if a transfer is pending.
+type Assignment struct {
+	ID           int   `json:"id"`
+	EmployeeID   int   `json:"employeeId"`
+	DepartmentID int   `json:"departmentId"`
+	StartDate    Date  `json:"startDate"`
+	EndDate      *Date `json:"endDate"`
+}
+
+// Covers reports whether the employee was in the department on day.
+func (a *Assignment) Covers(day Date) bool {
+	return !a.StartDate.After(day.Time) && (a.EndDate == nil || a.EndDate.After(day.Time))
+}
+
+// Transfer moves an employee to another department of their company on
+// EffectiveDate, today if it is not set.
+type Transfer struct {
+	DepartmentID  int   `json:"departmentId"`
+	EffectiveDate *Date `json:"effectiveDate"`
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAuditChainBroken is returned by VerifyAuditChain when an entry was
+// altered, removed or reordered.
+var ErrAuditChainBroken = errors.New("audit chain is broken")
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// FieldChange is the value of one employee field before and after a write,
+// both as JSON. A null Before means the field did not exist yet (create,
+// restore), a null After that it no longer does (delete).
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditEntry records one write to an employee. Entries of an employee form a
+// hash chain: Seq counts from 1, PrevHash is the Hash of the previous entry
+// (empty for the first one) and Hash covers every other field, so an
+// exported history can be checked with VerifyAuditChain.
+type AuditEntry struct {
+	ID         int           `json:"id"`
+	EmployeeID int           `json:"employeeId"`
+	Seq        int           `json:"seq"`
+	Action     AuditAction   `json:"action"`
+	Actor      string        `json:"actor"`
+	RequestID  string        `json:"requestId,omitempty"`
+	OccurredAt time.Time     `json:"occurredAt"`
+	Changes    []FieldChange `json:"changes"`
+	PrevHash   string        `json:"prevHash"`
+	Hash       string        `json:"hash"`
+}
+
+// Seal links the entry to prev, the latest entry of the same employee or nil
+// if there is none, and computes its hash. OccurredAt is normalised to UTC
+// microseconds, the precision it is stored with.
+func (e *AuditEntry) Seal(prev *AuditEntry) {
+	e.Seq, e.PrevHash = 1, ""
+	if prev != nil {
+		e.Seq, e.PrevHash = prev.Seq+1, prev.Hash
+	}
+	e.OccurredAt = e.OccurredAt.UTC().Truncate(time.Microsecond)
+	e.Hash = e.computeHash()
+}
+
+func (e *AuditEntry) computeHash() string {
+	payload, err := json.Marshal(struct {
+		EmployeeID int           `json:"employeeId"`
+		Seq        int           `json:"seq"`
+		Action     AuditAction   `json:"action"`
+		Actor      string        `json:"actor"`
+		RequestID  string        `json:"requestId"`
+		OccurredAt string        `json:"occurredAt"`
+		Changes    []FieldChange `json:"changes"`
+		PrevHash   string        `json:"prevHash"`
+	}{
+		EmployeeID: e.EmployeeID,
+		Seq:        e.Seq,
+		Action:     e.Action,
+		Actor:      e.Actor,
+		RequestID:  e.RequestID,
+		OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Changes:    e.Changes,
+		PrevHash:   e.PrevHash,
+	})
+	if err != nil {
+		// Only invalid RawMessage values can get here, and those never
+		// verify anyway.
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain checks that entries are the complete, untampered history
+// of one employee, oldest first.
+func VerifyAuditChain(entries []*AuditEntry) error {
+	var prev *AuditEntry
+	for _, e := range entries {
+		switch {
+		case prev == nil && (e.Seq != 1 || e.PrevHash != ""):
+			return fmt.Errorf("%w: history does not start at entry 1", ErrAuditChainBroken)
+		case prev != nil && (e.Seq != prev.Seq+1 || e.PrevHash != prev.Hash || e.EmployeeID != prev.EmployeeID):
+			return fmt.Errorf("%w: entry %d does not follow entry %d", ErrAuditChainBroken, e.Seq, prev.Seq)
+		case e.Hash != e.computeHash():
+			return fmt.Errorf("%w: entry %d has been altered", ErrAuditChainBroken, e.Seq)
+		}
+		prev = e
+	}
+	return nil
+}
+
+// auditedFields lists the employee fields that are diffed, by JSON name.
+var auditedFields = []struct {
+	name  string
+	value func(*Employee) interface{}
+}{
+	{"name", func(e *Employee) interface{} { return e.Name }},
+	{"surname", func(e *Employee) interface{} { return e.Surname }},
+	{"phone", func(e *Employee) interface{} { return e.Phone }},
+	{"companyId", func(e *Employee) interface{} { return e.CompanyID }},
+	{"departmentId", func(e *Employee) interface{} { return e.DepartmentID }},
+	{"passportType", func(e *Employee) interface{} { return e.PassportType }},
+	{"passportNumber", func(e *Employee) interface{} { return e.PassportNumber }},
+	{"managerId", func(e *Employee) interface{} { return e.ManagerID }},
+}
+
+// DiffEmployees returns the fields that differ between before and after. A
+// nil side stands for an employee that does not exist, so every field is
+// reported with null on that side.
+func DiffEmployees(before, after *Employee) []FieldChange {
+	changes := []FieldChange{}
+	for _, f := range auditedFields {
+		b, a := auditValue(before, f.value), auditValue(after, f.value)
+		if !bytes.Equal(b, a) {
+			changes = append(changes, FieldChange{Field: f.name, Before: b, After: a})
+		}
+	}
+	return changes
+}
+
+func auditValue(emp *Employee, value func(*Employee) interface{}) json.RawMessage {
+	if emp == nil {
+		return json.RawMessage("null")
+	}
+	// Strings, ints and *int always marshal.
+	v, _ := json.Marshal(value(emp))
+	return v
+}
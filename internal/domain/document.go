@@ -0,0 +1,38 @@
+package domain
+
+// DefaultDocumentType is the type of the primary document of an employee
+// without a passportType.
+const DefaultDocumentType = "passport"
+
+// PassportDocumentType returns the type of the primary document of an
+// employee with the given passportType.
+func PassportDocumentType(passportType string) string {
+	if passportType == "" {
+		return DefaultDocumentType
+	}
+	return passportType
+}
+
+// IdentityDocument is a passport, work permit or other identity document of
+// an employee. The type and number together identify a document; the same
+// number may be used by documents of different types.
+type IdentityDocument struct {
+	ID             int    `json:"id"`
+	EmployeeID     int    `json:"employeeId"`
+	Type           string `json:"type"`
+	Number         string `json:"number"`
+	IssuingCountry string `json:"issuingCountry"`
+	IssuedOn       *Date  `json:"issuedOn"`
+	ExpiresOn      *Date  `json:"expiresOn"`
+	// Primary marks the document the employee is identified by. An employee
+	// has at most one primary document.
+	Primary bool `json:"primary"`
+}
+
+// ExpiringDocument is a document found by an expiry query, with the employee
+// it belongs to.
+type ExpiringDocument struct {
+	IdentityDocument
+	EmployeeName    string `json:"employeeName"`
+	EmployeeSurname string `json:"employeeSurname"`
+}
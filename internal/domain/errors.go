@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrUnavailable = errors.New("service unavailable")
+	// ErrVersionMismatch is matched by VersionConflictError.
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+type NotFoundError struct {
+	Entity string
+}
+
+func NewNotFoundError(entity string) *NotFoundError {
+	return &NotFoundError{Entity: entity}
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Entity + " not found"
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError reports a uniqueness violation. Field holds the JSON name of
+// the violated field so clients can point at it without parsing the message.
+type ConflictError struct {
+	Entity string
+	Field  string
+}
+
+func NewConflictError(entity, field string) *ConflictError {
+	return &ConflictError{Entity: entity, Field: field}
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s with this %s already exists", e.Entity, e.Field)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// VersionConflictError reports that an entity was changed since the caller
+// read it at version Expected, so the caller's write would overwrite someone
+// else's. It matches both ErrVersionMismatch and ErrConflict.
+type VersionConflictError struct {
+	Entity   string
+	Expected int
+}
+
+func NewVersionConflictError(entity string, expected int) *VersionConflictError {
+	return &VersionConflictError{Entity: entity, Expected: expected}
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s has been modified since version %d", e.Entity, e.Expected)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionMismatch || target == ErrConflict
+}
+
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// UnavailableError wraps infrastructure failures (lost connections, timeouts)
+// that are worth retrying, as opposed to bugs or bad input.
+type UnavailableError struct {
+	Err error
+}
+
+func NewUnavailableError(err error) *UnavailableError {
+	return &UnavailableError{Err: err}
+}
+
+func (e *UnavailableError) Error() string {
+	return "storage unavailable: " + e.Err.Error()
+}
+
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
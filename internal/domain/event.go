@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventEmployeeCreated   EventType = "EmployeeCreated"
+	EventEmployeeUpdated   EventType = "EmployeeUpdated"
+	EventEmployeeDeleted   EventType = "EmployeeDeleted"
+	EventEmployeeRestored  EventType = "EmployeeRestored"
+	EventDepartmentCreated EventType = "DepartmentCreated"
+	EventDepartmentUpdated EventType = "DepartmentUpdated"
+	EventDepartmentDeleted EventType = "DepartmentDeleted"
+)
+
+const (
+	AggregateEmployee   = "employee"
+	AggregateDepartment = "department"
+)
+
+// Event is a change published to other systems. Events are stored in the
+// outbox in the transaction of the change and relayed afterwards, in ID
+// order for each aggregate.
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          EventType       `json:"type"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   int             `json:"aggregateId"`
+	CompanyID     int             `json:"companyId"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+}
+
+// Key identifies the aggregate; brokers use it to keep the events of one
+// aggregate in order.
+func (e *Event) Key() string {
+	return e.AggregateType + ":" + strconv.Itoa(e.AggregateID)
+}
+
+// EmployeeEventPayload is the payload of employee events: the employee after
+// the change (before it, for EmployeeDeleted) and, for EmployeeUpdated, the
+// fields that changed.
+type EmployeeEventPayload struct {
+	Employee *Employee     `json:"employee"`
+	Changes  []FieldChange `json:"changes,omitempty"`
+}
+
+// DepartmentEventPayload is the payload of department events: the
+// department after the change (before it, for DepartmentDeleted).
+type DepartmentEventPayload struct {
+	Department *Department `json:"department"`
+}
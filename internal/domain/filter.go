@@ -0,0 +1,69 @@
+package domain
+
+import "strconv"
+
+type EmployeeFilter struct {
+	NamePrefix    string
+	SurnamePrefix string
+	Phone         string
+	PassportType  string
+	DepartmentID  *int
+	// NoDepartment selects employees that are not assigned to any department.
+	NoDepartment bool
+	// IncludeDeleted adds soft-deleted employees to the result.
+	IncludeDeleted bool
+	// AsOf makes department listings select the employees assigned to the
+	// department on that date instead of those in it now.
+	AsOf *Date
+	// IncludeSubdepartments makes department listings also select the
+	// employees of every department below the department.
+	IncludeSubdepartments bool
+}
+
+// EmployeeSortFields lists the JSON field names employee listings can be
+// sorted by.
+var EmployeeSortFields = []string{
+	"id",
+	"name",
+	"surname",
+	"phone",
+	"companyId",
+	"departmentId",
+	"passportType",
+	"passportNumber",
+}
+
+func IsEmployeeSortField(field string) bool {
+	for _, f := range EmployeeSortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// EmployeeSortKey returns the value of the sort field for emp in the textual
+// form stored in pagination cursors. Missing department IDs sort as 0.
+func EmployeeSortKey(emp *Employee, field string) string {
+	switch field {
+	case "name":
+		return emp.Name
+	case "surname":
+		return emp.Surname
+	case "phone":
+		return emp.Phone
+	case "companyId":
+		return strconv.Itoa(emp.CompanyID)
+	case "departmentId":
+		if emp.DepartmentID == nil {
+			return "0"
+		}
+		return strconv.Itoa(*emp.DepartmentID)
+	case "passportType":
+		return emp.PassportType
+	case "passportNumber":
+		return emp.PassportNumber
+	default:
+		return strconv.Itoa(emp.ID)
+	}
+}
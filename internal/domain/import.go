@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+type ImportMode string
+
+const (
+	// ImportAllOrNothing creates every row or, if any row fails, none.
+	ImportAllOrNothing ImportMode = "all-or-nothing"
+	// ImportBestEffort creates every row it can and reports the rest.
+	ImportBestEffort ImportMode = "best-effort"
+)
+
+// ImportRow is one parsed data row of an import file. Err is set if the row
+// could not be parsed or validated; Employee is then nil.
+type ImportRow struct {
+	Line     int
+	Employee *Employee
+	Err      error
+}
+
+type ImportRowStatus string
+
+const (
+	ImportCreated ImportRowStatus = "created"
+	// ImportSkipped rows were left alone: the employee already exists, or
+	// an all-or-nothing import was rolled back.
+	ImportSkipped ImportRowStatus = "skipped"
+	ImportFailed  ImportRowStatus = "failed"
+)
+
+type ImportRowResult struct {
+	Line       int             `json:"line"`
+	Status     ImportRowStatus `json:"status"`
+	EmployeeID int             `json:"employeeId,omitempty"`
+	Field      string          `json:"field,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+}
+
+type ImportReport struct {
+	Mode    ImportMode `json:"mode"`
+	Created int        `json:"created"`
+	Skipped int        `json:"skipped"`
+	Failed  int        `json:"failed"`
+	// RolledBack is set when an all-or-nothing import created nothing
+	// because some row failed.
+	RolledBack bool              `json:"rolledBack,omitempty"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+type ImportJobStatus string
+
+const (
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobCompleted ImportJobStatus = "completed"
+	// ImportJobFailed means the job itself broke off, for example because
+	// the database went away; row failures are reported in Report instead.
+	ImportJobFailed ImportJobStatus = "failed"
+)
+
+// ImportJob is an import running in the background.
+type ImportJob struct {
+	ID         string          `json:"id"`
+	CompanyID  int             `json:"companyId"`
+	Mode       ImportMode      `json:"mode"`
+	Status     ImportJobStatus `json:"status"`
+	Rows       int             `json:"rows"`
+	Processed  int             `json:"processed"`
+	Report     *ImportReport   `json:"report,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
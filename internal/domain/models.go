@@ -1,10 +1,46 @@
 package domain
 
+import "time"
+
+type Company struct {
+	ID             int    `json:"id"`
+	LegalName      string `json:"legalName"`
+	TaxID          string `json:"taxId"`
+	DefaultCountry string `json:"defaultCountry"`
+	Active         bool   `json:"active"`
+}
+
+// CompanyPatch holds the company fields a PATCH request changes; nil means
+// the field is left as is.
+type CompanyPatch struct {
+	LegalName      *string `json:"legalName"`
+	TaxID          *string `json:"taxId"`
+	DefaultCountry *string `json:"defaultCountry"`
+	Active         *bool   `json:"active"`
+}
+
 type Department struct {
 	ID        int    `json:"id"`
 	CompanyID int    `json:"companyId"`
 	Name      string `json:"name"`
 	Phone     string `json:"phone"`
+	// ParentID is the department of the same company this one is part of,
+	// nil at the top level.
+	ParentID *int `json:"parentId"`
+}
+
+type DepartmentSummary struct {
+	Department
+	EmployeeCount int `json:"employeeCount"`
+}
+
+// DepartmentNode is a department in the tree of its company. EmployeeCount
+// counts the employees of the department itself, TotalEmployeeCount also
+// those of every department below it.
+type DepartmentNode struct {
+	DepartmentSummary
+	TotalEmployeeCount int               `json:"totalEmployeeCount"`
+	Children           []*DepartmentNode `json:"children"`
 }
 
 type Employee struct {
@@ -17,4 +53,19 @@ type Employee struct {
 	PassportType   string      `json:"passportType"`
 	PassportNumber string      `json:"passportNumber"`
 	Department     *Department `json:"department"`
+	// ManagerID is the employee this one reports to, in the same company.
+	ManagerID *int `json:"managerId"`
+	// Version is incremented on every write. Updates and deletes carrying a
+	// non-zero Version only succeed if it is still current.
+	Version int `json:"version"`
+	// DeletedAt is set on soft-deleted employees, which are only returned
+	// when asked for explicitly.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// Subordinate is an employee somewhere below a manager. Depth is 1 for direct
+// reports, 2 for their reports and so on.
+type Subordinate struct {
+	*Employee
+	Depth int `json:"depth"`
 }
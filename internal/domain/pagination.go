@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+type PageRequest struct {
+	Limit        int
+	Cursor       string
+	IncludeTotal bool
+	// SortBy is one of EmployeeSortFields; ties are always broken by ID.
+	SortBy   string
+	SortDesc bool
+}
+
+type EmployeePage struct {
+	Company    *Company    `json:"company,omitempty"`
+	Items      []*Employee `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	Total      *int        `json:"total,omitempty"`
+}
+
+// Cursor is the keyset position a page continues from. Clients only ever see
+// it in its encoded, opaque form. Sort and Desc record the ordering the cursor
+// was issued for, so it cannot be replayed against a different one.
+type Cursor struct {
+	Sort  string `json:"s,omitempty"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    int    `json:"id"`
+}
+
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	if s == "" {
+		return c, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, NewValidationError("cursor", "invalid cursor")
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID < 0 {
+		return c, NewValidationError("cursor", "invalid cursor")
+	}
+	return c, nil
+}
+
+// DecodeCursorFor decodes page.Cursor and checks that it was issued for the ordering
+// requested by page.
+func DecodeCursorFor(page PageRequest) (Cursor, error) {
+	c, err := DecodeCursor(page.Cursor)
+	if err != nil || page.Cursor == "" {
+		return c, err
+	}
+	if c.Sort != page.SortBy || c.Desc != page.SortDesc {
+		return c, NewValidationError("cursor", "cursor does not match the requested sort order")
+	}
+	return c, nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+// Patch transforms the JSON document of an entity, for example an RFC 7396
+// merge patch or an RFC 6902 JSON Patch.
+type Patch interface {
+	Apply(doc []byte) ([]byte, error)
+}
+
+// EmployeeDocument is the JSON document employee patches are applied to. Only
+// writable fields are part of it, and nullable fields are null when unset so
+// that a patch can clear them. Department is not part of the stored
+// document: a patch may add it to move the employee into a department that is
+// looked up or created by name.
+type EmployeeDocument struct {
+	Name           string      `json:"name"`
+	Surname        string      `json:"surname"`
+	Phone          string      `json:"phone"`
+	CompanyID      int         `json:"companyId"`
+	DepartmentID   *int        `json:"departmentId"`
+	PassportType   *string     `json:"passportType"`
+	PassportNumber string      `json:"passportNumber"`
+	ManagerID      *int        `json:"managerId"`
+	Department     *Department `json:"department,omitempty"`
+}
+
+func NewEmployeeDocument(emp *Employee) *EmployeeDocument {
+	doc := &EmployeeDocument{
+		Name:           emp.Name,
+		Surname:        emp.Surname,
+		Phone:          emp.Phone,
+		CompanyID:      emp.CompanyID,
+		DepartmentID:   emp.DepartmentID,
+		PassportNumber: emp.PassportNumber,
+		ManagerID:      emp.ManagerID,
+	}
+	if emp.PassportType != "" {
+		passportType := emp.PassportType
+		doc.PassportType = &passportType
+	}
+	return doc
+}
+
+// ApplyTo copies the document into emp, leaving ID and Version untouched.
+func (d *EmployeeDocument) ApplyTo(emp *Employee) {
+	emp.Name = d.Name
+	emp.Surname = d.Surname
+	emp.Phone = d.Phone
+	emp.CompanyID = d.CompanyID
+	emp.DepartmentID = d.DepartmentID
+	emp.PassportType = ""
+	if d.PassportType != nil {
+		emp.PassportType = *d.PassportType
+	}
+	emp.PassportNumber = d.PassportNumber
+	emp.ManagerID = d.ManagerID
+	emp.Department = d.Department
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchQuery is a normalised full-text query. Each variant is a
+// space-separated list of lowercase words; a row matches if it matches any
+// variant, which is how transliterated spellings are searched for.
+type SearchQuery struct {
+	Variants []string
+	Limit    int
+}
+
+type SearchResult struct {
+	Employee  *Employee `json:"employee"`
+	Rank      float64   `json:"rank"`
+	Highlight string    `json:"highlight"`
+}
+
+// MatchSearchWord scores how a lowercase document word matches a query word:
+// 2 if the query word prefixes it, 1 if they are within a single edit of each
+// other, to tolerate typos, and 0 otherwise.
+func MatchSearchWord(docWord, queryWord string) int {
+	if strings.HasPrefix(docWord, queryWord) {
+		return 2
+	}
+	if withinOneEdit(docWord, queryWord) {
+		return 1
+	}
+	return 0
+}
+
+// SearchWords splits text into lowercase words the way MatchSearchWord
+// expects them.
+func SearchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSearchSeparator)
+}
+
+// HighlightSearch HTML-escapes text and wraps the words matching any word of
+// query in <mark> tags, so the result can be rendered as HTML as is.
+func HighlightSearch(text string, query SearchQuery) string {
+	var queryWords []string
+	for _, variant := range query.Variants {
+		queryWords = append(queryWords, strings.Fields(variant)...)
+	}
+	matches := func(word string) bool {
+		lower := strings.ToLower(word)
+		for _, w := range queryWords {
+			if MatchSearchWord(lower, w) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	var b strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := html.EscapeString(text[start:end])
+		if matches(text[start:end]) {
+			b.WriteString("<mark>" + word + "</mark>")
+		} else {
+			b.WriteString(word)
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if isSearchSeparator(r) {
+			flush(i)
+			b.WriteString(html.EscapeString(string(r)))
+		} else if start < 0 {
+			start = i
+		}
+	}
+	flush(len(text))
+
+	return b.String()
+}
+
+func isSearchSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// withinOneEdit reports whether a and b differ by at most one insertion,
+// deletion or substitution.
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra)-len(rb) > 1 || len(rb) < 3 {
+		return false
+	}
+
+	i, j, edits := 0, 0, 0
+	for i < len(ra) && j < len(rb) {
+		if ra[i] == rb[j] {
+			i++
+			j++
+			continue
+		}
+		edits++
+		if edits > 1 {
+			return false
+		}
+		if len(ra) == len(rb) {
+			j++
+		}
+		i++
+	}
+	return edits+(len(ra)-i) <= 1
+}
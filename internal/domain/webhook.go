@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []EventType{
+	EventEmployeeCreated,
+	EventEmployeeUpdated,
+	EventEmployeeDeleted,
+	EventEmployeeRestored,
+	EventDepartmentCreated,
+	EventDepartmentUpdated,
+	EventDepartmentDeleted,
+}
+
+func (t EventType) Valid() bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscription asks for the events of a company to be POSTed to URL.
+// An empty EventTypes means every event type.
+type WebhookSubscription struct {
+	ID        int    `json:"id"`
+	CompanyID int    `json:"companyId"`
+	URL       string `json:"url"`
+	// Secret is the HMAC-SHA256 key deliveries are signed with. It is only
+	// returned when the subscription is created or the secret is changed.
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"eventTypes"`
+	Active     bool        `json:"active"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// Accepts reports whether events of type t are delivered to the subscription.
+func (s *WebhookSubscription) Accepts(t EventType) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, accepted := range s.EventTypes {
+		if accepted == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscriptionPatch holds the subscription fields a PATCH request
+// changes; nil means the field is left as is.
+type WebhookSubscriptionPatch struct {
+	URL        *string      `json:"url"`
+	Secret     *string      `json:"secret"`
+	EventTypes *[]EventType `json:"eventTypes"`
+	Active     *bool        `json:"active"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookPending deliveries wait for their first attempt or a retry.
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDead deliveries failed every attempt and are only sent again
+	// when replayed.
+	WebhookDead WebhookDeliveryStatus = "dead"
+)
+
+func (s WebhookDeliveryStatus) Valid() bool {
+	return s == WebhookPending || s == WebhookSucceeded || s == WebhookDead
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+// Payload is the request body: the event as JSON.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID int                   `json:"subscriptionId"`
+	EventID        int64                 `json:"eventId"`
+	EventType      EventType             `json:"eventType"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time            `json:"lastAttemptAt,omitempty"`
+	LastStatusCode int                   `json:"lastStatusCode,omitempty"`
+	LastError      string                `json:"lastError,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+}
+
+// Failed reports whether the delivery has failed at least once and not
+// succeeded since, which is when it can be replayed.
+func (d *WebhookDelivery) Failed() bool {
+	return d.Status == WebhookDead || (d.Status == WebhookPending && d.Attempts > 0)
+}
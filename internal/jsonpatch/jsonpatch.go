@@ -0,0 +1,197 @@
+// Package jsonpatch applies RFC 7396 JSON Merge Patch and RFC 6902 JSON Patch
+// documents to JSON values. Failures are reported as domain errors: a patch
+// that cannot be applied is a validation error, a failed "test" operation is
+// a conflict.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// MergePatch is an RFC 7396 merge patch: object members replace those of the
+// target, null members remove them.
+type MergePatch struct {
+	patch interface{}
+}
+
+func NewMergePatch(body []byte) (*MergePatch, error) {
+	patch, err := decode(body)
+	if err != nil {
+		return nil, err
+	}
+	return &MergePatch{patch: patch}, nil
+}
+
+func (p *MergePatch) Apply(doc []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p.patch))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+		} else {
+			targetObj[name] = merge(targetObj[name], value)
+		}
+	}
+	return targetObj
+}
+
+// Operation is a single RFC 6902 operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is an RFC 6902 patch. Operations are applied in order and the
+// whole patch fails if any of them does.
+type JSONPatch struct {
+	ops []Operation
+}
+
+func NewJSONPatch(body []byte) (*JSONPatch, error) {
+	var ops []Operation
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, fmt.Errorf("invalid JSON Patch: %w", err)
+	}
+
+	for i, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if len(op.Value) == 0 {
+				return nil, fmt.Errorf("invalid JSON Patch: operation %d (%s) requires a value", i, op.Op)
+			}
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				return nil, fmt.Errorf("invalid JSON Patch: operation %d: %w", i, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("invalid JSON Patch: operation %d has unknown op %q", i, op.Op)
+		}
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, fmt.Errorf("invalid JSON Patch: operation %d: %w", i, err)
+		}
+	}
+
+	return &JSONPatch{ops: ops}, nil
+}
+
+func (p *JSONPatch) Apply(doc []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range p.ops {
+		target, err = apply(target, op)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func apply(doc interface{}, op Operation) (interface{}, error) {
+	path, _ := parsePointer(op.Path)
+
+	var value interface{}
+	if len(op.Value) != 0 {
+		v, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, path, value, op.Path)
+	case "remove":
+		doc, _, err := remove(doc, path, op.Path)
+		return doc, err
+	case "replace":
+		if _, err := get(doc, path, op.Path); err != nil {
+			return nil, err
+		}
+		doc, _, err := remove(doc, path, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value, op.Path)
+	case "move":
+		from, _ := parsePointer(op.From)
+		doc, moved, err := remove(doc, from, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, moved, op.Path)
+	case "copy":
+		from, _ := parsePointer(op.From)
+		copied, err := get(doc, from, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, deepCopy(copied), op.Path)
+	case "test":
+		actual, err := get(doc, path, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, value) {
+			return nil, fmt.Errorf("%w: test failed at %q", domain.ErrConflict, op.Path)
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+func decode(data []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return v, nil
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c[k] = deepCopy(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = deepCopy(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,152 @@
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/jsonpatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "Success: replace and add members",
+			doc:      `{"a":"b","c":{"d":"e"}}`,
+			patch:    `{"a":"z","c":{"f":"g"}}`,
+			expected: `{"a":"z","c":{"d":"e","f":"g"}}`,
+		},
+		{
+			name:     "Success: null removes a member",
+			doc:      `{"a":"b","c":1}`,
+			patch:    `{"a":null}`,
+			expected: `{"c":1}`,
+		},
+		{
+			name:     "Success: arrays are replaced",
+			doc:      `{"a":[1,2]}`,
+			patch:    `{"a":[3]}`,
+			expected: `{"a":[3]}`,
+		},
+		{
+			name:     "Success: nested null removes a nested member",
+			doc:      `{"a":{"b":1,"c":2}}`,
+			patch:    `{"a":{"b":null}}`,
+			expected: `{"a":{"c":2}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonpatch.NewMergePatch([]byte(tt.patch))
+			require.NoError(t, err)
+
+			result, err := patch.Apply([]byte(tt.doc))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+
+	t.Run("Error: malformed patch", func(t *testing.T) {
+		_, err := jsonpatch.NewMergePatch([]byte(`{"a":`))
+		assert.Error(t, err)
+	})
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		patch       string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "Success: add, replace and remove members",
+			doc:      `{"a":1,"b":2}`,
+			patch:    `[{"op":"add","path":"/c","value":3},{"op":"replace","path":"/a","value":10},{"op":"remove","path":"/b"}]`,
+			expected: `{"a":10,"c":3}`,
+		},
+		{
+			name:     "Success: insert into and append to arrays",
+			doc:      `{"a":[1,3]}`,
+			patch:    `[{"op":"add","path":"/a/1","value":2},{"op":"add","path":"/a/-","value":4}]`,
+			expected: `{"a":[1,2,3,4]}`,
+		},
+		{
+			name:     "Success: move and copy",
+			doc:      `{"a":{"b":1},"c":[]}`,
+			patch:    `[{"op":"copy","from":"/a/b","path":"/c/0"},{"op":"move","from":"/a","path":"/d"}]`,
+			expected: `{"c":[1],"d":{"b":1}}`,
+		},
+		{
+			name:     "Success: escaped pointer tokens",
+			doc:      `{"a/b":1,"m~n":2}`,
+			patch:    `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/m~0n","value":3}]`,
+			expected: `{"m~n":3}`,
+		},
+		{
+			name:     "Success: null is a value",
+			doc:      `{"a":1}`,
+			patch:    `[{"op":"replace","path":"/a","value":null}]`,
+			expected: `{"a":null}`,
+		},
+		{
+			name:     "Success: passing test",
+			doc:      `{"name":"John","version":2}`,
+			patch:    `[{"op":"test","path":"/name","value":"John"},{"op":"replace","path":"/name","value":"Jack"}]`,
+			expected: `{"name":"Jack","version":2}`,
+		},
+		{
+			name:        "Error: failing test is a conflict",
+			doc:         `{"name":"John"}`,
+			patch:       `[{"op":"test","path":"/name","value":"Jane"},{"op":"replace","path":"/name","value":"Jack"}]`,
+			expectedErr: domain.ErrConflict,
+		},
+		{
+			name:        "Error: replacing a missing member",
+			doc:         `{"a":1}`,
+			patch:       `[{"op":"replace","path":"/b","value":2}]`,
+			expectedErr: domain.ErrValidation,
+		},
+		{
+			name:        "Error: array index out of range",
+			doc:         `{"a":[1]}`,
+			patch:       `[{"op":"remove","path":"/a/5"}]`,
+			expectedErr: domain.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonpatch.NewJSONPatch([]byte(tt.patch))
+			require.NoError(t, err)
+
+			result, err := patch.Apply([]byte(tt.doc))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+
+	t.Run("Error: malformed operations are rejected upfront", func(t *testing.T) {
+		for _, body := range []string{
+			`{"op":"add"}`,
+			`[{"op":"frobnicate","path":"/a"}]`,
+			`[{"op":"add","path":"/a"}]`,
+			`[{"op":"remove","path":"a"}]`,
+		} {
+			_, err := jsonpatch.NewJSONPatch([]byte(body))
+			assert.Error(t, err, body)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package jsonpatch
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference
+// tokens. The empty pointer refers to the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("JSON pointer must start with /: " + strconv.Quote(pointer))
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+func pathError(pointer, message string) error {
+	return domain.NewValidationError(pointer, message)
+}
+
+func get(doc interface{}, path []string, pointer string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, pathError(pointer, "path "+pointer+" does not exist")
+			}
+			doc = v
+		case []interface{}:
+			i, err := index(token, len(node))
+			if err != nil {
+				return nil, pathError(pointer, err.Error())
+			}
+			doc = node[i]
+		default:
+			return nil, pathError(pointer, "path "+pointer+" does not exist")
+		}
+	}
+	return doc, nil
+}
+
+// add sets the value at path, inserting into arrays, and returns the new root.
+func add(doc interface{}, path []string, value interface{}, pointer string) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1], pointer)
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		i := len(node)
+		if last != "-" {
+			if i, err = index(last, len(node)+1); err != nil {
+				return nil, pathError(pointer, err.Error())
+			}
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return set(doc, path[:len(path)-1], node, pointer)
+	default:
+		return nil, pathError(pointer, "path "+pointer+" does not exist")
+	}
+}
+
+// remove deletes the value at path and returns the new root and the removed
+// value.
+func remove(doc interface{}, path []string, pointer string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1], pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		v, ok := node[last]
+		if !ok {
+			return nil, nil, pathError(pointer, "path "+pointer+" does not exist")
+		}
+		delete(node, last)
+		return doc, v, nil
+	case []interface{}:
+		i, err := index(last, len(node))
+		if err != nil {
+			return nil, nil, pathError(pointer, err.Error())
+		}
+		v := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err = set(doc, path[:len(path)-1], node, pointer)
+		return doc, v, err
+	default:
+		return nil, nil, pathError(pointer, "path "+pointer+" does not exist")
+	}
+}
+
+// set replaces the value at an existing path. Slices change identity when
+// they grow or shrink, so the parent has to be updated.
+func set(doc interface{}, path []string, value interface{}, pointer string) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1], pointer)
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		i, err := index(last, len(node))
+		if err != nil {
+			return nil, pathError(pointer, err.Error())
+		}
+		node[i] = value
+	}
+	return doc, nil
+}
+
+func index(token string, length int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= length || (len(token) > 1 && token[0] == '0') {
+		return 0, errors.New("invalid array index " + strconv.Quote(token))
+	}
+	return i, nil
+}
@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := load(files)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "versions must be contiguous")
+		assert.NotEmpty(t, m.Name)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{
+			name: "Error: missing down file",
+			files: fstest.MapFS{
+				"sql/0001_init.up.sql": {Data: []byte("SELECT 1")},
+			},
+		},
+		{
+			name: "Error: missing direction",
+			files: fstest.MapFS{
+				"sql/0001_init.sql": {Data: []byte("SELECT 1")},
+			},
+		},
+		{
+			name: "Error: invalid version",
+			files: fstest.MapFS{
+				"sql/first_init.up.sql":   {Data: []byte("SELECT 1")},
+				"sql/first_init.down.sql": {Data: []byte("SELECT 1")},
+			},
+		},
+		{
+			name: "Error: conflicting names for one version",
+			files: fstest.MapFS{
+				"sql/0001_init.up.sql":  {Data: []byte("SELECT 1")},
+				"sql/0001_other.up.sql": {Data: []byte("SELECT 1")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(tt.files)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad_Order(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"sql/0010_later.up.sql":    {Data: []byte("up 10")},
+		"sql/0010_later.down.sql":  {Data: []byte("down 10")},
+		"sql/0002_second.up.sql":   {Data: []byte("up 2")},
+		"sql/0002_second.down.sql": {Data: []byte("down 2")},
+	})
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, Migration{Version: 2, Name: "second", Up: "up 2", Down: "down 2"}, migrations[0])
+	assert.Equal(t, 10, migrations[1].Version)
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS employees;
+DROP TABLE IF EXISTS departments;
@@ -1,4 +1,3 @@
--- init.sql
 CREATE TABLE IF NOT EXISTS departments (
     id SERIAL PRIMARY KEY,
     company_id INTEGER NOT NULL,
@@ -16,4 +15,8 @@ CREATE TABLE IF NOT EXISTS employees (
     department_id INTEGER REFERENCES departments(id) ON DELETE SET NULL,
     passport_type VARCHAR(20),
     passport_number VARCHAR(50) UNIQUE NOT NULL
-);
\ No newline at end of file
+);
+
+CREATE INDEX IF NOT EXISTS employees_company_id_id_idx ON employees (company_id, id);
+CREATE INDEX IF NOT EXISTS employees_company_id_surname_idx ON employees (company_id, surname, id);
+CREATE INDEX IF NOT EXISTS employees_department_id_idx ON employees (department_id);
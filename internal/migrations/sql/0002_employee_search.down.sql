@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS employees_search_text_trgm_idx;
+DROP INDEX IF EXISTS employees_search_vector_idx;
+
+DROP TRIGGER IF EXISTS departments_search_refresh ON departments;
+DROP FUNCTION IF EXISTS departments_search_refresh();
+DROP TRIGGER IF EXISTS employees_search_refresh ON employees;
+DROP FUNCTION IF EXISTS employees_search_refresh();
+
+ALTER TABLE employees DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE employees DROP COLUMN IF EXISTS search_text;
@@ -0,0 +1,45 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- search_vector and search_text also cover the department name, so they are
+-- maintained by triggers instead of generated columns.
+CREATE OR REPLACE FUNCTION employees_search_refresh() RETURNS trigger AS $$
+DECLARE
+    dept_name TEXT;
+BEGIN
+    SELECT name INTO dept_name FROM departments WHERE id = NEW.department_id;
+
+    NEW.search_text := lower(concat_ws(' ', NEW.surname, NEW.name, NEW.phone, dept_name));
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.surname, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(NEW.phone, '')), 'C') ||
+        setweight(to_tsvector('simple', coalesce(dept_name, '')), 'D');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS employees_search_refresh ON employees;
+CREATE TRIGGER employees_search_refresh
+    BEFORE INSERT OR UPDATE OF name, surname, phone, department_id ON employees
+    FOR EACH ROW EXECUTE FUNCTION employees_search_refresh();
+
+CREATE OR REPLACE FUNCTION departments_search_refresh() RETURNS trigger AS $$
+BEGIN
+    UPDATE employees SET department_id = department_id WHERE department_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS departments_search_refresh ON departments;
+CREATE TRIGGER departments_search_refresh
+    AFTER UPDATE OF name ON departments
+    FOR EACH ROW EXECUTE FUNCTION departments_search_refresh();
+
+-- Fill the search columns of rows created before the trigger existed.
+UPDATE employees SET department_id = department_id WHERE search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS employees_search_vector_idx ON employees USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS employees_search_text_trgm_idx ON employees USING GIN (search_text gin_trgm_ops);
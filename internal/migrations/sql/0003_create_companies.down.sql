@@ -0,0 +1,4 @@
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_company_id_fkey;
+ALTER TABLE departments DROP CONSTRAINT IF EXISTS departments_company_id_fkey;
+
+DROP TABLE IF EXISTS companies;
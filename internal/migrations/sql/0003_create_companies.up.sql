@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS companies (
+    id SERIAL PRIMARY KEY,
+    legal_name VARCHAR(255) NOT NULL,
+    tax_id VARCHAR(20) UNIQUE NOT NULL,
+    default_country CHAR(2) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Company IDs used before this table existed become placeholder companies
+-- that have to be filled in through PATCH /companies/{id}.
+INSERT INTO companies (id, legal_name, tax_id, default_country)
+SELECT company_id, 'Company ' || company_id, 'UNKNOWN-' || company_id, 'RU'
+FROM (
+    SELECT company_id FROM employees
+    UNION
+    SELECT company_id FROM departments
+) AS used
+ON CONFLICT (id) DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('companies', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM companies;
+
+ALTER TABLE departments DROP CONSTRAINT IF EXISTS departments_company_id_fkey;
+ALTER TABLE departments ADD CONSTRAINT departments_company_id_fkey
+    FOREIGN KEY (company_id) REFERENCES companies(id);
+
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_company_id_fkey;
+ALTER TABLE employees ADD CONSTRAINT employees_company_id_fkey
+    FOREIGN KEY (company_id) REFERENCES companies(id);
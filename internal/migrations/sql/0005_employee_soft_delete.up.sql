@@ -0,0 +1,11 @@
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Deleted employees keep their phone and passport number, so uniqueness only
+-- applies to live rows and a rehired person can reuse them.
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_phone_key;
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_passport_number_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS employees_phone_live_key
+    ON employees (phone) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS employees_passport_number_live_key
+    ON employees (passport_number) WHERE deleted_at IS NULL;
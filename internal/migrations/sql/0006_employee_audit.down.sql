@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS employee_audit;
+DROP FUNCTION IF EXISTS employee_audit_append_only();
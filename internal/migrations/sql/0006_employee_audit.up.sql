@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS employee_audit (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    seq INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    -- JSON rather than JSONB: the text is kept exactly as hashed.
+    changes JSON NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    UNIQUE (employee_id, seq)
+);
+
+CREATE OR REPLACE FUNCTION employee_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'employee_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS employee_audit_append_only ON employee_audit;
+CREATE TRIGGER employee_audit_append_only
+    BEFORE UPDATE OR DELETE ON employee_audit
+    FOR EACH ROW EXECUTE FUNCTION employee_audit_append_only();
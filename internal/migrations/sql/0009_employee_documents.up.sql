@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS employee_documents (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    number VARCHAR(50) NOT NULL,
+    issuing_country CHAR(2) NOT NULL,
+    issued_on DATE,
+    expires_on DATE,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT employee_documents_type_number_key UNIQUE (type, number),
+    CONSTRAINT employee_documents_dates_check CHECK (issued_on IS NULL OR expires_on IS NULL OR issued_on <= expires_on)
+);
+
+CREATE INDEX IF NOT EXISTS employee_documents_employee_id_idx ON employee_documents (employee_id);
+CREATE INDEX IF NOT EXISTS employee_documents_expires_on_idx ON employee_documents (expires_on) WHERE expires_on IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS employee_documents_primary_key
+    ON employee_documents (employee_id) WHERE is_primary;
+
+-- The passport already stored on each employee becomes their primary
+-- document, issued by the company's default country.
+INSERT INTO employee_documents (employee_id, type, number, issuing_country, is_primary)
+SELECT e.id, COALESCE(NULLIF(e.passport_type, ''), 'passport'), e.passport_number, c.default_country, TRUE
+FROM employees e
+JOIN companies c ON c.id = e.company_id
+ORDER BY e.deleted_at IS NOT NULL, e.id
+ON CONFLICT (type, number) DO NOTHING;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS employee_assignments (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    department_id INTEGER NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    -- The day the employee left the department; NULL while they are in it.
+    end_date DATE,
+    CONSTRAINT employee_assignments_employee_id_start_date_key UNIQUE (employee_id, start_date),
+    CONSTRAINT employee_assignments_dates_check CHECK (end_date IS NULL OR end_date > start_date)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS employee_assignments_open_key
+    ON employee_assignments (employee_id) WHERE end_date IS NULL;
+CREATE INDEX IF NOT EXISTS employee_assignments_department_id_idx
+    ON employee_assignments (department_id, start_date);
+
+-- Earlier moves were not recorded, so current assignments start on the day
+-- of the migration.
+INSERT INTO employee_assignments (employee_id, department_id, start_date)
+SELECT id, department_id, CURRENT_DATE
+FROM employees
+WHERE department_id IS NOT NULL
+ON CONFLICT DO NOTHING;
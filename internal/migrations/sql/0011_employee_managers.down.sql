@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS employees_manager_id_idx;
+ALTER TABLE employees DROP COLUMN IF EXISTS manager_id;
@@ -0,0 +1,9 @@
+-- Reporting lines. Cycles are prevented by the service; the check only
+-- rules out the trivial one.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS manager_id INTEGER
+    REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_manager_id_check;
+ALTER TABLE employees ADD CONSTRAINT employees_manager_id_check CHECK (manager_id <> id);
+
+CREATE INDEX IF NOT EXISTS employees_manager_id_idx
+    ON employees (manager_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS departments_parent_id_idx;
+ALTER TABLE departments DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,9 @@
+-- Departments nest within their company. Cycles are prevented by the
+-- service; deleting a department moves its children to the top level, the
+-- way its employees are left without a department.
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS parent_id INTEGER
+    REFERENCES departments(id) ON DELETE SET NULL;
+ALTER TABLE departments DROP CONSTRAINT IF EXISTS departments_parent_id_check;
+ALTER TABLE departments ADD CONSTRAINT departments_parent_id_check CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS departments_parent_id_idx ON departments (parent_id);
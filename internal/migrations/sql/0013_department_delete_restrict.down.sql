@@ -0,0 +1,7 @@
+ALTER TABLE departments DROP CONSTRAINT IF EXISTS departments_parent_id_fkey;
+ALTER TABLE departments ADD CONSTRAINT departments_parent_id_fkey
+    FOREIGN KEY (parent_id) REFERENCES departments(id) ON DELETE SET NULL;
+
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_department_id_fkey;
+ALTER TABLE employees ADD CONSTRAINT employees_department_id_fkey
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE SET NULL;
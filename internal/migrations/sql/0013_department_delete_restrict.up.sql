@@ -0,0 +1,10 @@
+-- A department can only be deleted once nothing refers to it: detaching its
+-- employees and children in the database would change them without a new
+-- version, an audit entry or an event.
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_department_id_fkey;
+ALTER TABLE employees ADD CONSTRAINT employees_department_id_fkey
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE RESTRICT;
+
+ALTER TABLE departments DROP CONSTRAINT IF EXISTS departments_parent_id_fkey;
+ALTER TABLE departments ADD CONSTRAINT departments_parent_id_fkey
+    FOREIGN KEY (parent_id) REFERENCES departments(id) ON DELETE RESTRICT;
@@ -0,0 +1,3 @@
+-- Fails if live employees of different passport types share a number.
+CREATE UNIQUE INDEX IF NOT EXISTS employees_passport_number_live_key
+    ON employees (passport_number) WHERE deleted_at IS NULL;
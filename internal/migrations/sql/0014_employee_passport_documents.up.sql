@@ -0,0 +1,15 @@
+-- The passport of an employee is their primary document, which the service
+-- writes together with the employee. Documents are unique by type and
+-- number, so the number alone no longer has to be.
+DROP INDEX IF EXISTS employees_passport_number_live_key;
+
+-- Employees created since 0009 get their primary document too.
+INSERT INTO employee_documents (employee_id, type, number, issuing_country, is_primary)
+SELECT e.id, COALESCE(NULLIF(e.passport_type, ''), 'passport'), e.passport_number, c.default_country, TRUE
+FROM employees e
+JOIN companies c ON c.id = e.company_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM employee_documents d WHERE d.employee_id = e.id AND d.is_primary
+)
+ORDER BY e.deleted_at IS NOT NULL, e.id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE employee_assignments DROP CONSTRAINT IF EXISTS employee_assignments_department_id_fkey;
+ALTER TABLE employee_assignments ADD CONSTRAINT employee_assignments_department_id_fkey
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+-- Assignments are the history of who worked where. A department that has
+-- any, past or scheduled, cannot be deleted, so the history is never lost.
+ALTER TABLE employee_assignments DROP CONSTRAINT IF EXISTS employee_assignments_department_id_fkey;
+ALTER TABLE employee_assignments ADD CONSTRAINT employee_assignments_department_id_fkey
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE RESTRICT;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS employee_documents_retire ON employees;
+DROP FUNCTION IF EXISTS employee_documents_retire();
+DROP INDEX IF EXISTS employee_documents_type_number_live_key;
+-- Fails if a retired document shares its type and number with another one.
+ALTER TABLE employee_documents ADD CONSTRAINT employee_documents_type_number_key UNIQUE (type, number);
+ALTER TABLE employee_documents DROP COLUMN IF EXISTS retired;
@@ -0,0 +1,25 @@
+-- Documents of soft-deleted employees are retired, so a rehired person can
+-- use their passport again. Restoring the employee brings the documents
+-- back, and fails if a live employee has taken one in the meantime.
+ALTER TABLE employee_documents ADD COLUMN IF NOT EXISTS retired BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE employee_documents d SET retired = TRUE
+FROM employees e
+WHERE e.id = d.employee_id AND e.deleted_at IS NOT NULL;
+
+ALTER TABLE employee_documents DROP CONSTRAINT IF EXISTS employee_documents_type_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS employee_documents_type_number_live_key
+    ON employee_documents (type, number) WHERE NOT retired;
+
+CREATE OR REPLACE FUNCTION employee_documents_retire() RETURNS trigger AS $$
+BEGIN
+    UPDATE employee_documents SET retired = (NEW.deleted_at IS NOT NULL) WHERE employee_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS employee_documents_retire ON employees;
+CREATE TRIGGER employee_documents_retire
+    AFTER UPDATE OF deleted_at ON employees
+    FOR EACH ROW WHEN (OLD.deleted_at IS DISTINCT FROM NEW.deleted_at)
+    EXECUTE FUNCTION employee_documents_retire();
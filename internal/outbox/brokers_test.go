@@ -0,0 +1,105 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/outbox"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The broker tests run against local brokers, see "make test-brokers". They
+// are skipped unless TEST_KAFKA_BROKERS or TEST_NATS_URL is set.
+
+func testEvent() *domain.Event {
+	return &domain.Event{
+		ID:            time.Now().UnixNano(),
+		Type:          domain.EventEmployeeCreated,
+		AggregateType: domain.AggregateEmployee,
+		AggregateID:   7,
+		CompanyID:     1,
+		Payload:       json.RawMessage(`{"employee":{"id":7}}`),
+		OccurredAt:    time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	brokers := os.Getenv("TEST_KAFKA_BROKERS")
+	if brokers == "" {
+		t.Skip("TEST_KAFKA_BROKERS is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	topic := fmt.Sprintf("employee-events-test-%d", time.Now().UnixNano())
+	publisher := outbox.NewKafkaPublisher(strings.Split(brokers, ","), topic)
+	defer publisher.Close()
+
+	event := testEvent()
+	// The first write may fail while the topic is being created.
+	require.Eventually(t, func() bool { return publisher.Publish(ctx, event) == nil }, 20*time.Second, 500*time.Millisecond)
+
+	reader := kafka.NewReader(kafka.ReaderConfig{Brokers: strings.Split(brokers, ","), Topic: topic})
+	defer reader.Close()
+
+	msg, err := reader.ReadMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "employee:7", string(msg.Key))
+
+	var got domain.Event
+	require.NoError(t, json.Unmarshal(msg.Value, &got))
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, domain.EventEmployeeCreated, got.Type)
+}
+
+func TestNATSPublisher(t *testing.T) {
+	url := os.Getenv("TEST_NATS_URL")
+	if url == "" {
+		t.Skip("TEST_NATS_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	suffix := time.Now().UnixNano()
+	stream, subject := fmt.Sprintf("EVENTS_TEST_%d", suffix), fmt.Sprintf("test%d.events", suffix)
+	publisher, err := outbox.NewNATSPublisher(ctx, url, stream, subject)
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	event := testEvent()
+	require.NoError(t, publisher.Publish(ctx, event))
+	// Publishing again is deduplicated by event ID.
+	require.NoError(t, publisher.Publish(ctx, event))
+
+	nc, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	s, err := js.Stream(ctx, stream)
+	require.NoError(t, err)
+	info, err := s.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), info.State.Msgs)
+
+	msg, err := s.GetLastMsgForSubject(ctx, subject+".EmployeeCreated")
+	require.NoError(t, err)
+	var got domain.Event
+	require.NoError(t, json.Unmarshal(msg.Data, &got))
+	assert.Equal(t, event.ID, got.ID)
+
+	require.NoError(t, js.DeleteStream(ctx, stream))
+}
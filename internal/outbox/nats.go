@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSPublisher publishes events to JetStream under subject.<event type>.
+// The event ID is the message ID, so JetStream drops events published again
+// within its deduplication window.
+type NATSPublisher struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	subject string
+}
+
+// NewNATSPublisher connects to url and creates or updates stream to capture
+// the subjects events are published to.
+func NewNATSPublisher(ctx context.Context, url, stream, subject string) (*NATSPublisher, error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     stream,
+		Subjects: []string{subject + ".>"},
+	})
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to create stream %s: %w", stream, err)
+	}
+
+	return &NATSPublisher{conn: nc, js: js, subject: subject}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.subject + "." + string(event.Type))
+	msg.Data = data
+	msg.Header.Set("Event-Key", event.Key())
+
+	_, err = p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(strconv.FormatInt(event.ID, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to publish event %d to nats: %w", event.ID, err)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,74 @@
+// Package outbox relays events from the transactional outbox to a message
+// broker.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// Publisher delivers events to a broker. Publish returns once the broker has
+// accepted the event; an event may be published more than once, so
+// consumers deduplicate by event ID.
+type Publisher interface {
+	Publish(ctx context.Context, event *domain.Event) error
+	Close() error
+}
+
+// LogPublisher writes events to a logger. It is meant for local development.
+type LogPublisher struct {
+	logger *log.Logger
+}
+
+func NewLogPublisher(logger *log.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.logger.Printf("Event %s", data)
+	return nil
+}
+
+func (p *LogPublisher) Close() error {
+	return nil
+}
+
+// PublisherFunc adapts a function to a Publisher with nothing to close.
+type PublisherFunc func(ctx context.Context, event *domain.Event) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event *domain.Event) error {
+	return f(ctx, event)
+}
+
+func (f PublisherFunc) Close() error {
+	return nil
+}
+
+// Fanout publishes every event to all of publishers in turn. If one fails,
+// the event is retried on all of them, so the others may see it twice.
+type Fanout []Publisher
+
+func (f Fanout) Publish(ctx context.Context, event *domain.Event) error {
+	for _, p := range f {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f Fanout) Close() error {
+	var errs []error
+	for _, p := range f {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// maxBackoff caps the wait between polls while publishing keeps failing.
+const maxBackoff = time.Minute
+
+// Store is the outbox as seen by the relay.
+type Store interface {
+	// ProcessPending passes up to limit unpublished events to fn, oldest
+	// first, and marks the events whose IDs fn returns as published.
+	ProcessPending(ctx context.Context, limit int, fn func([]*domain.Event) []int64) error
+}
+
+// Relay publishes outbox events. Delivery is at least once: an event is
+// marked published only after the publisher accepted it. Events of one
+// aggregate are published in the order they were written; when one fails,
+// the later events of its aggregate wait for the next round while other
+// aggregates carry on.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	logger    *log.Logger
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRelay(store Store, publisher Publisher, logger *log.Logger, interval time.Duration, batchSize int) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		logger:    logger,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run relays events until ctx is cancelled. It polls every interval, at once
+// again after a full batch, and backs off exponentially while rounds fail.
+func (r *Relay) Run(ctx context.Context) {
+	wait := r.interval
+	for {
+		n, failed, err := r.RelayOnce(ctx)
+		switch {
+		case err != nil || failed > 0:
+			if err != nil && ctx.Err() == nil {
+				r.logger.Printf("Outbox relay failed: %v", err)
+			}
+			wait = min(wait*2, maxBackoff)
+		case n == r.batchSize:
+			wait = 0
+		default:
+			wait = r.interval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if wait == 0 {
+			wait = r.interval
+		}
+	}
+}
+
+// RelayOnce publishes one batch of pending events and returns how many were
+// published and how many failed.
+func (r *Relay) RelayOnce(ctx context.Context) (published, failed int, err error) {
+	err = r.store.ProcessPending(ctx, r.batchSize, func(events []*domain.Event) []int64 {
+		ids := make([]int64, 0, len(events))
+		blocked := make(map[string]bool)
+
+		for _, event := range events {
+			key := event.Key()
+			if blocked[key] || ctx.Err() != nil {
+				failed++
+				continue
+			}
+			if err := r.publisher.Publish(ctx, event); err != nil {
+				r.logger.Printf("Publishing event %d (%s %s) failed: %v", event.ID, event.Type, key, err)
+				blocked[key] = true
+				failed++
+				continue
+			}
+			ids = append(ids, event.ID)
+		}
+
+		published = len(ids)
+		return ids
+	})
+	return published, failed, err
+}
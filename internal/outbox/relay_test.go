@@ -0,0 +1,130 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/outbox"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records published events and fails those listed in
+// fail once each.
+type recordingPublisher struct {
+	events []*domain.Event
+	fail   map[int64]bool
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	if p.fail[event.ID] {
+		delete(p.fail, event.ID)
+		return errors.New("broker unavailable")
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+func (p *recordingPublisher) ids() []int64 {
+	ids := make([]int64, len(p.events))
+	for i, e := range p.events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func addEvents(t *testing.T, repo *memory.OutboxRepo, aggregateIDs ...int) {
+	t.Helper()
+	for _, id := range aggregateIDs {
+		err := repo.Add(context.Background(), &domain.Event{
+			Type:          domain.EventEmployeeUpdated,
+			AggregateType: domain.AggregateEmployee,
+			AggregateID:   id,
+			CompanyID:     1,
+			Payload:       []byte(`{}`),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+
+	t.Run("Success: events are published in order and only once", func(t *testing.T) {
+		repo := memory.NewOutboxRepo(memory.NewStore())
+		addEvents(t, repo, 1, 2, 1)
+		publisher := &recordingPublisher{}
+		relay := outbox.NewRelay(repo, publisher, logger, 0, 10)
+
+		published, failed, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, published)
+		assert.Zero(t, failed)
+
+		published, _, err = relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, published)
+		assert.Equal(t, []int64{1, 2, 3}, publisher.ids())
+	})
+
+	t.Run("Success: batches are limited", func(t *testing.T) {
+		repo := memory.NewOutboxRepo(memory.NewStore())
+		addEvents(t, repo, 1, 2, 3)
+		publisher := &recordingPublisher{}
+		relay := outbox.NewRelay(repo, publisher, logger, 0, 2)
+
+		published, _, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+
+		published, _, err = relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []int64{1, 2, 3}, publisher.ids())
+	})
+
+	t.Run("Error: failed event holds back its aggregate only", func(t *testing.T) {
+		repo := memory.NewOutboxRepo(memory.NewStore())
+		// Events 1 and 3 belong to employee 1, event 2 to employee 2.
+		addEvents(t, repo, 1, 2, 1)
+		publisher := &recordingPublisher{fail: map[int64]bool{1: true}}
+		relay := outbox.NewRelay(repo, publisher, logger, 0, 10)
+
+		published, failed, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, 2, failed)
+		assert.Equal(t, []int64{2}, publisher.ids())
+
+		published, failed, err = relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Zero(t, failed)
+		assert.Equal(t, []int64{2, 1, 3}, publisher.ids())
+	})
+
+	t.Run("Error: rolled back events are never published", func(t *testing.T) {
+		store := memory.NewStore()
+		repo := memory.NewOutboxRepo(store)
+		err := store.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Add(ctx, &domain.Event{Type: domain.EventEmployeeCreated, AggregateType: domain.AggregateEmployee, AggregateID: 1}))
+			return errors.New("boom")
+		})
+		require.Error(t, err)
+
+		publisher := &recordingPublisher{}
+		published, _, err := outbox.NewRelay(repo, publisher, logger, 0, 10).RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, published)
+	})
+}
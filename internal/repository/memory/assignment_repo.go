@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type AssignmentRepo struct {
+	store *Store
+}
+
+func NewAssignmentRepo(store *Store) *AssignmentRepo {
+	return &AssignmentRepo{store: store}
+}
+
+func (r *AssignmentRepo) Create(ctx context.Context, a *domain.Assignment) (int, error) {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.employees[a.EmployeeID]; !ok {
+		return 0, fmt.Errorf("%w: assignment employeeId refers to a missing employee", domain.ErrConflict)
+	}
+	if _, ok := s.departments[a.DepartmentID]; !ok {
+		return 0, fmt.Errorf("%w: assignment departmentId refers to a missing department", domain.ErrConflict)
+	}
+
+	c := copyAssignment(a)
+	c.ID = s.lastAssignmentID + 1
+	if err := s.putAssignment(c); err != nil {
+		return 0, err
+	}
+	s.lastAssignmentID = c.ID
+
+	return c.ID, nil
+}
+
+func (r *AssignmentRepo) Update(ctx context.Context, a *domain.Assignment) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	existing := s.findAssignment(a.ID)
+	if existing == nil {
+		return domain.NewNotFoundError("assignment")
+	}
+	if _, ok := s.departments[a.DepartmentID]; !ok {
+		return fmt.Errorf("%w: assignment departmentId refers to a missing department", domain.ErrConflict)
+	}
+
+	c := copyAssignment(a)
+	c.EmployeeID = existing.EmployeeID
+	return s.putAssignment(c)
+}
+
+func (r *AssignmentRepo) Delete(ctx context.Context, id int) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	existing := s.findAssignment(id)
+	if existing == nil {
+		return domain.NewNotFoundError("assignment")
+	}
+	s.removeAssignments(existing.EmployeeID, func(a *domain.Assignment) bool { return a.ID == id })
+	return nil
+}
+
+func (r *AssignmentRepo) Last(ctx context.Context, employeeID int) (*domain.Assignment, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	assignments := s.assignments[employeeID]
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	return copyAssignment(assignments[len(assignments)-1]), nil
+}
+
+func (r *AssignmentRepo) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.Assignment, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	assignments := []*domain.Assignment{}
+	for _, a := range s.assignments[employeeID] {
+		assignments = append(assignments, copyAssignment(a))
+	}
+	return assignments, nil
+}
+
+func (r *AssignmentRepo) ListDue(ctx context.Context, day domain.Date, limit int) ([]*domain.Assignment, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	due := []*domain.Assignment{}
+	for employeeID, assignments := range s.assignments {
+		last := assignments[len(assignments)-1]
+		emp := s.employees[employeeID]
+		if last.EndDate != nil || last.StartDate.After(day.Time) || emp.DeletedAt != nil {
+			continue
+		}
+		if emp.DepartmentID == nil || *emp.DepartmentID != last.DepartmentID {
+			due = append(due, copyAssignment(last))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].StartDate.Equal(due[j].StartDate.Time) {
+			return due[i].StartDate.Before(due[j].StartDate.Time)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// assignedOn reports whether the employee was assigned to one of the
+// departments on day.
+func (s *Store) assignedOn(employeeID int, departments map[int]bool, day domain.Date) bool {
+	for _, a := range s.assignments[employeeID] {
+		if departments[a.DepartmentID] && a.Covers(day) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) findAssignment(id int) *domain.Assignment {
+	for _, assignments := range s.assignments {
+		for _, a := range assignments {
+			if a.ID == id {
+				return a
+			}
+		}
+	}
+	return nil
+}
+
+// putAssignment stores a, replacing the assignment with its ID, after
+// checking the same constraints as the Postgres schema: one assignment per
+// employee and start date, one open assignment per employee and an end date
+// after the start date.
+func (s *Store) putAssignment(a *domain.Assignment) error {
+	if a.EndDate != nil && !a.EndDate.After(a.StartDate.Time) {
+		return fmt.Errorf("%w: assignment must end after it starts", domain.ErrConflict)
+	}
+
+	assignments := []*domain.Assignment{a}
+	for _, other := range s.assignments[a.EmployeeID] {
+		if other.ID == a.ID {
+			continue
+		}
+		if other.StartDate.Equal(a.StartDate.Time) {
+			return fmt.Errorf("%w: employee already has an assignment starting on %s", domain.ErrConflict, a.StartDate)
+		}
+		if other.EndDate == nil && a.EndDate == nil {
+			return fmt.Errorf("%w: employee already has an open assignment", domain.ErrConflict)
+		}
+		assignments = append(assignments, other)
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].StartDate.Before(assignments[j].StartDate.Time) })
+
+	s.assignments[a.EmployeeID] = assignments
+	return nil
+}
+
+// removeAssignments drops the assignments of the employee matching drop,
+// keeping the slice in the map fresh so that snapshots are not affected.
+func (s *Store) removeAssignments(employeeID int, drop func(*domain.Assignment) bool) {
+	kept := []*domain.Assignment{}
+	for _, a := range s.assignments[employeeID] {
+		if !drop(a) {
+			kept = append(kept, a)
+		}
+	}
+	if len(kept) == 0 {
+		delete(s.assignments, employeeID)
+		return
+	}
+	s.assignments[employeeID] = kept
+}
+
+func copyAssignment(a *domain.Assignment) *domain.Assignment {
+	c := *a
+	if a.EndDate != nil {
+		end := *a.EndDate
+		c.EndDate = &end
+	}
+	return &c
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type AuditRepo struct {
+	store *Store
+}
+
+func NewAuditRepo(store *Store) *AuditRepo {
+	return &AuditRepo{store: store}
+}
+
+func (r *AuditRepo) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.employees[entry.EmployeeID]; !ok {
+		return fmt.Errorf("%w: audit entry employeeId refers to a missing employee", domain.ErrConflict)
+	}
+	for _, other := range s.audit {
+		if other.EmployeeID == entry.EmployeeID && other.Seq == entry.Seq {
+			return fmt.Errorf("%w: audit entry %d of employee %d already exists", domain.ErrConflict, entry.Seq, entry.EmployeeID)
+		}
+	}
+
+	e := copyAuditEntry(entry)
+	e.ID = len(s.audit) + 1
+	s.audit = append(s.audit, e)
+	entry.ID = e.ID
+
+	return nil
+}
+
+func (r *AuditRepo) Last(ctx context.Context, employeeID int) (*domain.AuditEntry, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		if s.audit[i].EmployeeID == employeeID {
+			return copyAuditEntry(s.audit[i]), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *AuditRepo) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.AuditEntry, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	entries := []*domain.AuditEntry{}
+	for _, e := range s.audit {
+		if e.EmployeeID == employeeID {
+			entries = append(entries, copyAuditEntry(e))
+		}
+	}
+	return entries, nil
+}
+
+func copyAuditEntry(entry *domain.AuditEntry) *domain.AuditEntry {
+	c := *entry
+	c.Changes = append([]domain.FieldChange(nil), entry.Changes...)
+	return &c
+}
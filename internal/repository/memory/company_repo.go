@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type CompanyRepo struct {
+	store *Store
+}
+
+func NewCompanyRepo(store *Store) *CompanyRepo {
+	return &CompanyRepo{store: store}
+}
+
+func (r *CompanyRepo) Create(ctx context.Context, company *domain.Company) (int, error) {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if err := s.checkCompanyUnique(company); err != nil {
+		return 0, err
+	}
+
+	s.lastCompanyID++
+	c := copyCompany(company)
+	c.ID = s.lastCompanyID
+	s.companies[c.ID] = c
+
+	return c.ID, nil
+}
+
+func (r *CompanyRepo) GetByID(ctx context.Context, id int) (*domain.Company, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	company, ok := s.companies[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("company")
+	}
+	return copyCompany(company), nil
+}
+
+func (r *CompanyRepo) List(ctx context.Context) ([]*domain.Company, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	companies := make([]*domain.Company, 0, len(s.companies))
+	for _, company := range s.companies {
+		companies = append(companies, copyCompany(company))
+	}
+	sort.Slice(companies, func(i, j int) bool { return companies[i].ID < companies[j].ID })
+
+	return companies, nil
+}
+
+func (r *CompanyRepo) Update(ctx context.Context, company *domain.Company) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.companies[company.ID]; !ok {
+		return domain.NewNotFoundError("company")
+	}
+	if err := s.checkCompanyUnique(company); err != nil {
+		return err
+	}
+
+	s.companies[company.ID] = copyCompany(company)
+	return nil
+}
+
+func (r *CompanyRepo) Delete(ctx context.Context, id int) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.companies[id]; !ok {
+		return domain.NewNotFoundError("company")
+	}
+	for _, emp := range s.employees {
+		if emp.CompanyID == id {
+			return fmt.Errorf("failed to delete company: %w: company is still referenced by employee companyId", domain.ErrConflict)
+		}
+	}
+	for _, dept := range s.departments {
+		if dept.CompanyID == id {
+			return fmt.Errorf("failed to delete company: %w: company is still referenced by department companyId", domain.ErrConflict)
+		}
+	}
+
+	// Webhook subscriptions and their deliveries go with the company, like
+	// ON DELETE CASCADE in the Postgres schema.
+	for subID, sub := range s.webhooks {
+		if sub.CompanyID == id {
+			s.deleteWebhook(subID)
+		}
+	}
+
+	delete(s.companies, id)
+	return nil
+}
+
+func (s *Store) checkCompanyUnique(company *domain.Company) error {
+	for _, other := range s.companies {
+		if other.ID != company.ID && other.TaxID == company.TaxID {
+			return domain.NewConflictError("company", "taxId")
+		}
+	}
+	return nil
+}
+
+// checkCompanyExists mirrors the companies foreign key.
+func (s *Store) checkCompanyExists(id int) error {
+	if _, ok := s.companies[id]; !ok {
+		return fmt.Errorf("%w: companyId refers to a missing company", domain.ErrConflict)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type DepartmentRepo struct {
+	store *Store
+}
+
+func NewDepartmentRepo(store *Store) *DepartmentRepo {
+	return &DepartmentRepo{store: store}
+}
+
+func (r *DepartmentRepo) GetOrCreate(ctx context.Context, dept *domain.Department) (int, bool, error) {
+	s := r.store
+	defer s.lock(ctx)()
+
+	for _, existing := range s.departments {
+		if existing.CompanyID == dept.CompanyID && existing.Name == dept.Name {
+			return existing.ID, false, nil
+		}
+	}
+
+	if err := s.checkCompanyExists(dept.CompanyID); err != nil {
+		return 0, false, err
+	}
+	if err := s.checkDepartmentParent(dept); err != nil {
+		return 0, false, err
+	}
+	if err := s.checkDepartmentUnique(dept); err != nil {
+		return 0, false, err
+	}
+
+	s.lastDepartmentID++
+	d := copyDepartment(dept)
+	d.ID = s.lastDepartmentID
+	s.departments[d.ID] = d
+
+	return d.ID, true, nil
+}
+
+func (r *DepartmentRepo) GetByID(ctx context.Context, id int) (*domain.Department, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	dept, ok := s.departments[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("department")
+	}
+	return copyDepartment(dept), nil
+}
+
+func (r *DepartmentRepo) ListByCompany(ctx context.Context, companyID int) ([]*domain.DepartmentSummary, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	counts := make(map[int]int)
+	for _, emp := range s.employees {
+		if emp.DepartmentID != nil && emp.DeletedAt == nil {
+			counts[*emp.DepartmentID]++
+		}
+	}
+
+	departments := []*domain.DepartmentSummary{}
+	for _, dept := range s.departments {
+		if dept.CompanyID == companyID {
+			departments = append(departments, &domain.DepartmentSummary{
+				Department:    *copyDepartment(dept),
+				EmployeeCount: counts[dept.ID],
+			})
+		}
+	}
+	sort.Slice(departments, func(i, j int) bool { return departments[i].Name < departments[j].Name })
+
+	return departments, nil
+}
+
+func (r *DepartmentRepo) Update(ctx context.Context, dept *domain.Department) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if dept.Name == "" && dept.Phone == "" && dept.ParentID == nil {
+		return domain.NewValidationError("", "no fields to update")
+	}
+
+	existing, ok := s.departments[dept.ID]
+	if !ok {
+		return domain.NewNotFoundError("department")
+	}
+
+	updated := copyDepartment(existing)
+	if dept.Name != "" {
+		updated.Name = dept.Name
+	}
+	if dept.Phone != "" {
+		updated.Phone = dept.Phone
+	}
+	// A parent ID of 0 moves the department to the top level.
+	if dept.ParentID != nil {
+		updated.ParentID = nil
+		if *dept.ParentID != 0 {
+			parentID := *dept.ParentID
+			updated.ParentID = &parentID
+		}
+	}
+
+	if err := s.checkDepartmentParent(updated); err != nil {
+		return err
+	}
+	if err := s.checkDepartmentUnique(updated); err != nil {
+		return err
+	}
+
+	s.departments[dept.ID] = updated
+	return nil
+}
+
+func (r *DepartmentRepo) Delete(ctx context.Context, id int) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.departments[id]; !ok {
+		return domain.NewNotFoundError("department")
+	}
+
+	// Mirrors ON DELETE RESTRICT: employees, deleted ones included, child
+	// departments and assignments, past and scheduled, keep the department.
+	for _, emp := range s.employees {
+		if emp.DepartmentID != nil && *emp.DepartmentID == id {
+			return fmt.Errorf("failed to delete department: %w: department is still referenced by employee departmentId", domain.ErrConflict)
+		}
+	}
+	for _, dept := range s.departments {
+		if dept.ParentID != nil && *dept.ParentID == id {
+			return fmt.Errorf("failed to delete department: %w: department is still referenced by department parentId", domain.ErrConflict)
+		}
+	}
+	for _, assignments := range s.assignments {
+		for _, a := range assignments {
+			if a.DepartmentID == id {
+				return fmt.Errorf("failed to delete department: %w: department is still referenced by assignment departmentId", domain.ErrConflict)
+			}
+		}
+	}
+	delete(s.departments, id)
+
+	return nil
+}
+
+// LockHierarchy does nothing: WithinTx already holds the whole store.
+func (r *DepartmentRepo) LockHierarchy(ctx context.Context, companyID int) error {
+	return nil
+}
+
+func (s *Store) checkDepartmentUnique(dept *domain.Department) error {
+	for _, other := range s.departments {
+		if other.ID == dept.ID {
+			continue
+		}
+		if other.Phone == dept.Phone {
+			return domain.NewConflictError("department", "phone")
+		}
+		if other.CompanyID == dept.CompanyID && other.Name == dept.Name {
+			return domain.NewConflictError("department", "name")
+		}
+	}
+	return nil
+}
+
+// checkDepartmentParent mirrors the parent foreign key and the check that no
+// department is its own parent.
+func (s *Store) checkDepartmentParent(dept *domain.Department) error {
+	if dept.ParentID == nil {
+		return nil
+	}
+	if _, ok := s.departments[*dept.ParentID]; !ok {
+		return fmt.Errorf("%w: department parentId refers to a missing department", domain.ErrConflict)
+	}
+	if *dept.ParentID == dept.ID {
+		return fmt.Errorf("failed to write department: department %d cannot be its own parent", dept.ID)
+	}
+	return nil
+}
+
+// subdepartments returns the IDs of the department and every department
+// below it. The caller must hold the lock.
+func (s *Store) subdepartments(id int) map[int]bool {
+	ids := map[int]bool{id: true}
+	for grown := true; grown; {
+		grown = false
+		for _, dept := range s.departments {
+			if dept.ParentID != nil && ids[*dept.ParentID] && !ids[dept.ID] {
+				ids[dept.ID] = true
+				grown = true
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,121 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepartmentRepo_GetOrCreate(t *testing.T) {
+	ctx := context.Background()
+	empRepo, repo, companyID := newEmployeeFixture(t)
+
+	id, created, err := repo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "HR", Phone: "+100"})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	t.Run("Success: same name in the same company is reused", func(t *testing.T) {
+		again, created, err := repo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "HR", Phone: "+200"})
+		assert.NoError(t, err)
+		assert.Equal(t, id, again)
+		assert.False(t, created)
+	})
+
+	t.Run("Error: phone is unique across companies", func(t *testing.T) {
+		_, _, err := repo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "Finance", Phone: "+100"})
+
+		var conflictErr *domain.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "phone", conflictErr.Field)
+	})
+
+	t.Run("Error: rename to a taken name", func(t *testing.T) {
+		otherID, _, err := repo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "Sales", Phone: "+300"})
+		require.NoError(t, err)
+
+		err = repo.Update(ctx, &domain.Department{ID: otherID, Name: "HR"})
+
+		var conflictErr *domain.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "name", conflictErr.Field)
+	})
+
+	t.Run("Error: delete keeps employees in the department", func(t *testing.T) {
+		empID, err := empRepo.Create(ctx, &domain.Employee{
+			Name: "John", Phone: "+1", CompanyID: companyID, PassportNumber: "1", DepartmentID: &id,
+		})
+		require.NoError(t, err)
+
+		departments, err := repo.ListByCompany(ctx, companyID)
+		require.NoError(t, err)
+		require.Len(t, departments, 2)
+		assert.Equal(t, "HR", departments[0].Name)
+		assert.Equal(t, 1, departments[0].EmployeeCount)
+
+		assert.ErrorIs(t, repo.Delete(ctx, id), domain.ErrConflict)
+
+		emp, err := empRepo.GetByID(ctx, empID)
+		require.NoError(t, err)
+		assert.Equal(t, &id, emp.DepartmentID)
+
+		require.NoError(t, empRepo.Delete(ctx, empID, 0))
+		assert.ErrorIs(t, repo.Delete(ctx, id), domain.ErrConflict, "deleted employees count too")
+
+		_, err = repo.GetByID(ctx, id)
+		require.NoError(t, err)
+	})
+}
+
+func TestDepartmentRepo_DeleteKeepsAssignments(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	companyID, err := memory.NewCompanyRepo(store).Create(ctx, &domain.Company{LegalName: "Acme LLC", TaxID: "1", DefaultCountry: "RU", Active: true})
+	require.NoError(t, err)
+
+	repo := memory.NewDepartmentRepo(store)
+	oldID, _, err := repo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "HR", Phone: "+100"})
+	require.NoError(t, err)
+	newID, _, err := repo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "Sales", Phone: "+200"})
+	require.NoError(t, err)
+
+	// The employee has moved on; only the history points at the old department.
+	empID, err := memory.NewEmployeeRepo(store).Create(ctx, &domain.Employee{
+		Name: "John", Phone: "+1", CompanyID: companyID, PassportNumber: "1", DepartmentID: &newID,
+	})
+	require.NoError(t, err)
+	end, err := domain.ParseDate("2024-06-01")
+	require.NoError(t, err)
+	start, err := domain.ParseDate("2024-01-01")
+	require.NoError(t, err)
+	_, err = memory.NewAssignmentRepo(store).Create(ctx, &domain.Assignment{
+		EmployeeID: empID, DepartmentID: oldID, StartDate: start, EndDate: &end,
+	})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, repo.Delete(ctx, oldID), domain.ErrConflict)
+
+	_, err = repo.GetByID(ctx, oldID)
+	require.NoError(t, err)
+}
+
+func TestCompanyRepo_Delete(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	companies := memory.NewCompanyRepo(store)
+
+	companyID, err := companies.Create(ctx, &domain.Company{LegalName: "Acme LLC", TaxID: "1", DefaultCountry: "RU", Active: true})
+	require.NoError(t, err)
+
+	_, err = companies.Create(ctx, &domain.Company{LegalName: "Other LLC", TaxID: "1", DefaultCountry: "RU"})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	_, _, err = memory.NewDepartmentRepo(store).GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "HR", Phone: "+1"})
+	require.NoError(t, err)
+
+	err = companies.Delete(ctx, companyID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type DocumentRepo struct {
+	store *Store
+}
+
+func NewDocumentRepo(store *Store) *DocumentRepo {
+	return &DocumentRepo{store: store}
+}
+
+func (r *DocumentRepo) Create(ctx context.Context, doc *domain.IdentityDocument) (int, error) {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.employees[doc.EmployeeID]; !ok {
+		return 0, fmt.Errorf("%w: document employeeId refers to a missing employee", domain.ErrConflict)
+	}
+	if err := s.checkDocumentUnique(doc); err != nil {
+		return 0, err
+	}
+
+	s.lastDocumentID++
+	d := copyDocument(doc)
+	d.ID = s.lastDocumentID
+	s.documents[d.ID] = d
+
+	return d.ID, nil
+}
+
+func (r *DocumentRepo) GetByID(ctx context.Context, id int) (*domain.IdentityDocument, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	doc, ok := s.documents[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("document")
+	}
+	return copyDocument(doc), nil
+}
+
+func (r *DocumentRepo) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.IdentityDocument, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	docs := []*domain.IdentityDocument{}
+	for _, doc := range s.documents {
+		if doc.EmployeeID == employeeID {
+			docs = append(docs, copyDocument(doc))
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs, nil
+}
+
+func (r *DocumentRepo) Update(ctx context.Context, doc *domain.IdentityDocument) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	existing, ok := s.documents[doc.ID]
+	if !ok {
+		return domain.NewNotFoundError("document")
+	}
+
+	d := copyDocument(doc)
+	d.EmployeeID = existing.EmployeeID
+	if err := s.checkDocumentUnique(d); err != nil {
+		return err
+	}
+	s.documents[d.ID] = d
+	return nil
+}
+
+func (r *DocumentRepo) Delete(ctx context.Context, id int) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.documents[id]; !ok {
+		return domain.NewNotFoundError("document")
+	}
+	delete(s.documents, id)
+	return nil
+}
+
+func (r *DocumentRepo) ListExpiring(ctx context.Context, companyID int, from, to domain.Date) ([]*domain.ExpiringDocument, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	docs := []*domain.ExpiringDocument{}
+	for _, doc := range s.documents {
+		emp := s.employees[doc.EmployeeID]
+		if emp.CompanyID != companyID || emp.DeletedAt != nil || doc.ExpiresOn == nil {
+			continue
+		}
+		if doc.ExpiresOn.Before(from.Time) || doc.ExpiresOn.After(to.Time) {
+			continue
+		}
+		docs = append(docs, &domain.ExpiringDocument{
+			IdentityDocument: *copyDocument(doc),
+			EmployeeName:     emp.Name,
+			EmployeeSurname:  emp.Surname,
+		})
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if !docs[i].ExpiresOn.Equal(docs[j].ExpiresOn.Time) {
+			return docs[i].ExpiresOn.Before(docs[j].ExpiresOn.Time)
+		}
+		return docs[i].ID < docs[j].ID
+	})
+	return docs, nil
+}
+
+// checkDocumentUnique mirrors the unique index on (type, number), which
+// leaves out the retired documents of deleted employees, and the unique
+// index allowing one primary document per employee.
+func (s *Store) checkDocumentUnique(doc *domain.IdentityDocument) error {
+	for _, other := range s.documents {
+		if other.ID == doc.ID {
+			continue
+		}
+		retired := s.employees[other.EmployeeID].DeletedAt != nil
+		if !retired && other.Type == doc.Type && other.Number == doc.Number {
+			return domain.NewConflictError("document", "number")
+		}
+		if doc.Primary && other.Primary && other.EmployeeID == doc.EmployeeID {
+			return domain.NewConflictError("document", "primary")
+		}
+	}
+	return nil
+}
+
+func copyDocument(doc *domain.IdentityDocument) *domain.IdentityDocument {
+	c := *doc
+	if doc.IssuedOn != nil {
+		d := *doc.IssuedOn
+		c.IssuedOn = &d
+	}
+	if doc.ExpiresOn != nil {
+		d := *doc.ExpiresOn
+		c.ExpiresOn = &d
+	}
+	return &c
+}
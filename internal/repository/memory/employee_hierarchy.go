@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// GetDirectReports returns the live employees whose manager is managerID, in
+// ID order.
+func (r *EmployeeRepo) GetDirectReports(ctx context.Context, managerID int) ([]*domain.Employee, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	reports := []*domain.Employee{}
+	for _, emp := range s.directReports(managerID) {
+		reports = append(reports, s.withDepartment(emp))
+	}
+	return reports, nil
+}
+
+// GetManagementChain returns the live managers above the employee, nearest
+// first, stopping at a deleted manager like the Postgres repository does.
+func (r *EmployeeRepo) GetManagementChain(ctx context.Context, id int) ([]*domain.Employee, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	chain := []*domain.Employee{}
+	seen := map[int]bool{id: true}
+	emp := s.employees[id]
+	for emp != nil && emp.ManagerID != nil && !seen[*emp.ManagerID] {
+		manager, ok := s.employees[*emp.ManagerID]
+		if !ok || manager.DeletedAt != nil {
+			break
+		}
+		seen[manager.ID] = true
+		chain = append(chain, s.withDepartment(manager))
+		emp = manager
+	}
+	return chain, nil
+}
+
+// GetSubordinates returns every live employee below the manager, breadth
+// first, each level in ID order.
+func (r *EmployeeRepo) GetSubordinates(ctx context.Context, managerID int) ([]*domain.Subordinate, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	subordinates := []*domain.Subordinate{}
+	seen := map[int]bool{managerID: true}
+	level := []int{managerID}
+	for depth := 1; len(level) > 0; depth++ {
+		var next []int
+		for _, id := range level {
+			for _, emp := range s.directReports(id) {
+				if seen[emp.ID] {
+					continue
+				}
+				seen[emp.ID] = true
+				subordinates = append(subordinates, &domain.Subordinate{Employee: s.withDepartment(emp), Depth: depth})
+				next = append(next, emp.ID)
+			}
+		}
+		level = next
+	}
+
+	sort.SliceStable(subordinates, func(i, j int) bool {
+		a, b := subordinates[i], subordinates[j]
+		return a.Depth < b.Depth || a.Depth == b.Depth && a.ID < b.ID
+	})
+	return subordinates, nil
+}
+
+// directReports returns the stored live employees reporting to managerID in
+// ID order. The caller must hold the lock.
+func (s *Store) directReports(managerID int) []*domain.Employee {
+	var reports []*domain.Employee
+	for _, emp := range s.employees {
+		if emp.ManagerID != nil && *emp.ManagerID == managerID && emp.DeletedAt == nil {
+			reports = append(reports, emp)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].ID < reports[j].ID })
+	return reports
+}
+
+// GetManagerIDs returns the IDs of the managers above the employee, nearest
+// first, deleted managers included.
+func (r *EmployeeRepo) GetManagerIDs(ctx context.Context, id int) ([]int, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	ids := []int{}
+	seen := map[int]bool{id: true}
+	for emp := s.employees[id]; emp != nil && emp.ManagerID != nil; {
+		managerID := *emp.ManagerID
+		ids = append(ids, managerID)
+		if seen[managerID] {
+			break
+		}
+		seen[managerID] = true
+		emp = s.employees[managerID]
+	}
+	return ids, nil
+}
+
+// LockReportingLines does nothing: WithinTx already holds the whole store.
+func (r *EmployeeRepo) LockReportingLines(ctx context.Context, companyID int) error {
+	return nil
+}
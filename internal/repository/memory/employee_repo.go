@@ -0,0 +1,338 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type EmployeeRepo struct {
+	store *Store
+}
+
+func NewEmployeeRepo(store *Store) *EmployeeRepo {
+	return &EmployeeRepo{store: store}
+}
+
+func (r *EmployeeRepo) Create(ctx context.Context, emp *domain.Employee) (int, error) {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if err := s.checkEmployeeRefs(emp); err != nil {
+		return 0, err
+	}
+	if err := s.checkEmployeeUnique(emp); err != nil {
+		return 0, err
+	}
+
+	s.lastEmployeeID++
+	e := copyEmployee(emp)
+	e.ID = s.lastEmployeeID
+	e.Version = 1
+	e.DeletedAt = nil
+	s.employees[e.ID] = e
+	emp.Version = e.Version
+
+	return e.ID, nil
+}
+
+func (r *EmployeeRepo) GetByID(ctx context.Context, id int) (*domain.Employee, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	emp, ok := s.employees[id]
+	if !ok || emp.DeletedAt != nil {
+		return nil, domain.NewNotFoundError("employee")
+	}
+	return s.withDepartment(emp), nil
+}
+
+func (r *EmployeeRepo) Update(ctx context.Context, emp *domain.Employee) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	existing, ok := s.employees[emp.ID]
+	if !ok || existing.DeletedAt != nil {
+		return domain.NewNotFoundError("employee")
+	}
+	if emp.Version != 0 && emp.Version != existing.Version {
+		return domain.NewVersionConflictError("employee", emp.Version)
+	}
+
+	updated := copyEmployee(emp)
+	updated.Version = existing.Version + 1
+
+	if err := s.checkEmployeeRefs(updated); err != nil {
+		return err
+	}
+	if err := s.checkEmployeeUnique(updated); err != nil {
+		return err
+	}
+
+	s.employees[emp.ID] = updated
+	emp.Version = updated.Version
+	return nil
+}
+
+func (r *EmployeeRepo) Delete(ctx context.Context, id, version int) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	existing, ok := s.employees[id]
+	if !ok || existing.DeletedAt != nil {
+		return domain.NewNotFoundError("employee")
+	}
+	if version != 0 && version != existing.Version {
+		return domain.NewVersionConflictError("employee", version)
+	}
+
+	deleted := copyEmployee(existing)
+	now := time.Now().UTC()
+	deleted.DeletedAt = &now
+	deleted.Version++
+	s.employees[id] = deleted
+
+	return nil
+}
+
+func (r *EmployeeRepo) Restore(ctx context.Context, id int) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	existing, ok := s.employees[id]
+	if !ok {
+		return domain.NewNotFoundError("employee")
+	}
+	if existing.DeletedAt == nil {
+		return fmt.Errorf("%w: employee is not deleted", domain.ErrConflict)
+	}
+
+	restored := copyEmployee(existing)
+	restored.DeletedAt = nil
+	if err := s.checkEmployeeUnique(restored); err != nil {
+		return err
+	}
+	for _, doc := range s.documents {
+		if doc.EmployeeID != id {
+			continue
+		}
+		if err := s.checkDocumentUnique(doc); err != nil {
+			return err
+		}
+	}
+	restored.Version++
+	s.employees[id] = restored
+
+	return nil
+}
+
+func (r *EmployeeRepo) GetByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	return r.list(ctx, func(emp *domain.Employee) bool {
+		return emp.CompanyID == companyID
+	}, filter, page)
+}
+
+func (r *EmployeeRepo) GetByDepartment(ctx context.Context, companyID, deptID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	// The scope runs with the store locked by list.
+	var departments map[int]bool
+	return r.list(ctx, func(emp *domain.Employee) bool {
+		if emp.CompanyID != companyID {
+			return false
+		}
+		if departments == nil {
+			departments = map[int]bool{deptID: true}
+			if filter.IncludeSubdepartments {
+				departments = r.store.subdepartments(deptID)
+			}
+		}
+		if filter.AsOf != nil {
+			return r.store.assignedOn(emp.ID, departments, *filter.AsOf)
+		}
+		return emp.DepartmentID != nil && departments[*emp.DepartmentID]
+	}, filter, page)
+}
+
+// StreamByCompany passes the employees of the company matching filter to fn
+// in ID order. The matches are copied first, so fn runs without the store
+// locked.
+func (r *EmployeeRepo) StreamByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	s := r.store
+	unlock := s.rlock(ctx)
+	var matched []*domain.Employee
+	for _, emp := range s.employees {
+		if emp.CompanyID == companyID && matchesFilter(emp, filter) {
+			matched = append(matched, s.withDepartment(emp))
+		}
+	}
+	unlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	for _, emp := range matched {
+		if err := fn(emp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EmployeeRepo) list(ctx context.Context, scope func(*domain.Employee) bool, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	if !domain.IsEmployeeSortField(page.SortBy) {
+		return nil, domain.NewValidationError("sort", "unsupported sort field "+page.SortBy)
+	}
+
+	cursor, err := domain.DecodeCursorFor(page)
+	if err != nil {
+		return nil, err
+	}
+
+	s := r.store
+	defer s.rlock(ctx)()
+
+	var matched []*domain.Employee
+	for _, emp := range s.employees {
+		if scope(emp) && matchesFilter(emp, filter) {
+			matched = append(matched, emp)
+		}
+	}
+
+	less := func(a, b *domain.Employee) bool {
+		c := compareSortKeys(page.SortBy, domain.EmployeeSortKey(a, page.SortBy), a.ID, domain.EmployeeSortKey(b, page.SortBy), b.ID)
+		if page.SortDesc {
+			return c > 0
+		}
+		return c < 0
+	}
+	sort.Slice(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+
+	result := &domain.EmployeePage{Items: []*domain.Employee{}}
+	if page.IncludeTotal {
+		total := len(matched)
+		result.Total = &total
+	}
+
+	for _, emp := range matched {
+		if page.Cursor != "" {
+			c := compareSortKeys(page.SortBy, domain.EmployeeSortKey(emp, page.SortBy), emp.ID, cursor.Value, cursor.ID)
+			if (!page.SortDesc && c <= 0) || (page.SortDesc && c >= 0) {
+				continue
+			}
+		}
+		if len(result.Items) == page.Limit {
+			last := result.Items[len(result.Items)-1]
+			result.NextCursor = domain.EncodeCursor(domain.Cursor{
+				Sort:  page.SortBy,
+				Desc:  page.SortDesc,
+				Value: domain.EmployeeSortKey(last, page.SortBy),
+				ID:    last.ID,
+			})
+			break
+		}
+		result.Items = append(result.Items, s.withDepartment(emp))
+	}
+
+	return result, nil
+}
+
+// withDepartment returns a copy of emp with its department joined, the way
+// the Postgres repository returns employees.
+func (s *Store) withDepartment(emp *domain.Employee) *domain.Employee {
+	c := copyEmployee(emp)
+	if emp.DepartmentID != nil {
+		if d, ok := s.departments[*emp.DepartmentID]; ok {
+			c.Department = copyDepartment(d)
+		}
+	}
+	return c
+}
+
+func matchesFilter(emp *domain.Employee, f domain.EmployeeFilter) bool {
+	if f.NamePrefix != "" && !hasPrefixFold(emp.Name, f.NamePrefix) {
+		return false
+	}
+	if f.SurnamePrefix != "" && !hasPrefixFold(emp.Surname, f.SurnamePrefix) {
+		return false
+	}
+	if f.Phone != "" && emp.Phone != f.Phone {
+		return false
+	}
+	if f.PassportType != "" && emp.PassportType != f.PassportType {
+		return false
+	}
+	if f.DepartmentID != nil && (emp.DepartmentID == nil || *emp.DepartmentID != *f.DepartmentID) {
+		return false
+	}
+	if f.NoDepartment && emp.DepartmentID != nil {
+		return false
+	}
+	if !f.IncludeDeleted && emp.DeletedAt != nil {
+		return false
+	}
+	return true
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+}
+
+// compareSortKeys orders (value, id) pairs the way the Postgres keyset
+// condition does: numerically for integer columns, then by ID.
+func compareSortKeys(field, a string, aID int, b string, bID int) int {
+	var c int
+	switch field {
+	case "id":
+	case "companyId", "departmentId":
+		an, _ := strconv.Atoi(a)
+		bn, _ := strconv.Atoi(b)
+		c = an - bn
+	default:
+		c = strings.Compare(a, b)
+	}
+	if c != 0 {
+		return c
+	}
+	return aID - bID
+}
+
+// checkEmployeeRefs mirrors the company, department and manager foreign keys
+// and the check that nobody manages themselves.
+func (s *Store) checkEmployeeRefs(emp *domain.Employee) error {
+	if err := s.checkCompanyExists(emp.CompanyID); err != nil {
+		return err
+	}
+	if emp.DepartmentID != nil {
+		if _, ok := s.departments[*emp.DepartmentID]; !ok {
+			return fmt.Errorf("%w: employee departmentId refers to a missing department", domain.ErrConflict)
+		}
+	}
+	if emp.ManagerID != nil {
+		if _, ok := s.employees[*emp.ManagerID]; !ok {
+			return fmt.Errorf("%w: employee managerId refers to a missing employee", domain.ErrConflict)
+		}
+		if *emp.ManagerID == emp.ID {
+			return fmt.Errorf("failed to write employee: employee %d cannot manage themselves", emp.ID)
+		}
+	}
+	return nil
+}
+
+// checkEmployeeUnique mirrors the partial unique index on phones: only live
+// employees take part. Passports are unique as primary documents.
+func (s *Store) checkEmployeeUnique(emp *domain.Employee) error {
+	if emp.DeletedAt != nil {
+		return nil
+	}
+	for _, other := range s.employees {
+		if other.ID == emp.ID || other.DeletedAt != nil {
+			continue
+		}
+		if other.Phone == emp.Phone {
+			return domain.NewConflictError("employee", "phone")
+		}
+	}
+	return nil
+}
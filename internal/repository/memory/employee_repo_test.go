@@ -0,0 +1,237 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEmployeeFixture(t *testing.T) (*memory.EmployeeRepo, *memory.DepartmentRepo, int) {
+	t.Helper()
+
+	store := memory.NewStore()
+	companyID, err := memory.NewCompanyRepo(store).Create(context.Background(), &domain.Company{
+		LegalName:      "Acme LLC",
+		TaxID:          "7701234567",
+		DefaultCountry: "RU",
+		Active:         true,
+	})
+	require.NoError(t, err)
+
+	return memory.NewEmployeeRepo(store), memory.NewDepartmentRepo(store), companyID
+}
+
+func TestEmployeeRepo_Uniqueness(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		duplicate     domain.Employee
+		expectedField string
+	}{
+		{
+			name:          "Error: duplicate phone",
+			duplicate:     domain.Employee{Name: "Jane", Surname: "Doe", Phone: "+79998887766", PassportNumber: "2"},
+			expectedField: "phone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _, companyID := newEmployeeFixture(t)
+
+			_, err := repo.Create(ctx, &domain.Employee{
+				Name: "John", Surname: "Doe", Phone: "+79998887766", CompanyID: companyID, PassportNumber: "1",
+			})
+			require.NoError(t, err)
+
+			tt.duplicate.CompanyID = companyID
+			_, err = repo.Create(ctx, &tt.duplicate)
+
+			var conflictErr *domain.ConflictError
+			require.ErrorAs(t, err, &conflictErr)
+			assert.Equal(t, tt.expectedField, conflictErr.Field)
+		})
+	}
+
+	t.Run("Error: update to a taken phone", func(t *testing.T) {
+		repo, _, companyID := newEmployeeFixture(t)
+
+		_, err := repo.Create(ctx, &domain.Employee{Name: "John", Phone: "+1", CompanyID: companyID, PassportNumber: "1"})
+		require.NoError(t, err)
+		id, err := repo.Create(ctx, &domain.Employee{Name: "Jane", Phone: "+2", CompanyID: companyID, PassportNumber: "2"})
+		require.NoError(t, err)
+
+		emp := &domain.Employee{ID: id, Name: "Jane", Phone: "+1", CompanyID: companyID, PassportNumber: "2"}
+		err = repo.Update(ctx, emp)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		emp.Phone = "+2"
+		err = repo.Update(ctx, emp)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, emp.Version)
+	})
+
+	t.Run("Error: unknown company", func(t *testing.T) {
+		repo, _, _ := newEmployeeFixture(t)
+
+		_, err := repo.Create(ctx, &domain.Employee{Name: "John", Phone: "+1", CompanyID: 9999, PassportNumber: "1"})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+func TestEmployeeRepo_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	repo, _, companyID := newEmployeeFixture(t)
+
+	id, err := repo.Create(ctx, &domain.Employee{Name: "John", Phone: "+1", CompanyID: companyID, PassportNumber: "1"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, id, 1))
+
+	_, err = repo.GetByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	page := domain.PageRequest{Limit: 10, SortBy: "id"}
+	result, err := repo.GetByCompany(ctx, companyID, domain.EmployeeFilter{}, page)
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+
+	result, err = repo.GetByCompany(ctx, companyID, domain.EmployeeFilter{IncludeDeleted: true}, page)
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.NotNil(t, result.Items[0].DeletedAt)
+	assert.Equal(t, 2, result.Items[0].Version)
+
+	t.Run("Error: deleting twice is not found", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Delete(ctx, id, 0), domain.ErrNotFound)
+	})
+
+	t.Run("Success: rehire reuses the phone and passport", func(t *testing.T) {
+		_, err := repo.Create(ctx, &domain.Employee{Name: "Jane", Phone: "+1", CompanyID: companyID, PassportNumber: "1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Error: restore clashes with the rehired employee", func(t *testing.T) {
+		var conflictErr *domain.ConflictError
+		require.ErrorAs(t, repo.Restore(ctx, id), &conflictErr)
+		assert.Equal(t, "phone", conflictErr.Field)
+	})
+
+	t.Run("Error: restoring a live employee", func(t *testing.T) {
+		liveID, err := repo.Create(ctx, &domain.Employee{Name: "Jim", Phone: "+2", CompanyID: companyID, PassportNumber: "2"})
+		require.NoError(t, err)
+		assert.ErrorIs(t, repo.Restore(ctx, liveID), domain.ErrConflict)
+		assert.ErrorIs(t, repo.Restore(ctx, 9999), domain.ErrNotFound)
+	})
+}
+
+func TestEmployeeRepo_GetByCompany(t *testing.T) {
+	ctx := context.Background()
+	repo, deptRepo, companyID := newEmployeeFixture(t)
+
+	deptID, _, err := deptRepo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "Engineering", Phone: "+100"})
+	require.NoError(t, err)
+
+	surnames := []string{"Smirnov", "Ivanov", "Sidorov", "Petrov", "Ivanova"}
+	for i, surname := range surnames {
+		emp := &domain.Employee{
+			Name:           "Name",
+			Surname:        surname,
+			Phone:          "+7" + surname,
+			CompanyID:      companyID,
+			PassportNumber: surname,
+		}
+		if i%2 == 0 {
+			emp.DepartmentID = &deptID
+		}
+		_, err := repo.Create(ctx, emp)
+		require.NoError(t, err)
+	}
+
+	t.Run("Success: pages cover all rows in sort order", func(t *testing.T) {
+		page := domain.PageRequest{Limit: 2, SortBy: "surname", SortDesc: true, IncludeTotal: true}
+
+		var got []string
+		for {
+			result, err := repo.GetByCompany(ctx, companyID, domain.EmployeeFilter{}, page)
+			require.NoError(t, err)
+			assert.Equal(t, 5, *result.Total)
+			for _, emp := range result.Items {
+				got = append(got, emp.Surname)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			page.Cursor = result.NextCursor
+		}
+
+		assert.Equal(t, []string{"Smirnov", "Sidorov", "Petrov", "Ivanova", "Ivanov"}, got)
+	})
+
+	t.Run("Success: filters combine", func(t *testing.T) {
+		result, err := repo.GetByCompany(ctx, companyID, domain.EmployeeFilter{SurnamePrefix: "iva", NoDepartment: true},
+			domain.PageRequest{Limit: 10, SortBy: "id"})
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, "Ivanov", result.Items[0].Surname)
+	})
+
+	t.Run("Success: departments are joined", func(t *testing.T) {
+		result, err := repo.GetByDepartment(ctx, companyID, deptID, domain.EmployeeFilter{}, domain.PageRequest{Limit: 10, SortBy: "id"})
+		require.NoError(t, err)
+		require.Len(t, result.Items, 3)
+		for _, emp := range result.Items {
+			require.NotNil(t, emp.Department)
+			assert.Equal(t, "Engineering", emp.Department.Name)
+		}
+	})
+
+	t.Run("Success: empty page", func(t *testing.T) {
+		result, err := repo.GetByCompany(ctx, companyID+1, domain.EmployeeFilter{}, domain.PageRequest{Limit: 10, SortBy: "id"})
+		require.NoError(t, err)
+		assert.NotNil(t, result.Items)
+		assert.Empty(t, result.Items)
+	})
+
+	t.Run("Error: cursor reused with another sort", func(t *testing.T) {
+		result, err := repo.GetByCompany(ctx, companyID, domain.EmployeeFilter{}, domain.PageRequest{Limit: 1, SortBy: "surname"})
+		require.NoError(t, err)
+
+		_, err = repo.GetByCompany(ctx, companyID, domain.EmployeeFilter{},
+			domain.PageRequest{Limit: 1, SortBy: "phone", Cursor: result.NextCursor})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestEmployeeRepo_Search(t *testing.T) {
+	ctx := context.Background()
+	repo, _, companyID := newEmployeeFixture(t)
+
+	_, err := repo.Create(ctx, &domain.Employee{Name: "Иван", Surname: "Иванов", Phone: "+1", CompanyID: companyID, PassportNumber: "1"})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, &domain.Employee{Name: "Petr", Surname: "Petrov", Phone: "+2", CompanyID: companyID, PassportNumber: "2"})
+	require.NoError(t, err)
+
+	results, err := repo.Search(ctx, companyID, domain.SearchQuery{Variants: []string{"ivanov", "иванов"}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "<mark>Иванов</mark> Иван +1", results[0].Highlight)
+
+	results, err = repo.Search(ctx, companyID, domain.SearchQuery{Variants: []string{"petrav"}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Petrov", results[0].Employee.Surname)
+	assert.Equal(t, "<mark>Petrov</mark> Petr +2", results[0].Highlight, "typo matches are highlighted too")
+
+	_, err = repo.Create(ctx, &domain.Employee{Name: "<img src=x onerror=alert(1)>", Surname: "Sidorov", Phone: "+3", CompanyID: companyID, PassportNumber: "3"})
+	require.NoError(t, err)
+
+	results, err = repo.Search(ctx, companyID, domain.SearchQuery{Variants: []string{"sidorov img"}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "<mark>Sidorov</mark> &lt;<mark>img</mark> src=x onerror=alert(1)&gt; +3", results[0].Highlight)
+}
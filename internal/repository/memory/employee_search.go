@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// Search approximates the Postgres full-text search: a query word matches a
+// document word it prefixes, or one within a single edit of it to tolerate
+// typos. Rank is the share of query words matched, exact prefixes counting
+// double.
+func (r *EmployeeRepo) Search(ctx context.Context, companyID int, query domain.SearchQuery) ([]*domain.SearchResult, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	results := []*domain.SearchResult{}
+	for _, emp := range s.employees {
+		if emp.CompanyID != companyID || emp.DeletedAt != nil {
+			continue
+		}
+
+		var dept *domain.Department
+		fields := []string{emp.Surname, emp.Name, emp.Phone}
+		if emp.DepartmentID != nil {
+			if d, ok := s.departments[*emp.DepartmentID]; ok {
+				dept = copyDepartment(d)
+				fields = append(fields, d.Name)
+			}
+		}
+		text := strings.Join(fields, " ")
+		docWords := domain.SearchWords(text)
+
+		best := 0.0
+		for _, variant := range query.Variants {
+			words := strings.Fields(variant)
+			score := 0.0
+			for _, w := range words {
+				for _, dw := range docWords {
+					if m := domain.MatchSearchWord(dw, w); m > 0 {
+						score += float64(m)
+						break
+					}
+				}
+			}
+			if rank := score / float64(2*len(words)); rank > best {
+				best = rank
+			}
+		}
+		if best == 0 {
+			continue
+		}
+
+		found := copyEmployee(emp)
+		found.Department = dept
+		results = append(results, &domain.SearchResult{
+			Employee:  found,
+			Rank:      best,
+			Highlight: domain.HighlightSearch(text, query),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Employee.ID < results[j].Employee.ID
+	})
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
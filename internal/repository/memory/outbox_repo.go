@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type OutboxRepo struct {
+	store *Store
+}
+
+func NewOutboxRepo(store *Store) *OutboxRepo {
+	return &OutboxRepo{store: store}
+}
+
+func (r *OutboxRepo) Add(ctx context.Context, event *domain.Event) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	s.lastEventID++
+	e := copyEvent(event)
+	e.ID = s.lastEventID
+	s.outbox = append(s.outbox, e)
+	event.ID = e.ID
+
+	return nil
+}
+
+// ProcessPending passes up to limit unpublished events to fn, oldest first,
+// and marks the ones fn reports as published. fn runs without the store
+// lock, so publishing does not hold up writes.
+func (r *OutboxRepo) ProcessPending(ctx context.Context, limit int, fn func([]*domain.Event) []int64) error {
+	s := r.store
+
+	unlock := s.rlock(ctx)
+	pending := []*domain.Event{}
+	for _, e := range s.outbox {
+		if len(pending) == limit {
+			break
+		}
+		if !s.published[e.ID] {
+			pending = append(pending, copyEvent(e))
+		}
+	}
+	unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	published := fn(pending)
+
+	defer s.lock(ctx)()
+	for _, id := range published {
+		s.published[id] = true
+	}
+	return nil
+}
+
+func copyEvent(event *domain.Event) *domain.Event {
+	c := *event
+	c.Payload = append([]byte(nil), event.Payload...)
+	return &c
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// Store holds the data shared by the in-memory repositories. It enforces the
+// same uniqueness and foreign key rules as the Postgres schema so that both
+// backends fail in the same way.
+type Store struct {
+	mu sync.RWMutex
+
+	companies   map[int]*domain.Company
+	departments map[int]*domain.Department
+	employees   map[int]*domain.Employee
+	audit       []*domain.AuditEntry
+	outbox      []*domain.Event
+	// published holds the IDs of relayed outbox events. It is not part of
+	// transactions: only the relay changes it, outside of WithinTx.
+	published  map[int64]bool
+	webhooks   map[int]*domain.WebhookSubscription
+	deliveries map[int64]*domain.WebhookDelivery
+	documents  map[int]*domain.IdentityDocument
+	// assignments holds the department assignments of each employee,
+	// ordered by start date.
+	assignments map[int][]*domain.Assignment
+
+	lastCompanyID    int
+	lastDepartmentID int
+	lastEmployeeID   int
+	lastEventID      int64
+	lastWebhookID    int
+	lastDeliveryID   int64
+	lastDocumentID   int
+	lastAssignmentID int
+}
+
+func NewStore() *Store {
+	return &Store{
+		companies:   make(map[int]*domain.Company),
+		departments: make(map[int]*domain.Department),
+		employees:   make(map[int]*domain.Employee),
+		published:   make(map[int64]bool),
+		webhooks:    make(map[int]*domain.WebhookSubscription),
+		deliveries:  make(map[int64]*domain.WebhookDelivery),
+		documents:   make(map[int]*domain.IdentityDocument),
+		assignments: make(map[int][]*domain.Assignment),
+	}
+}
+
+type txKey struct{}
+
+// lock acquires the store for writing and returns the matching unlock. Inside
+// WithinTx the transaction already holds the lock, so both are no-ops.
+func (s *Store) lock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Store) rlock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+func (s *Store) inTx(ctx context.Context) bool {
+	tx, _ := ctx.Value(txKey{}).(*Store)
+	return tx == s
+}
+
+// WithinTx runs fn with the store locked for writing and restores the
+// previous state if fn fails, so the memory backend gives the same
+// all-or-nothing behaviour as a Postgres transaction. Nested calls join the
+// outer transaction.
+func (s *Store) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if s.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			s.restore(snapshot)
+			panic(p)
+		}
+		if err != nil {
+			s.restore(snapshot)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, s))
+}
+
+// snapshot copies the maps but shares the values: repositories never modify
+// stored values in place, they replace them with fresh copies. The audit
+// trail and the outbox are append-only, so keeping their length is enough.
+func (s *Store) snapshot() *Store {
+	snap := &Store{
+		companies:        make(map[int]*domain.Company, len(s.companies)),
+		departments:      make(map[int]*domain.Department, len(s.departments)),
+		employees:        make(map[int]*domain.Employee, len(s.employees)),
+		audit:            s.audit,
+		outbox:           s.outbox,
+		lastCompanyID:    s.lastCompanyID,
+		lastDepartmentID: s.lastDepartmentID,
+		lastEmployeeID:   s.lastEmployeeID,
+		lastEventID:      s.lastEventID,
+		lastWebhookID:    s.lastWebhookID,
+		lastDeliveryID:   s.lastDeliveryID,
+		webhooks:         make(map[int]*domain.WebhookSubscription, len(s.webhooks)),
+		deliveries:       make(map[int64]*domain.WebhookDelivery, len(s.deliveries)),
+		documents:        make(map[int]*domain.IdentityDocument, len(s.documents)),
+		lastDocumentID:   s.lastDocumentID,
+		assignments:      make(map[int][]*domain.Assignment, len(s.assignments)),
+		lastAssignmentID: s.lastAssignmentID,
+	}
+	for id, c := range s.companies {
+		snap.companies[id] = c
+	}
+	for id, d := range s.departments {
+		snap.departments[id] = d
+	}
+	for id, e := range s.employees {
+		snap.employees[id] = e
+	}
+	for id, w := range s.webhooks {
+		snap.webhooks[id] = w
+	}
+	for id, d := range s.deliveries {
+		snap.deliveries[id] = d
+	}
+	for id, d := range s.documents {
+		snap.documents[id] = d
+	}
+	for id, a := range s.assignments {
+		snap.assignments[id] = append([]*domain.Assignment(nil), a...)
+	}
+	return snap
+}
+
+func (s *Store) restore(snap *Store) {
+	s.companies = snap.companies
+	s.departments = snap.departments
+	s.employees = snap.employees
+	s.audit = snap.audit
+	s.outbox = snap.outbox
+	s.lastCompanyID = snap.lastCompanyID
+	s.lastDepartmentID = snap.lastDepartmentID
+	s.lastEmployeeID = snap.lastEmployeeID
+	s.lastEventID = snap.lastEventID
+	s.webhooks = snap.webhooks
+	s.deliveries = snap.deliveries
+	s.lastWebhookID = snap.lastWebhookID
+	s.lastDeliveryID = snap.lastDeliveryID
+	s.documents = snap.documents
+	s.lastDocumentID = snap.lastDocumentID
+	s.assignments = snap.assignments
+	s.lastAssignmentID = snap.lastAssignmentID
+}
+
+func copyEmployee(emp *domain.Employee) *domain.Employee {
+	c := *emp
+	if emp.DepartmentID != nil {
+		id := *emp.DepartmentID
+		c.DepartmentID = &id
+	}
+	if emp.ManagerID != nil {
+		id := *emp.ManagerID
+		c.ManagerID = &id
+	}
+	if emp.DeletedAt != nil {
+		at := *emp.DeletedAt
+		c.DeletedAt = &at
+	}
+	c.Department = nil
+	return &c
+}
+
+func copyDepartment(dept *domain.Department) *domain.Department {
+	c := *dept
+	if dept.ParentID != nil {
+		id := *dept.ParentID
+		c.ParentID = &id
+	}
+	return &c
+}
+
+func copyCompany(company *domain.Company) *domain.Company {
+	c := *company
+	return &c
+}
@@ -0,0 +1,82 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_WithinTx(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	companyID, err := memory.NewCompanyRepo(store).Create(ctx, &domain.Company{
+		LegalName: "Acme LLC", TaxID: "7701234567", DefaultCountry: "RU", Active: true,
+	})
+	require.NoError(t, err)
+
+	empRepo := memory.NewEmployeeRepo(store)
+	deptRepo := memory.NewDepartmentRepo(store)
+	auditRepo := memory.NewAuditRepo(store)
+
+	firstID, err := empRepo.Create(ctx, &domain.Employee{
+		Name: "John", Surname: "Doe", Phone: "+100", CompanyID: companyID, PassportNumber: "A1",
+	})
+	require.NoError(t, err)
+
+	t.Run("Error: failed unit of work leaves no department behind", func(t *testing.T) {
+		err := store.WithinTx(ctx, func(ctx context.Context) error {
+			deptID, _, err := deptRepo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "HR", Phone: "+200"})
+			require.NoError(t, err)
+
+			_, err = empRepo.Create(ctx, &domain.Employee{
+				Name: "Jane", Surname: "Roe", Phone: "+100", CompanyID: companyID,
+				DepartmentID: &deptID, PassportNumber: "A2",
+			})
+			return err
+		})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		departments, err := deptRepo.ListByCompany(ctx, companyID)
+		require.NoError(t, err)
+		assert.Empty(t, departments)
+	})
+
+	t.Run("Success: committed unit of work is visible", func(t *testing.T) {
+		var empID int
+		err := store.WithinTx(ctx, func(ctx context.Context) error {
+			deptID, _, err := deptRepo.GetOrCreate(ctx, &domain.Department{CompanyID: companyID, Name: "HR", Phone: "+200"})
+			if err != nil {
+				return err
+			}
+
+			empID, err = empRepo.Create(ctx, &domain.Employee{
+				Name: "Jane", Surname: "Roe", Phone: "+300", CompanyID: companyID,
+				DepartmentID: &deptID, PassportNumber: "A2",
+			})
+			return err
+		})
+		require.NoError(t, err)
+
+		emp, err := empRepo.GetByID(ctx, empID)
+		require.NoError(t, err)
+		assert.NotNil(t, emp.DepartmentID)
+	})
+
+	t.Run("Error: audit entries of a failed unit of work are discarded", func(t *testing.T) {
+		err := store.WithinTx(ctx, func(ctx context.Context) error {
+			entry := &domain.AuditEntry{EmployeeID: firstID, Action: domain.AuditUpdate}
+			entry.Seal(nil)
+			require.NoError(t, auditRepo.Append(ctx, entry))
+			return domain.ErrConflict
+		})
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		entries, err := auditRepo.ListByEmployee(ctx, firstID)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type WebhookRepo struct {
+	store *Store
+}
+
+func NewWebhookRepo(store *Store) *WebhookRepo {
+	return &WebhookRepo{store: store}
+}
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (int, error) {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if err := s.checkCompanyExists(sub.CompanyID); err != nil {
+		return 0, err
+	}
+
+	s.lastWebhookID++
+	w := copyWebhook(sub)
+	w.ID = s.lastWebhookID
+	s.webhooks[w.ID] = w
+
+	return w.ID, nil
+}
+
+func (r *WebhookRepo) GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	sub, ok := s.webhooks[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("webhook subscription")
+	}
+	return copyWebhook(sub), nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context, companyID int) ([]*domain.WebhookSubscription, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	subs := []*domain.WebhookSubscription{}
+	for _, sub := range s.webhooks {
+		if sub.CompanyID == companyID {
+			subs = append(subs, copyWebhook(sub))
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs, nil
+}
+
+func (r *WebhookRepo) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	existing, ok := s.webhooks[sub.ID]
+	if !ok {
+		return domain.NewNotFoundError("webhook subscription")
+	}
+
+	w := copyWebhook(sub)
+	w.CompanyID = existing.CompanyID
+	w.CreatedAt = existing.CreatedAt
+	s.webhooks[w.ID] = w
+	return nil
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, id int) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return domain.NewNotFoundError("webhook subscription")
+	}
+	s.deleteWebhook(id)
+	return nil
+}
+
+// deleteWebhook removes a subscription and its deliveries. The caller holds
+// the write lock.
+func (s *Store) deleteWebhook(id int) {
+	for deliveryID, d := range s.deliveries {
+		if d.SubscriptionID == id {
+			delete(s.deliveries, deliveryID)
+		}
+	}
+	delete(s.webhooks, id)
+}
+
+func (r *WebhookRepo) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.webhooks[delivery.SubscriptionID]; !ok {
+		return fmt.Errorf("%w: webhook delivery subscriptionId refers to a missing webhook subscription", domain.ErrConflict)
+	}
+	for _, d := range s.deliveries {
+		if d.SubscriptionID == delivery.SubscriptionID && d.EventID == delivery.EventID {
+			delivery.ID = d.ID
+			return nil
+		}
+	}
+
+	s.lastDeliveryID++
+	d := copyDelivery(delivery)
+	d.ID = s.lastDeliveryID
+	s.deliveries[d.ID] = d
+	delivery.ID = d.ID
+
+	return nil
+}
+
+func (r *WebhookRepo) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("webhook delivery")
+	}
+	return copyDelivery(d), nil
+}
+
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, subscriptionID int, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	s := r.store
+	defer s.rlock(ctx)()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.SubscriptionID == subscriptionID && (status == "" || d.Status == status) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	for i, d := range deliveries {
+		deliveries[i] = copyDelivery(d)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	s := r.store
+	defer s.lock(ctx)()
+
+	if _, ok := s.deliveries[delivery.ID]; !ok {
+		return domain.NewNotFoundError("webhook delivery")
+	}
+	s.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries due at now,
+// oldest first, and moves their next attempt lease ahead so that they are
+// not claimed again while being sent.
+func (r *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	s := r.store
+	defer s.lock(ctx)()
+
+	due := []*domain.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.Status == domain.WebhookPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(*due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leased := now.Add(lease)
+	for i, d := range due {
+		c := copyDelivery(d)
+		c.NextAttemptAt = &leased
+		s.deliveries[c.ID] = c
+		due[i] = copyDelivery(c)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	return due, nil
+}
+
+func copyWebhook(sub *domain.WebhookSubscription) *domain.WebhookSubscription {
+	c := *sub
+	c.EventTypes = append([]domain.EventType{}, sub.EventTypes...)
+	return &c
+}
+
+func copyDelivery(delivery *domain.WebhookDelivery) *domain.WebhookDelivery {
+	c := *delivery
+	c.Payload = append([]byte(nil), delivery.Payload...)
+	return &c
+}
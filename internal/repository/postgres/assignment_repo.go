@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type AssignmentRepo struct {
+	db *sql.DB
+}
+
+func NewAssignmentRepo(db *sql.DB) *AssignmentRepo {
+	return &AssignmentRepo{db: db}
+}
+
+const assignmentColumns = `a.id, a.employee_id, a.department_id, a.start_date, a.end_date`
+
+func (r *AssignmentRepo) Create(ctx context.Context, a *domain.Assignment) (int, error) {
+	query := `INSERT INTO employee_assignments (employee_id, department_id, start_date, end_date)
+        VALUES ($1, $2, $3, $4) RETURNING id`
+
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		a.EmployeeID,
+		a.DepartmentID,
+		a.StartDate.String(),
+		dateValue(a.EndDate),
+	).Scan(&id)
+	if err != nil {
+		return 0, mapError(err, "failed to create assignment")
+	}
+
+	return id, nil
+}
+
+func (r *AssignmentRepo) Update(ctx context.Context, a *domain.Assignment) error {
+	query := `UPDATE employee_assignments SET department_id = $1, start_date = $2, end_date = $3 WHERE id = $4`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.DepartmentID,
+		a.StartDate.String(),
+		dateValue(a.EndDate),
+		a.ID,
+	)
+	if err != nil {
+		return mapError(err, "failed to update assignment")
+	}
+
+	return checkAffected(result, "assignment")
+}
+
+func (r *AssignmentRepo) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM employee_assignments WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err, "failed to delete assignment")
+	}
+
+	return checkAffected(result, "assignment")
+}
+
+func (r *AssignmentRepo) Last(ctx context.Context, employeeID int) (*domain.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM employee_assignments a
+        WHERE a.employee_id = $1
+        ORDER BY a.start_date DESC
+        LIMIT 1`
+
+	a, err := scanAssignment(conn(ctx, r.db).QueryRowContext(ctx, query, employeeID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, mapError(err, "failed to get assignment")
+	}
+
+	return a, nil
+}
+
+func (r *AssignmentRepo) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM employee_assignments a
+        WHERE a.employee_id = $1
+        ORDER BY a.start_date`
+
+	return r.query(ctx, query, employeeID)
+}
+
+func (r *AssignmentRepo) ListDue(ctx context.Context, day domain.Date, limit int) ([]*domain.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM employee_assignments a
+        JOIN employees e ON e.id = a.employee_id
+        WHERE a.end_date IS NULL AND a.start_date <= $1
+            AND e.deleted_at IS NULL
+            AND e.department_id IS DISTINCT FROM a.department_id
+        ORDER BY a.start_date, a.id
+        LIMIT $2`
+
+	return r.query(ctx, query, day.String(), limit)
+}
+
+func (r *AssignmentRepo) query(ctx context.Context, query string, args ...interface{}) ([]*domain.Assignment, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err, "failed to get assignments")
+	}
+	defer rows.Close()
+
+	assignments := []*domain.Assignment{}
+	for rows.Next() {
+		a, err := scanAssignment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan assignment: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return assignments, nil
+}
+
+func scanAssignment(row rowScanner) (*domain.Assignment, error) {
+	var a domain.Assignment
+	var startDate time.Time
+	var endDate sql.NullTime
+
+	if err := row.Scan(&a.ID, &a.EmployeeID, &a.DepartmentID, &startDate, &endDate); err != nil {
+		return nil, err
+	}
+
+	a.StartDate = domain.NewDate(startDate)
+	a.EndDate = nullDate(endDate)
+	return &a, nil
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type AuditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+const auditColumns = `id, employee_id, seq, action, actor, request_id, occurred_at, changes, prev_hash, hash`
+
+func (r *AuditRepo) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	query := `INSERT INTO employee_audit
+        (employee_id, seq, action, actor, request_id, occurred_at, changes, prev_hash, hash)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+
+	err = conn(ctx, r.db).QueryRowContext(ctx, query,
+		entry.EmployeeID,
+		entry.Seq,
+		entry.Action,
+		entry.Actor,
+		entry.RequestID,
+		entry.OccurredAt,
+		string(changes),
+		entry.PrevHash,
+		entry.Hash,
+	).Scan(&entry.ID)
+	if err != nil {
+		return mapError(err, "failed to append audit entry")
+	}
+
+	return nil
+}
+
+func (r *AuditRepo) Last(ctx context.Context, employeeID int) (*domain.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM employee_audit
+        WHERE employee_id = $1 ORDER BY seq DESC LIMIT 1`
+
+	entry, err := scanAuditEntry(conn(ctx, r.db).QueryRowContext(ctx, query, employeeID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, mapError(err, "failed to get last audit entry")
+	}
+
+	return entry, nil
+}
+
+func (r *AuditRepo) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM employee_audit
+        WHERE employee_id = $1 ORDER BY seq`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, employeeID)
+	if err != nil {
+		return nil, mapError(err, "failed to get audit entries")
+	}
+	defer rows.Close()
+
+	entries := []*domain.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return entries, nil
+}
+
+func scanAuditEntry(row rowScanner) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	var changes []byte
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.EmployeeID,
+		&entry.Seq,
+		&entry.Action,
+		&entry.Actor,
+		&entry.RequestID,
+		&entry.OccurredAt,
+		&changes,
+		&entry.PrevHash,
+		&entry.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+	}
+	entry.OccurredAt = entry.OccurredAt.UTC()
+
+	return &entry, nil
+}
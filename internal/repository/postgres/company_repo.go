@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type CompanyRepo struct {
+	db *sql.DB
+}
+
+func NewCompanyRepo(db *sql.DB) *CompanyRepo {
+	return &CompanyRepo{db: db}
+}
+
+func (r *CompanyRepo) Create(ctx context.Context, company *domain.Company) (int, error) {
+	var id int
+	query := `INSERT INTO companies (legal_name, tax_id, default_country, active)
+        VALUES ($1, $2, $3, $4) RETURNING id`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		company.LegalName,
+		company.TaxID,
+		company.DefaultCountry,
+		company.Active,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, mapError(err, "failed to create company")
+	}
+
+	return id, nil
+}
+
+func (r *CompanyRepo) GetByID(ctx context.Context, id int) (*domain.Company, error) {
+	query := "SELECT id, legal_name, tax_id, default_country, active FROM companies WHERE id = $1"
+
+	var company domain.Company
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&company.ID,
+		&company.LegalName,
+		&company.TaxID,
+		&company.DefaultCountry,
+		&company.Active,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NewNotFoundError("company")
+		}
+		return nil, mapError(err, "failed to get company")
+	}
+
+	return &company, nil
+}
+
+func (r *CompanyRepo) List(ctx context.Context) ([]*domain.Company, error) {
+	query := "SELECT id, legal_name, tax_id, default_country, active FROM companies ORDER BY id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, mapError(err, "failed to get companies")
+	}
+	defer rows.Close()
+
+	companies := []*domain.Company{}
+	for rows.Next() {
+		var company domain.Company
+		err := rows.Scan(
+			&company.ID,
+			&company.LegalName,
+			&company.TaxID,
+			&company.DefaultCountry,
+			&company.Active,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		companies = append(companies, &company)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return companies, nil
+}
+
+func (r *CompanyRepo) Update(ctx context.Context, company *domain.Company) error {
+	query := `UPDATE companies
+        SET legal_name = $1, tax_id = $2, default_country = $3, active = $4
+        WHERE id = $5`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		company.LegalName,
+		company.TaxID,
+		company.DefaultCountry,
+		company.Active,
+		company.ID,
+	)
+	if err != nil {
+		return mapError(err, "failed to update company")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("company")
+	}
+
+	return nil
+}
+
+func (r *CompanyRepo) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM companies WHERE id = $1", id)
+	if err != nil {
+		return mapError(err, "failed to delete company")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("company")
+	}
+
+	return nil
+}
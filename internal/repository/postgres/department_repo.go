@@ -1,11 +1,13 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
-	"github.com/lib/pq"
 )
 
 type DepartmentRepo struct {
@@ -16,45 +18,37 @@ func NewDepartmentRepo(db *sql.DB) *DepartmentRepo {
 	return &DepartmentRepo{db: db}
 }
 
-func (r *DepartmentRepo) GetOrCreate(dept *domain.Department) (int, error) {
+func (r *DepartmentRepo) GetOrCreate(ctx context.Context, dept *domain.Department) (int, bool, error) {
 	var id int
-	err := r.db.QueryRow(
+	err := conn(ctx, r.db).QueryRowContext(ctx,
 		"SELECT id FROM departments WHERE company_id = $1 AND name = $2",
 		dept.CompanyID, dept.Name,
 	).Scan(&id)
 
 	if err == nil {
-		return id, nil
+		return id, false, nil
 	}
 
 	if err != sql.ErrNoRows {
-		return 0, fmt.Errorf("failed to query department: %w", err)
+		return 0, false, mapError(err, "failed to query department")
 	}
 
-	err = r.db.QueryRow(
-		"INSERT INTO departments (company_id, name, phone) VALUES ($1, $2, $3) RETURNING id",
-		dept.CompanyID, dept.Name, dept.Phone,
+	err = conn(ctx, r.db).QueryRowContext(ctx,
+		"INSERT INTO departments (company_id, name, phone, parent_id) VALUES ($1, $2, $3, $4) RETURNING id",
+		dept.CompanyID, dept.Name, dept.Phone, dept.ParentID,
 	).Scan(&id)
 
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Constraint {
-			// case "departments_company_id_name_key":
-			// 	return 0, fmt.Errorf("department with this name already exists in this company")
-			case "departments_phone_key":
-				return 0, fmt.Errorf("department with this phone number already exists")
-			}
-		}
-		return 0, fmt.Errorf("failed to create department: %w", err)
+		return 0, false, mapError(err, "failed to create department")
 	}
 
-	return id, nil
+	return id, true, nil
 }
 
-func (r *DepartmentRepo) GetByID(id int) (*domain.Department, error) {
-	query := "SELECT id, company_id, name, phone FROM departments WHERE id = $1"
+func (r *DepartmentRepo) GetByID(ctx context.Context, id int) (*domain.Department, error) {
+	query := "SELECT id, company_id, name, phone, parent_id FROM departments WHERE id = $1"
 
-	row := r.db.QueryRow(query, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id)
 
 	var dept domain.Department
 	err := row.Scan(
@@ -62,14 +56,132 @@ func (r *DepartmentRepo) GetByID(id int) (*domain.Department, error) {
 		&dept.CompanyID,
 		&dept.Name,
 		&dept.Phone,
+		&dept.ParentID,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("department not found")
+			return nil, domain.NewNotFoundError("department")
 		}
-		return nil, fmt.Errorf("failed to get department: %w", err)
+		return nil, mapError(err, "failed to get department")
 	}
 
 	return &dept, nil
 }
+
+func (r *DepartmentRepo) ListByCompany(ctx context.Context, companyID int) ([]*domain.DepartmentSummary, error) {
+	query := `SELECT d.id, d.company_id, d.name, d.phone, d.parent_id, COUNT(e.id)
+        FROM departments d
+        LEFT JOIN employees e ON e.department_id = d.id AND e.deleted_at IS NULL
+        WHERE d.company_id = $1
+        GROUP BY d.id
+        ORDER BY d.name`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, companyID)
+	if err != nil {
+		return nil, mapError(err, "failed to get departments")
+	}
+	defer rows.Close()
+
+	departments := []*domain.DepartmentSummary{}
+	for rows.Next() {
+		var dept domain.DepartmentSummary
+		err := rows.Scan(
+			&dept.ID,
+			&dept.CompanyID,
+			&dept.Name,
+			&dept.Phone,
+			&dept.ParentID,
+			&dept.EmployeeCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan department: %w", err)
+		}
+		departments = append(departments, &dept)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return departments, nil
+}
+
+func (r *DepartmentRepo) Update(ctx context.Context, dept *domain.Department) error {
+	var updates []string
+	var args []interface{}
+	argID := 1
+
+	if dept.Name != "" {
+		updates = append(updates, "name = $"+strconv.Itoa(argID))
+		args = append(args, dept.Name)
+		argID++
+	}
+	if dept.Phone != "" {
+		updates = append(updates, "phone = $"+strconv.Itoa(argID))
+		args = append(args, dept.Phone)
+		argID++
+	}
+	// A parent ID of 0 moves the department to the top level.
+	if dept.ParentID != nil {
+		updates = append(updates, "parent_id = NULLIF($"+strconv.Itoa(argID)+", 0)")
+		args = append(args, *dept.ParentID)
+		argID++
+	}
+
+	args = append(args, dept.ID)
+
+	if len(updates) == 0 {
+		return domain.NewValidationError("", "no fields to update")
+	}
+
+	query := "UPDATE departments SET " + strings.Join(updates, ", ") + " WHERE id = $" + strconv.Itoa(argID)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapError(err, "failed to update department")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("department")
+	}
+
+	return nil
+}
+
+func (r *DepartmentRepo) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM departments WHERE id = $1", id)
+	if err != nil {
+		return mapError(err, "failed to delete department")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError("department")
+	}
+
+	return nil
+}
+
+// departmentHierarchyLockKey namespaces the per-company advisory locks taken
+// by LockHierarchy.
+const departmentHierarchyLockKey = 0x64657074
+
+// LockHierarchy takes the advisory lock of the company's department
+// hierarchy for the rest of the transaction. Callers must be in one: outside
+// of it the lock is released as soon as it is taken.
+func (r *DepartmentRepo) LockHierarchy(ctx context.Context, companyID int) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, departmentHierarchyLockKey, companyID); err != nil {
+		return mapError(err, "failed to lock department hierarchy")
+	}
+	return nil
+}
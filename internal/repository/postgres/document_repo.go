@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type DocumentRepo struct {
+	db *sql.DB
+}
+
+func NewDocumentRepo(db *sql.DB) *DocumentRepo {
+	return &DocumentRepo{db: db}
+}
+
+const documentColumns = `d.id, d.employee_id, d.type, d.number, d.issuing_country, d.issued_on, d.expires_on, d.is_primary`
+
+func (r *DocumentRepo) Create(ctx context.Context, doc *domain.IdentityDocument) (int, error) {
+	query := `INSERT INTO employee_documents (employee_id, type, number, issuing_country, issued_on, expires_on, is_primary)
+        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		doc.EmployeeID,
+		doc.Type,
+		doc.Number,
+		doc.IssuingCountry,
+		dateValue(doc.IssuedOn),
+		dateValue(doc.ExpiresOn),
+		doc.Primary,
+	).Scan(&id)
+	if err != nil {
+		return 0, mapError(err, "failed to create document")
+	}
+
+	return id, nil
+}
+
+func (r *DocumentRepo) GetByID(ctx context.Context, id int) (*domain.IdentityDocument, error) {
+	query := `SELECT ` + documentColumns + ` FROM employee_documents d WHERE d.id = $1`
+
+	doc, err := scanDocument(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.NewNotFoundError("document")
+	}
+	if err != nil {
+		return nil, mapError(err, "failed to get document")
+	}
+
+	return doc, nil
+}
+
+func (r *DocumentRepo) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.IdentityDocument, error) {
+	query := `SELECT ` + documentColumns + ` FROM employee_documents d WHERE d.employee_id = $1 ORDER BY d.id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, employeeID)
+	if err != nil {
+		return nil, mapError(err, "failed to get documents")
+	}
+	defer rows.Close()
+
+	docs := []*domain.IdentityDocument{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return docs, nil
+}
+
+func (r *DocumentRepo) Update(ctx context.Context, doc *domain.IdentityDocument) error {
+	query := `UPDATE employee_documents
+        SET type = $1, number = $2, issuing_country = $3, issued_on = $4, expires_on = $5, is_primary = $6
+        WHERE id = $7`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		doc.Type,
+		doc.Number,
+		doc.IssuingCountry,
+		dateValue(doc.IssuedOn),
+		dateValue(doc.ExpiresOn),
+		doc.Primary,
+		doc.ID,
+	)
+	if err != nil {
+		return mapError(err, "failed to update document")
+	}
+
+	return checkAffected(result, "document")
+}
+
+func (r *DocumentRepo) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM employee_documents WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err, "failed to delete document")
+	}
+
+	return checkAffected(result, "document")
+}
+
+func (r *DocumentRepo) ListExpiring(ctx context.Context, companyID int, from, to domain.Date) ([]*domain.ExpiringDocument, error) {
+	query := `SELECT ` + documentColumns + `, e.name, e.surname
+        FROM employee_documents d
+        JOIN employees e ON e.id = d.employee_id
+        WHERE e.company_id = $1 AND e.deleted_at IS NULL
+            AND d.expires_on BETWEEN $2 AND $3
+        ORDER BY d.expires_on, d.id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, companyID, from.String(), to.String())
+	if err != nil {
+		return nil, mapError(err, "failed to get expiring documents")
+	}
+	defer rows.Close()
+
+	docs := []*domain.ExpiringDocument{}
+	for rows.Next() {
+		var expiring domain.ExpiringDocument
+		doc, err := scanDocument(rows, &expiring.EmployeeName, &expiring.EmployeeSurname)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		expiring.IdentityDocument = *doc
+		docs = append(docs, &expiring)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return docs, nil
+}
+
+func scanDocument(row rowScanner, extra ...interface{}) (*domain.IdentityDocument, error) {
+	var doc domain.IdentityDocument
+	var issuedOn, expiresOn sql.NullTime
+
+	dest := []interface{}{
+		&doc.ID,
+		&doc.EmployeeID,
+		&doc.Type,
+		&doc.Number,
+		&doc.IssuingCountry,
+		&issuedOn,
+		&expiresOn,
+		&doc.Primary,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	doc.IssuedOn = nullDate(issuedOn)
+	doc.ExpiresOn = nullDate(expiresOn)
+	return &doc, nil
+}
+
+// dateValue passes a date to a DATE parameter as text, so the session time
+// zone cannot shift it to another day.
+func dateValue(d *domain.Date) interface{} {
+	if d == nil {
+		return nil
+	}
+	return d.String()
+}
+
+func nullDate(t sql.NullTime) *domain.Date {
+	if !t.Valid {
+		return nil
+	}
+	d := domain.NewDate(t.Time)
+	return &d
+}
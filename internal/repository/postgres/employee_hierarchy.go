@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// Reporting lines only run through live employees: a soft-deleted manager
+// ends the chain above them and hides everyone below them. GetManagerIDs,
+// which keeps cycles out, is the exception and walks through them. The
+// recursive queries carry the path walked so far and stop at an employee
+// already on it, so they terminate even if a cycle slipped past the service.
+
+// GetDirectReports returns the live employees whose manager is managerID, in
+// ID order.
+func (r *EmployeeRepo) GetDirectReports(ctx context.Context, managerID int) ([]*domain.Employee, error) {
+	query := `SELECT ` + employeeColumns + `
+        FROM employees e
+        LEFT JOIN departments d ON d.id = e.department_id
+        WHERE e.manager_id = $1 AND e.deleted_at IS NULL
+        ORDER BY e.id`
+
+	return r.queryEmployees(ctx, "failed to get direct reports", query, managerID)
+}
+
+// GetManagementChain returns the managers above the employee, nearest first.
+func (r *EmployeeRepo) GetManagementChain(ctx context.Context, id int) ([]*domain.Employee, error) {
+	query := `WITH RECURSIVE chain AS (
+            SELECT m.id, m.manager_id, 1 AS depth, ARRAY[e.id, m.id] AS path
+            FROM employees e
+            JOIN employees m ON m.id = e.manager_id AND m.deleted_at IS NULL
+            WHERE e.id = $1
+            UNION ALL
+            SELECT m.id, m.manager_id, c.depth + 1, c.path || m.id
+            FROM chain c
+            JOIN employees m ON m.id = c.manager_id AND m.deleted_at IS NULL
+            WHERE m.id <> ALL(c.path)
+        )
+        SELECT ` + employeeColumns + `
+        FROM chain c
+        JOIN employees e ON e.id = c.id
+        LEFT JOIN departments d ON d.id = e.department_id
+        ORDER BY c.depth`
+
+	return r.queryEmployees(ctx, "failed to get management chain", query, id)
+}
+
+// GetSubordinates returns every live employee below the manager, breadth
+// first: direct reports, then their reports, each level in ID order.
+func (r *EmployeeRepo) GetSubordinates(ctx context.Context, managerID int) ([]*domain.Subordinate, error) {
+	query := `WITH RECURSIVE tree AS (
+            SELECT e.id, 1 AS depth, ARRAY[$1::integer, e.id] AS path
+            FROM employees e
+            WHERE e.manager_id = $1 AND e.deleted_at IS NULL
+            UNION ALL
+            SELECT e.id, t.depth + 1, t.path || e.id
+            FROM tree t
+            JOIN employees e ON e.manager_id = t.id AND e.deleted_at IS NULL
+            WHERE e.id <> ALL(t.path)
+        )
+        SELECT ` + employeeColumns + `, t.depth
+        FROM tree t
+        JOIN employees e ON e.id = t.id
+        LEFT JOIN departments d ON d.id = e.department_id
+        ORDER BY t.depth, e.id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, managerID)
+	if err != nil {
+		return nil, mapError(err, "failed to get subordinates")
+	}
+	defer rows.Close()
+
+	subordinates := []*domain.Subordinate{}
+	for rows.Next() {
+		var sub domain.Subordinate
+		emp, err := scanEmployee(rows, &sub.Depth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subordinate: %w", err)
+		}
+		sub.Employee = emp
+		subordinates = append(subordinates, &sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return subordinates, nil
+}
+
+// reportingLinesLockKey namespaces the per-company advisory locks taken by
+// LockReportingLines.
+const reportingLinesLockKey = 0x6d677273
+
+// GetManagerIDs returns the IDs of the managers above the employee, nearest
+// first, deleted managers included. A cycle ends the list after the first
+// repeated ID.
+func (r *EmployeeRepo) GetManagerIDs(ctx context.Context, id int) ([]int, error) {
+	query := `WITH RECURSIVE chain AS (
+            SELECT e.manager_id AS id, 1 AS depth, ARRAY[e.id] AS path
+            FROM employees e
+            WHERE e.id = $1 AND e.manager_id IS NOT NULL
+            UNION ALL
+            SELECT m.manager_id, c.depth + 1, c.path || m.id
+            FROM chain c
+            JOIN employees m ON m.id = c.id
+            WHERE m.manager_id IS NOT NULL AND m.id <> ALL(c.path)
+        )
+        SELECT id FROM chain ORDER BY depth`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, mapError(err, "failed to get manager IDs")
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var managerID int
+		if err := rows.Scan(&managerID); err != nil {
+			return nil, fmt.Errorf("failed to scan manager ID: %w", err)
+		}
+		ids = append(ids, managerID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return ids, nil
+}
+
+// LockReportingLines takes the advisory lock of the company's reporting lines
+// for the rest of the transaction. Callers must be in one: outside of it the
+// lock is released as soon as it is taken.
+func (r *EmployeeRepo) LockReportingLines(ctx context.Context, companyID int) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, reportingLinesLockKey, companyID); err != nil {
+		return mapError(err, "failed to lock reporting lines")
+	}
+	return nil
+}
+
+func (r *EmployeeRepo) queryEmployees(ctx context.Context, msg, query string, args ...interface{}) ([]*domain.Employee, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err, msg)
+	}
+	defer rows.Close()
+
+	employees := []*domain.Employee{}
+	for rows.Next() {
+		emp, err := scanEmployee(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan employee: %w", err)
+		}
+		employees = append(employees, emp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return employees, nil
+}
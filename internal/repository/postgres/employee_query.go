@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+// employeeSortColumns maps API sort fields to SQL expressions. Nullable
+// columns are coalesced so that row-value comparisons in keyset conditions
+// never see NULL.
+var employeeSortColumns = map[string]sortColumn{
+	"id":             {"e.id", "int"},
+	"name":           {"e.name", "text"},
+	"surname":        {"e.surname", "text"},
+	"phone":          {"e.phone", "text"},
+	"companyId":      {"e.company_id", "int"},
+	"departmentId":   {"COALESCE(e.department_id, 0)", "int"},
+	"passportType":   {"COALESCE(e.passport_type, '')", "text"},
+	"passportNumber": {"e.passport_number", "text"},
+}
+
+// employeeQuery accumulates WHERE conditions together with their positional
+// arguments. User input only ever travels through args. Conditions refer to
+// the employees table as e, since listings join departments as d.
+type employeeQuery struct {
+	conds []string
+	args  []interface{}
+}
+
+func newEmployeeQuery() *employeeQuery {
+	return &employeeQuery{}
+}
+
+// arg registers a value and returns its placeholder.
+func (q *employeeQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// where adds a condition; every %s in cond is replaced by the placeholder of
+// the matching value.
+func (q *employeeQuery) where(cond string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, v := range values {
+		placeholders[i] = q.arg(v)
+	}
+	q.conds = append(q.conds, fmt.Sprintf(cond, placeholders...))
+}
+
+func (q *employeeQuery) conditions() string {
+	if len(q.conds) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(q.conds, " AND ")
+}
+
+func (q *employeeQuery) applyFilter(f domain.EmployeeFilter) {
+	if f.NamePrefix != "" {
+		q.where(`e.name ILIKE %s`, escapeLike(f.NamePrefix)+"%")
+	}
+	if f.SurnamePrefix != "" {
+		q.where(`e.surname ILIKE %s`, escapeLike(f.SurnamePrefix)+"%")
+	}
+	if f.Phone != "" {
+		q.where("e.phone = %s", f.Phone)
+	}
+	if f.PassportType != "" {
+		q.where("e.passport_type = %s", f.PassportType)
+	}
+	if f.DepartmentID != nil {
+		q.where("e.department_id = %s", *f.DepartmentID)
+	}
+	if f.NoDepartment {
+		q.where("e.department_id IS NULL")
+	}
+	if !f.IncludeDeleted {
+		q.where("e.deleted_at IS NULL")
+	}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
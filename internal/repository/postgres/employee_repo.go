@@ -1,13 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
-	"github.com/lib/pq"
 )
 
 type EmployeeRepo struct {
@@ -18,125 +17,96 @@ func NewEmployeeRepo(db *sql.DB) *EmployeeRepo {
 	return &EmployeeRepo{db: db}
 }
 
-func (r *EmployeeRepo) Create(emp *domain.Employee) (int, error) {
+func (r *EmployeeRepo) Create(ctx context.Context, emp *domain.Employee) (int, error) {
 	var id int
 	query := `INSERT INTO employees 
-        (name, surname, phone, company_id, department_id, passport_type, passport_number)
-        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+        (name, surname, phone, company_id, department_id, passport_type, passport_number, manager_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, version`
 
-	err := r.db.QueryRow(query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		emp.Name,
 		emp.Surname,
 		emp.Phone,
 		emp.CompanyID,
 		emp.DepartmentID,
-		emp.PassportType,
+		sql.NullString{String: emp.PassportType, Valid: emp.PassportType != ""},
 		emp.PassportNumber,
-	).Scan(&id)
+		emp.ManagerID,
+	).Scan(&id, &emp.Version)
 
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Constraint {
-			case "employees_phone_key":
-				return 0, fmt.Errorf("employee with this phone number already exists")
-			case "employees_passport_number_key":
-				return 0, fmt.Errorf("employee with this passport number already exists")
-			}
-		}
-		return 0, fmt.Errorf("failed to create employee: %w", err)
+		return 0, mapError(err, "failed to create employee")
 	}
 
 	return id, nil
 }
 
-func (r *EmployeeRepo) GetByID(id int) (*domain.Employee, error) {
-	query := `SELECT id, name, surname, phone, company_id, department_id, 
-        passport_type, passport_number FROM employees WHERE id = $1`
-
-	row := r.db.QueryRow(query, id)
-
-	var emp domain.Employee
-	err := row.Scan(
-		&emp.ID,
-		&emp.Name,
-		&emp.Surname,
-		&emp.Phone,
-		&emp.CompanyID,
-		&emp.DepartmentID,
-		&emp.PassportType,
-		&emp.PassportNumber,
-	)
+func (r *EmployeeRepo) GetByID(ctx context.Context, id int) (*domain.Employee, error) {
+	query := `SELECT ` + employeeColumns + `
+        FROM employees e
+        LEFT JOIN departments d ON d.id = e.department_id
+        WHERE e.id = $1 AND e.deleted_at IS NULL`
 
+	emp, err := scanEmployee(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("employee not found")
+			return nil, domain.NewNotFoundError("employee")
 		}
-		return nil, fmt.Errorf("failed to get employee: %w", err)
+		return nil, mapError(err, "failed to get employee")
 	}
 
-	return &emp, nil
+	return emp, nil
 }
 
-func (r *EmployeeRepo) Update(emp *domain.Employee) error {
-	var updates []string
-	var args []interface{}
-	argID := 1
-
-	if emp.Name != "" {
-		updates = append(updates, "name = $"+strconv.Itoa(argID))
-		args = append(args, emp.Name)
-		argID++
-	}
-	if emp.Surname != "" {
-		updates = append(updates, "surname = $"+strconv.Itoa(argID))
-		args = append(args, emp.Surname)
-		argID++
-	}
-	if emp.Phone != "" {
-		updates = append(updates, "phone = $"+strconv.Itoa(argID))
-		args = append(args, emp.Phone)
-		argID++
-	}
-	if emp.CompanyID != 0 {
-		updates = append(updates, "company_id = $"+strconv.Itoa(argID))
-		args = append(args, emp.CompanyID)
-		argID++
+// Update writes every field of emp, so empty optional fields are cleared. A
+// non-zero emp.Version must match the stored one; on success it is set to
+// the new version.
+func (r *EmployeeRepo) Update(ctx context.Context, emp *domain.Employee) error {
+	query := `UPDATE employees SET
+        name = $1, surname = $2, phone = $3, company_id = $4, department_id = $5,
+        passport_type = $6, passport_number = $7, manager_id = $8, version = version + 1
+        WHERE id = $9 AND deleted_at IS NULL`
+	args := []interface{}{
+		emp.Name,
+		emp.Surname,
+		emp.Phone,
+		emp.CompanyID,
+		emp.DepartmentID,
+		sql.NullString{String: emp.PassportType, Valid: emp.PassportType != ""},
+		emp.PassportNumber,
+		emp.ManagerID,
+		emp.ID,
 	}
-	if emp.DepartmentID != nil {
-		updates = append(updates, "department_id = $"+strconv.Itoa(argID))
-		args = append(args, emp.DepartmentID)
-		argID++
+	if emp.Version != 0 {
+		query += " AND version = $10"
+		args = append(args, emp.Version)
 	}
-	if emp.PassportType != "" {
-		updates = append(updates, "passport_type = $"+strconv.Itoa(argID))
-		args = append(args, emp.PassportType)
-		argID++
+	query += " RETURNING version"
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&emp.Version)
+	if err == sql.ErrNoRows {
+		return r.missingOrStale(ctx, emp.ID, emp.Version)
 	}
-	if emp.PassportNumber != "" {
-		updates = append(updates, "passport_number = $"+strconv.Itoa(argID))
-		args = append(args, emp.PassportNumber)
-		argID++
+	if err != nil {
+		return mapError(err, "failed to update employee")
 	}
 
-	args = append(args, emp.ID)
+	return nil
+}
 
-	if len(updates) == 0 {
-		return fmt.Errorf("no fields to update")
+// Delete soft-deletes the employee; a non-zero version must match the stored
+// one. The row is kept and can be brought back with Restore.
+func (r *EmployeeRepo) Delete(ctx context.Context, id, version int) error {
+	query := "UPDATE employees SET deleted_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL"
+	args := []interface{}{id}
+	if version != 0 {
+		query += " AND version = $2"
+		args = append(args, version)
 	}
 
-	query := "UPDATE employees SET " + strings.Join(updates, ", ") + " WHERE id = $" + strconv.Itoa(argID)
-
-	result, err := r.db.Exec(query, args...)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Constraint {
-			case "employees_phone_key":
-				return fmt.Errorf("employee with this phone number already exists")
-			case "employees_passport_number_key":
-				return fmt.Errorf("employee with this passport number already exists")
-			}
-		}
-		return fmt.Errorf("failed to update employee: %w", err)
+		return mapError(err, "failed to delete employee")
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -145,16 +115,21 @@ func (r *EmployeeRepo) Update(emp *domain.Employee) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("employee not found")
+		return r.missingOrStale(ctx, id, version)
 	}
 
 	return nil
 }
 
-func (r *EmployeeRepo) Delete(id int) error {
-	result, err := r.db.Exec("DELETE FROM employees WHERE id = $1", id)
+// Restore brings back a soft-deleted employee and, by trigger, their
+// documents. It fails with a conflict if the employee is not deleted or its
+// phone or one of its documents has been taken by a live employee in the
+// meantime.
+func (r *EmployeeRepo) Restore(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE employees SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL", id)
 	if err != nil {
-		return fmt.Errorf("failed to delete employee: %w", err)
+		return mapError(err, "failed to restore employee")
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -163,91 +138,259 @@ func (r *EmployeeRepo) Delete(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("employee not found")
+		var exists bool
+		err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)", id).Scan(&exists)
+		if err != nil {
+			return mapError(err, "failed to check employee")
+		}
+		if exists {
+			return fmt.Errorf("%w: employee is not deleted", domain.ErrConflict)
+		}
+		return domain.NewNotFoundError("employee")
 	}
 
 	return nil
 }
 
-func (r *EmployeeRepo) GetByCompany(companyID int) ([]*domain.Employee, error) {
-	query := `SELECT id, name, surname, phone, company_id, department_id, 
-        passport_type, passport_number FROM employees WHERE company_id = $1`
+// missingOrStale explains why a versioned write matched no rows.
+func (r *EmployeeRepo) missingOrStale(ctx context.Context, id, version int) error {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1 AND deleted_at IS NULL)", id).Scan(&exists)
+	if err != nil {
+		return mapError(err, "failed to check employee")
+	}
+
+	if exists && version != 0 {
+		return domain.NewVersionConflictError("employee", version)
+	}
+	return domain.NewNotFoundError("employee")
+}
+
+func (r *EmployeeRepo) GetByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	q := newEmployeeQuery()
+	q.where("e.company_id = %s", companyID)
+	return r.list(ctx, q, filter, page)
+}
+
+func (r *EmployeeRepo) GetByDepartment(ctx context.Context, companyID, deptId int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	q := newEmployeeQuery()
+	q.where("e.company_id = %s", companyID)
+
+	// departments is the department alone or, with IncludeSubdepartments,
+	// the department and every one below it. UNION stops the walk at
+	// departments already seen.
+	departments := "%s"
+	if filter.IncludeSubdepartments {
+		departments = `WITH RECURSIVE sub AS (
+                SELECT id FROM departments WHERE id = %s
+                UNION
+                SELECT d.id FROM departments d JOIN sub ON d.parent_id = sub.id
+            ) SELECT id FROM sub`
+	}
+
+	if filter.AsOf != nil {
+		q.where(`EXISTS (SELECT 1 FROM employee_assignments a
+            WHERE a.employee_id = e.id AND a.department_id IN (`+departments+`)
+                AND a.start_date <= %s AND (a.end_date IS NULL OR a.end_date > %s))`,
+			deptId, filter.AsOf.String(), filter.AsOf.String())
+	} else {
+		q.where("e.department_id IN ("+departments+")", deptId)
+	}
+	return r.list(ctx, q, filter, page)
+}
+
+// exportBatchSize is how many rows StreamByCompany fetches from its cursor
+// at a time.
+const exportBatchSize = 500
+
+// StreamByCompany passes the employees of the company matching filter to fn
+// in ID order. Rows are read through a server-side cursor in batches of
+// exportBatchSize, so memory use does not depend on the size of the company.
+// The cursor needs a transaction of its own; it is read-only and sees one
+// snapshot of the data.
+func (r *EmployeeRepo) StreamByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	q := newEmployeeQuery()
+	q.where("e.company_id = %s", companyID)
+	q.applyFilter(filter)
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return mapError(err, "failed to begin export")
+	}
+	defer tx.Rollback()
 
-	rows, err := r.db.Query(query, companyID)
+	_, err = tx.ExecContext(ctx, `DECLARE employee_export NO SCROLL CURSOR FOR
+        SELECT `+employeeColumns+`
+        FROM employees e
+        LEFT JOIN departments d ON d.id = e.department_id
+        WHERE `+q.conditions()+`
+        ORDER BY e.id`, q.args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get employees: %w", err)
+		return mapError(err, "failed to open export cursor")
+	}
+
+	fetch := "FETCH " + strconv.Itoa(exportBatchSize) + " FROM employee_export"
+	for {
+		n, err := r.fetchBatch(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if n < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *EmployeeRepo) fetchBatch(ctx context.Context, tx *sql.Tx, fetch string, fn func(*domain.Employee) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, mapError(err, "failed to fetch employees")
 	}
 	defer rows.Close()
 
-	var employees []*domain.Employee
+	n := 0
 	for rows.Next() {
-		var emp domain.Employee
-		err := rows.Scan(
-			&emp.ID,
-			&emp.Name,
-			&emp.Surname,
-			&emp.Phone,
-			&emp.CompanyID,
-			&emp.DepartmentID,
-			&emp.PassportType,
-			&emp.PassportNumber,
-		)
+		emp, err := scanEmployee(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan employee: %w", err)
+			return n, fmt.Errorf("failed to scan employee: %w", err)
+		}
+		n++
+		if err := fn(emp); err != nil {
+			return n, err
 		}
-		employees = append(employees, &emp)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+		return n, mapError(err, "rows error")
+	}
+
+	return n, nil
+}
+
+// list returns one keyset page of the employees matching q and filter. One
+// extra row is fetched to find out whether another page follows.
+func (r *EmployeeRepo) list(ctx context.Context, q *employeeQuery, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	sortCol, ok := employeeSortColumns[page.SortBy]
+	if !ok {
+		return nil, domain.NewValidationError("sort", "unsupported sort field "+page.SortBy)
 	}
 
-	if len(employees) == 0 {
-		return nil, fmt.Errorf("employees not found for company id %d", companyID)
+	cursor, err := domain.DecodeCursorFor(page)
+	if err != nil {
+		return nil, err
 	}
 
-	return employees, nil
-}
+	q.applyFilter(filter)
+
+	result := &domain.EmployeePage{Items: []*domain.Employee{}}
+
+	if page.IncludeTotal {
+		var total int
+		err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM employees e WHERE "+q.conditions(), q.args...).Scan(&total)
+		if err != nil {
+			return nil, mapError(err, "failed to count employees")
+		}
+		result.Total = &total
+	}
+
+	op, dir := ">", "ASC"
+	if page.SortDesc {
+		op, dir = "<", "DESC"
+	}
+	if page.Cursor != "" {
+		if page.SortBy == "id" {
+			q.where("e.id "+op+" %s", cursor.ID)
+		} else {
+			q.where("("+sortCol.expr+", e.id) "+op+" (%s::"+sortCol.cast+", %s)", cursor.Value, cursor.ID)
+		}
+	}
 
-func (r *EmployeeRepo) GetByDepartment(companyID, deptId int) ([]*domain.Employee, error) {
-	query := `SELECT e.id, e.name, e.surname, e.phone, e.company_id, 
-        e.department_id, e.passport_type, e.passport_number
+	query := `SELECT ` + employeeColumns + `
         FROM employees e
-        JOIN departments d ON e.department_id = d.id
-        WHERE e.company_id = $1 AND d.id = $2`
+        LEFT JOIN departments d ON d.id = e.department_id
+        WHERE ` + q.conditions() + `
+        ORDER BY ` + sortCol.expr + ` ` + dir + `, e.id ` + dir + `
+        LIMIT ` + q.arg(page.Limit+1)
 
-	rows, err := r.db.Query(query, companyID, deptId)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, q.args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get employees: %w", err)
+		return nil, mapError(err, "failed to get employees")
 	}
 	defer rows.Close()
 
-	var employees []*domain.Employee
 	for rows.Next() {
-		var emp domain.Employee
-		err := rows.Scan(
-			&emp.ID,
-			&emp.Name,
-			&emp.Surname,
-			&emp.Phone,
-			&emp.CompanyID,
-			&emp.DepartmentID,
-			&emp.PassportType,
-			&emp.PassportNumber,
-		)
+		emp, err := scanEmployee(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan employee: %w", err)
 		}
-		employees = append(employees, &emp)
+		result.Items = append(result.Items, emp)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+		return nil, mapError(err, "rows error")
+	}
+
+	if len(result.Items) > page.Limit {
+		result.Items = result.Items[:page.Limit]
+		last := result.Items[len(result.Items)-1]
+		result.NextCursor = domain.EncodeCursor(domain.Cursor{
+			Sort:  page.SortBy,
+			Desc:  page.SortDesc,
+			Value: domain.EmployeeSortKey(last, page.SortBy),
+			ID:    last.ID,
+		})
+	}
+
+	return result, nil
+}
+
+// employeeColumns selects an employee together with its department, joined as
+// LEFT JOIN departments d, in the order scanEmployee expects.
+const employeeColumns = `e.id, e.name, e.surname, e.phone, e.company_id, e.department_id,
+        COALESCE(e.passport_type, ''), e.passport_number, e.manager_id, e.version, e.deleted_at,
+        d.id, d.company_id, d.name, d.phone, d.parent_id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEmployee(row rowScanner, extra ...interface{}) (*domain.Employee, error) {
+	var emp domain.Employee
+	var deptID, deptCompanyID sql.NullInt64
+	var deptName, deptPhone sql.NullString
+	var deptParentID *int
+
+	dest := []interface{}{
+		&emp.ID,
+		&emp.Name,
+		&emp.Surname,
+		&emp.Phone,
+		&emp.CompanyID,
+		&emp.DepartmentID,
+		&emp.PassportType,
+		&emp.PassportNumber,
+		&emp.ManagerID,
+		&emp.Version,
+		&emp.DeletedAt,
+		&deptID,
+		&deptCompanyID,
+		&deptName,
+		&deptPhone,
+		&deptParentID,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
 	}
 
-	if len(employees) == 0 {
-		return nil, fmt.Errorf("employees not found for company id %d and department id %d", companyID, deptId)
+	if deptID.Valid {
+		emp.Department = &domain.Department{
+			ID:        int(deptID.Int64),
+			CompanyID: int(deptCompanyID.Int64),
+			Name:      deptName.String,
+			Phone:     deptPhone.String,
+			ParentID:  deptParentID,
+		}
 	}
 
-	return employees, nil
+	return &emp, nil
 }
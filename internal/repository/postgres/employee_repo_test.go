@@ -0,0 +1,137 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/migrations"
+	"github.com/Hexes-rgb/employee-service/internal/repository/postgres"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The Postgres tests run against a scratch database, see "make
+// test-postgres". They are skipped unless TEST_DATABASE_URL is set.
+
+// statements counts the statements sent through the "postgres-counting"
+// driver.
+var statements atomic.Int64
+
+var registerOnce sync.Once
+
+type countingDriver struct{}
+
+func (countingDriver) Open(dsn string) (driver.Conn, error) {
+	c, err := pq.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{c}, nil
+}
+
+// countingConn counts every query and exec; pq implements the context
+// variants, so database/sql never falls back to prepared statements.
+type countingConn struct {
+	driver.Conn
+}
+
+func (c countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	statements.Add(1)
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	statements.Add(1)
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c countingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	registerOnce.Do(func() { sql.Register("postgres-counting", countingDriver{}) })
+	db, err := sql.Open("postgres-counting", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.Background()))
+	return db
+}
+
+func TestEmployeeRepo_ListingsQueryOncePerPage(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	// Unique per run, so the test can share a database with earlier runs.
+	run := time.Now().UnixNano() % 1e9
+
+	companyID, err := postgres.NewCompanyRepo(db).Create(ctx, &domain.Company{
+		LegalName: "Acme LLC", TaxID: fmt.Sprintf("T%d", run), DefaultCountry: "RU", Active: true,
+	})
+	require.NoError(t, err)
+
+	depts := postgres.NewDepartmentRepo(db)
+	var deptIDs []int
+	for i := 0; i < 5; i++ {
+		id, _, err := depts.GetOrCreate(ctx, &domain.Department{
+			CompanyID: companyID, Name: fmt.Sprintf("Department %d", i), Phone: fmt.Sprintf("+%d%d", run, i),
+		})
+		require.NoError(t, err)
+		deptIDs = append(deptIDs, id)
+	}
+
+	repo := postgres.NewEmployeeRepo(db)
+	for i := 0; i < 50; i++ {
+		_, err := repo.Create(ctx, &domain.Employee{
+			Name: "John", Surname: fmt.Sprintf("Doe %02d", i), CompanyID: companyID, DepartmentID: &deptIDs[i%len(deptIDs)],
+			Phone: fmt.Sprintf("+%d%02d", run, i), PassportNumber: fmt.Sprintf("P%d%02d", run, i),
+		})
+		require.NoError(t, err)
+	}
+
+	pages := []struct {
+		name string
+		list func(page domain.PageRequest) (*domain.EmployeePage, error)
+	}{
+		{"company", func(page domain.PageRequest) (*domain.EmployeePage, error) {
+			return repo.GetByCompany(ctx, companyID, domain.EmployeeFilter{}, page)
+		}},
+		{"department", func(page domain.PageRequest) (*domain.EmployeePage, error) {
+			return repo.GetByDepartment(ctx, companyID, deptIDs[0], domain.EmployeeFilter{}, page)
+		}},
+	}
+	for _, p := range pages {
+		t.Run(p.name, func(t *testing.T) {
+			for _, limit := range []int{5, 40} {
+				statements.Store(0)
+				page, err := p.list(domain.PageRequest{Limit: limit, SortBy: "surname"})
+				require.NoError(t, err)
+				require.NotEmpty(t, page.Items)
+				for _, emp := range page.Items {
+					require.NotNil(t, emp.Department, "department joined")
+					assert.Equal(t, *emp.DepartmentID, emp.Department.ID)
+				}
+				assert.Equal(t, int64(1), statements.Load(), "one query for %d employees", len(page.Items))
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// Search ranks employees of a company by full-text match over the trigger
+// maintained search_vector, falling back to trigram word similarity so that
+// misspelt names are still found. Variants are ORed together. Matches are
+// highlighted by domain.HighlightSearch.
+func (r *EmployeeRepo) Search(ctx context.Context, companyID int, query domain.SearchQuery) ([]*domain.SearchResult, error) {
+	q := newEmployeeQuery()
+	q.where("e.company_id = %s", companyID)
+	q.where("e.deleted_at IS NULL")
+
+	var tsParts, similarities, fuzzy []string
+	for _, variant := range query.Variants {
+		words := strings.Fields(variant)
+		for i, w := range words {
+			words[i] = w + ":*"
+		}
+		tsParts = append(tsParts, "("+strings.Join(words, " & ")+")")
+
+		ph := q.arg(variant)
+		similarities = append(similarities, "word_similarity("+ph+", e.search_text)")
+		fuzzy = append(fuzzy, ph+" <% e.search_text")
+	}
+
+	tsQuery := q.arg(strings.Join(tsParts, " | "))
+	// Appended directly: the <% operator must not go through fmt in where.
+	q.conds = append(q.conds, "(e.search_vector @@ sq.tsq OR "+strings.Join(fuzzy, " OR ")+")")
+
+	sqlQuery := `WITH sq AS (SELECT to_tsquery('simple', ` + tsQuery + `) AS tsq)
+        SELECT ` + employeeColumns + `,
+            ts_rank(e.search_vector, sq.tsq) + GREATEST(` + strings.Join(similarities, ", ") + `) AS rank,
+            concat_ws(' ', e.surname, e.name, e.phone, d.name) AS search_text
+        FROM employees e
+        CROSS JOIN sq
+        LEFT JOIN departments d ON d.id = e.department_id
+        WHERE ` + q.conditions() + `
+        ORDER BY rank DESC, e.id
+        LIMIT ` + q.arg(query.Limit)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, sqlQuery, q.args...)
+	if err != nil {
+		return nil, mapError(err, "failed to search employees")
+	}
+	defer rows.Close()
+
+	results := []*domain.SearchResult{}
+	for rows.Next() {
+		var res domain.SearchResult
+		var text string
+		emp, err := scanEmployee(rows, &res.Rank, &text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		res.Employee = emp
+		// Highlighted here rather than with ts_headline, which passes HTML in
+		// names through unescaped and marks nothing in trigram-only matches.
+		res.Highlight = domain.HighlightSearch(text, query)
+		results = append(results, &res)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return results, nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/lib/pq"
+)
+
+type conflictField struct {
+	entity string
+	field  string
+}
+
+var uniqueConstraints = map[string]conflictField{
+	"employees_phone_live_key":                {"employee", "phone"},
+	"departments_phone_key":                   {"department", "phone"},
+	"departments_company_id_name_key":         {"department", "name"},
+	"companies_tax_id_key":                    {"company", "taxId"},
+	"employee_documents_type_number_live_key": {"document", "number"},
+	"employee_documents_primary_key":          {"document", "primary"},
+}
+
+// foreignKey names the referencing field of a foreign key constraint.
+type foreignKey struct {
+	entity     string
+	field      string
+	referenced string
+}
+
+var foreignKeys = map[string]foreignKey{
+	"employees_company_id_fkey":               {"employee", "companyId", "company"},
+	"employees_department_id_fkey":            {"employee", "departmentId", "department"},
+	"employees_manager_id_fkey":               {"employee", "managerId", "employee"},
+	"departments_company_id_fkey":             {"department", "companyId", "company"},
+	"departments_parent_id_fkey":              {"department", "parentId", "department"},
+	"employee_audit_employee_id_fkey":         {"audit entry", "employeeId", "employee"},
+	"employee_documents_employee_id_fkey":     {"document", "employeeId", "employee"},
+	"employee_assignments_employee_id_fkey":   {"assignment", "employeeId", "employee"},
+	"employee_assignments_department_id_fkey": {"assignment", "departmentId", "department"},
+	"webhook_subscriptions_company_id_fkey":   {"webhook subscription", "companyId", "company"},
+	"webhook_deliveries_subscription_id_fkey": {"webhook delivery", "subscriptionId", "webhook subscription"},
+}
+
+// foreignKeyMessage describes a foreign key violation without the key values
+// of pq.Error.Detail, which must not reach clients.
+func foreignKeyMessage(pqErr *pq.Error) string {
+	fk, ok := foreignKeys[pqErr.Constraint]
+	if !ok {
+		return "foreign key violation"
+	}
+	if strings.HasPrefix(pqErr.Message, "update or delete") {
+		return fmt.Sprintf("%s is still referenced by %s %s", fk.referenced, fk.entity, fk.field)
+	}
+	return fmt.Sprintf("%s %s refers to a missing %s", fk.entity, fk.field, fk.referenced)
+}
+
+// mapError translates driver errors into domain errors so that callers can
+// rely on errors.Is instead of matching messages. Errors it does not
+// recognise are wrapped with msg and returned as is.
+func mapError(err error, msg string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			if c, ok := uniqueConstraints[pqErr.Constraint]; ok {
+				return domain.NewConflictError(c.entity, c.field)
+			}
+		case "23503":
+			return fmt.Errorf("%s: %w: %s", msg, domain.ErrConflict, foreignKeyMessage(pqErr))
+		}
+		switch pqErr.Code.Class() {
+		case "08", "53", "57":
+			return domain.NewUnavailableError(fmt.Errorf("%s: %w", msg, err))
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netErr) {
+		return domain.NewUnavailableError(fmt.Errorf("%s: %w", msg, err))
+	}
+
+	return fmt.Errorf("%s: %w", msg, err)
+}
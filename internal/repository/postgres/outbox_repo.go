@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/lib/pq"
+)
+
+// outboxLockKey is the advisory lock held while relaying, so that only one
+// instance publishes at a time and events leave in order.
+const outboxLockKey = 0x6f7574626f78
+
+// outboxRetention is how long published events are kept before they are
+// deleted.
+const outboxRetention = 7 * 24 * time.Hour
+
+type OutboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+func (r *OutboxRepo) Add(ctx context.Context, event *domain.Event) error {
+	query := `INSERT INTO outbox
+        (event_type, aggregate_type, aggregate_id, company_id, payload, occurred_at)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		event.Type,
+		event.AggregateType,
+		event.AggregateID,
+		event.CompanyID,
+		string(event.Payload),
+		event.OccurredAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return mapError(err, "failed to add outbox event")
+	}
+
+	return nil
+}
+
+// ProcessPending passes up to limit unpublished events to fn, oldest first,
+// and marks the ones fn reports as published. Everything runs in one
+// transaction holding outboxLockKey; if another instance holds it,
+// ProcessPending returns without calling fn. If the commit fails after fn
+// published events they are published again later, which at-least-once
+// delivery allows.
+func (r *OutboxRepo) ProcessPending(ctx context.Context, limit int, fn func([]*domain.Event) []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(err, "failed to begin outbox transaction")
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+		return mapError(err, "failed to lock outbox")
+	}
+	if !locked {
+		return nil
+	}
+
+	events, err := pendingEvents(ctx, tx, limit)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	published := fn(events)
+	if len(published) > 0 {
+		_, err = tx.ExecContext(ctx, `UPDATE outbox SET published_at = now() WHERE id = ANY($1)`, pq.Array(published))
+		if err != nil {
+			return mapError(err, "failed to mark outbox events published")
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM outbox WHERE published_at < $1`, time.Now().Add(-outboxRetention))
+	if err != nil {
+		return mapError(err, "failed to delete old outbox events")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapError(err, "failed to commit outbox transaction")
+	}
+	return nil
+}
+
+func pendingEvents(ctx context.Context, tx *sql.Tx, limit int) ([]*domain.Event, error) {
+	query := `SELECT id, event_type, aggregate_type, aggregate_id, company_id, payload, occurred_at
+        FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT $1`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, mapError(err, "failed to get pending outbox events")
+	}
+	defer rows.Close()
+
+	events := []*domain.Event{}
+	for rows.Next() {
+		var e domain.Event
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.AggregateType, &e.AggregateID, &e.CompanyID, &payload, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		e.Payload = payload
+		e.OccurredAt = e.OccurredAt.UTC()
+		events = append(events, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return events, nil
+}
@@ -0,0 +1,311 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/lib/pq"
+)
+
+type WebhookRepo struct {
+	db *sql.DB
+}
+
+func NewWebhookRepo(db *sql.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+const webhookColumns = `id, company_id, url, secret, event_types, active, created_at`
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+        next_attempt_at, last_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (int, error) {
+	query := `INSERT INTO webhook_subscriptions (company_id, url, secret, event_types, active, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	var id int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		sub.CompanyID,
+		sub.URL,
+		sub.Secret,
+		pq.Array(eventTypeStrings(sub.EventTypes)),
+		sub.Active,
+		sub.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, mapError(err, "failed to create webhook subscription")
+	}
+
+	return id, nil
+}
+
+func (r *WebhookRepo) GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	sub, err := scanWebhook(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.NewNotFoundError("webhook subscription")
+	}
+	if err != nil {
+		return nil, mapError(err, "failed to get webhook subscription")
+	}
+
+	return sub, nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context, companyID int) ([]*domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions WHERE company_id = $1 ORDER BY id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, companyID)
+	if err != nil {
+		return nil, mapError(err, "failed to get webhook subscriptions")
+	}
+	defer rows.Close()
+
+	subs := []*domain.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return subs, nil
+}
+
+func (r *WebhookRepo) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	query := `UPDATE webhook_subscriptions
+        SET url = $1, secret = $2, event_types = $3, active = $4
+        WHERE id = $5`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		sub.URL,
+		sub.Secret,
+		pq.Array(eventTypeStrings(sub.EventTypes)),
+		sub.Active,
+		sub.ID,
+	)
+	if err != nil {
+		return mapError(err, "failed to update webhook subscription")
+	}
+
+	return checkAffected(result, "webhook subscription")
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err, "failed to delete webhook subscription")
+	}
+
+	return checkAffected(result, "webhook subscription")
+}
+
+func (r *WebhookRepo) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries
+        (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		string(delivery.Payload),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return mapError(err, "failed to add webhook delivery")
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	delivery, err := scanDelivery(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.NewNotFoundError("webhook delivery")
+	}
+	if err != nil {
+		return nil, mapError(err, "failed to get webhook delivery")
+	}
+
+	return delivery, nil
+}
+
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, subscriptionID int, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+        WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY id DESC LIMIT $3`
+
+	return r.queryDeliveries(ctx, conn(ctx, r.db), query, subscriptionID, string(status), limit)
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+        SET status = $1, attempts = $2, next_attempt_at = $3, last_attempt_at = $4,
+            last_status_code = $5, last_error = $6, delivered_at = $7
+        WHERE id = $8`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return mapError(err, "failed to update webhook delivery")
+	}
+
+	return checkAffected(result, "webhook delivery")
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries due at now,
+// oldest first, and moves their next attempt lease ahead so that neither
+// this nor another instance claims them again while they are being sent.
+func (r *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries SET next_attempt_at = $2
+        WHERE id IN (
+            SELECT id FROM webhook_deliveries
+            WHERE status = 'pending' AND next_attempt_at <= $1
+            ORDER BY next_attempt_at, id
+            LIMIT $3
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + deliveryColumns
+
+	deliveries, err := r.queryDeliveries(ctx, conn(ctx, r.db), query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) queryDeliveries(ctx context.Context, q querier, query string, args ...interface{}) ([]*domain.WebhookDelivery, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err, "failed to get webhook deliveries")
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, mapError(err, "rows error")
+	}
+
+	return deliveries, nil
+}
+
+func checkAffected(result sql.Result, entity string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.NewNotFoundError(entity)
+	}
+	return nil
+}
+
+func scanWebhook(row rowScanner) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	var eventTypes []string
+
+	err := row.Scan(
+		&sub.ID,
+		&sub.CompanyID,
+		&sub.URL,
+		&sub.Secret,
+		pq.Array(&eventTypes),
+		&sub.Active,
+		&sub.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.EventTypes = make([]domain.EventType, len(eventTypes))
+	for i, t := range eventTypes {
+		sub.EventTypes[i] = domain.EventType(t)
+	}
+	sub.CreatedAt = sub.CreatedAt.UTC()
+
+	return &sub, nil
+}
+
+func scanDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var payload []byte
+	var nextAttemptAt, lastAttemptAt, deliveredAt sql.NullTime
+
+	err := row.Scan(
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&nextAttemptAt,
+		&lastAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.CreatedAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Payload = payload
+	d.NextAttemptAt = utcTime(nextAttemptAt)
+	d.LastAttemptAt = utcTime(lastAttemptAt)
+	d.DeliveredAt = utcTime(deliveredAt)
+	d.CreatedAt = d.CreatedAt.UTC()
+
+	return &d, nil
+}
+
+func utcTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+func eventTypeStrings(types []domain.EventType) []string {
+	s := make([]string, len(types))
+	for i, t := range types {
+		s[i] = string(t)
+	}
+	return s
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,11 +14,17 @@ import (
 )
 
 type Server struct {
-	httpServer *http.Server
-	logger     *log.Logger
+	httpServer      *http.Server
+	logger          *log.Logger
+	shutdownTimeout time.Duration
+	// cancelRequests cancels the base context of every request, aborting
+	// queries that are still running when graceful shutdown gives up.
+	cancelRequests context.CancelFunc
 }
 
 func New(cfg config.ServerConfig, handler http.Handler, logger *log.Logger) *Server {
+	baseCtx, cancel := context.WithCancel(context.Background())
+
 	return &Server{
 		httpServer: &http.Server{
 			Addr:         ":" + cfg.Port,
@@ -26,8 +33,13 @@ func New(cfg config.ServerConfig, handler http.Handler, logger *log.Logger) *Ser
 			WriteTimeout: cfg.WriteTimeout,
 			IdleTimeout:  cfg.IdleTimeout,
 			ErrorLog:     logger,
+			BaseContext: func(net.Listener) context.Context {
+				return baseCtx
+			},
 		},
-		logger: logger,
+		logger:          logger,
+		shutdownTimeout: cfg.ShutdownTimeout,
+		cancelRequests:  cancel,
 	}
 }
 
@@ -47,12 +59,18 @@ func (s *Server) WaitForShutdown() {
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
+	defer s.cancelRequests()
 
 	s.logger.Println("Shutdown signal received")
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		s.logger.Printf("Server shutdown error: %v", err)
+		s.logger.Printf("Server shutdown error: %v, cancelling in-flight requests", err)
+		s.cancelRequests()
+		if err := s.httpServer.Close(); err != nil {
+			s.logger.Printf("Server close error: %v", err)
+		}
+		return
 	}
 
 	s.logger.Println("Server stopped gracefully")
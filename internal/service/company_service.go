@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type CompanyService struct {
+	repo CompanyRepository
+}
+
+func NewCompanyService(repo CompanyRepository) *CompanyService {
+	return &CompanyService{repo: repo}
+}
+
+func (s *CompanyService) CreateCompany(ctx context.Context, company *domain.Company) (int, error) {
+	company.Active = true
+
+	id, err := s.repo.Create(ctx, company)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create company: %w", err)
+	}
+	return id, nil
+}
+
+func (s *CompanyService) GetCompany(ctx context.Context, id int) (*domain.Company, error) {
+	company, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	return company, nil
+}
+
+func (s *CompanyService) ListCompanies(ctx context.Context) ([]*domain.Company, error) {
+	companies, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get companies: %w", err)
+	}
+	return companies, nil
+}
+
+func (s *CompanyService) UpdateCompany(ctx context.Context, id int, patch *domain.CompanyPatch) error {
+	company, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get company: %w", err)
+	}
+
+	if patch.LegalName != nil {
+		company.LegalName = *patch.LegalName
+	}
+	if patch.TaxID != nil {
+		company.TaxID = *patch.TaxID
+	}
+	if patch.DefaultCountry != nil {
+		company.DefaultCountry = *patch.DefaultCountry
+	}
+	if patch.Active != nil {
+		company.Active = *patch.Active
+	}
+
+	if err := s.repo.Update(ctx, company); err != nil {
+		return fmt.Errorf("failed to update company: %w", err)
+	}
+	return nil
+}
+
+func (s *CompanyService) DeleteCompany(ctx context.Context, id int) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete company: %w", err)
+	}
+	return nil
+}
+
+// ensureActiveCompany reports a validation error when employees or
+// departments are about to be attached to a missing or deactivated company.
+func ensureActiveCompany(ctx context.Context, repo CompanyRepository, id int) error {
+	company, err := repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.NewValidationError("companyId", fmt.Sprintf("company %d does not exist", id))
+		}
+		return fmt.Errorf("failed to get company: %w", err)
+	}
+	if !company.Active {
+		return domain.NewValidationError("companyId", fmt.Sprintf("company %d is not active", id))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type CompanyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *CompanyRepositoryMock) Create(ctx context.Context, company *domain.Company) (int, error) {
+	args := m.Called(ctx, company)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *CompanyRepositoryMock) GetByID(ctx context.Context, id int) (*domain.Company, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Company), args.Error(1)
+}
+
+func (m *CompanyRepositoryMock) List(ctx context.Context) ([]*domain.Company, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Company), args.Error(1)
+}
+
+func (m *CompanyRepositoryMock) Update(ctx context.Context, company *domain.Company) error {
+	args := m.Called(ctx, company)
+	return args.Error(0)
+}
+
+func (m *CompanyRepositoryMock) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// activeCompanies returns a company repository in which every company exists
+// and is active.
+func activeCompanies() *CompanyRepositoryMock {
+	repo := new(CompanyRepositoryMock)
+	repo.On("GetByID", mock.Anything, mock.AnythingOfType("int")).Return(&domain.Company{
+		ID:             1,
+		LegalName:      "Acme LLC",
+		TaxID:          "7701234567",
+		DefaultCountry: "RU",
+		Active:         true,
+	}, nil).Maybe()
+	return repo
+}
+
+func TestCompanyService_CreateCompany(t *testing.T) {
+	t.Run("Success: new companies are active", func(t *testing.T) {
+		repo := new(CompanyRepositoryMock)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Company")).Return(1, nil).Run(func(args mock.Arguments) {
+			company := args.Get(1).(*domain.Company)
+			assert.True(t, company.Active)
+		})
+
+		svc := service.NewCompanyService(repo)
+		id, err := svc.CreateCompany(context.Background(), &domain.Company{
+			LegalName:      "Acme LLC",
+			TaxID:          "7701234567",
+			DefaultCountry: "RU",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, id)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error: duplicate tax ID", func(t *testing.T) {
+		repo := new(CompanyRepositoryMock)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Company")).Return(0, domain.NewConflictError("company", "taxId"))
+
+		svc := service.NewCompanyService(repo)
+		_, err := svc.CreateCompany(context.Background(), &domain.Company{LegalName: "Acme LLC", TaxID: "7701234567"})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestCompanyService_UpdateCompany(t *testing.T) {
+	t.Run("Success: only patched fields change", func(t *testing.T) {
+		repo := new(CompanyRepositoryMock)
+		repo.On("GetByID", mock.Anything, 1).Return(&domain.Company{
+			ID:             1,
+			LegalName:      "Acme LLC",
+			TaxID:          "7701234567",
+			DefaultCountry: "RU",
+			Active:         true,
+		}, nil)
+		repo.On("Update", mock.Anything, &domain.Company{
+			ID:             1,
+			LegalName:      "Acme LLC",
+			TaxID:          "7701234567",
+			DefaultCountry: "KZ",
+			Active:         false,
+		}).Return(nil)
+
+		country, active := "KZ", false
+		svc := service.NewCompanyService(repo)
+		err := svc.UpdateCompany(context.Background(), 1, &domain.CompanyPatch{DefaultCountry: &country, Active: &active})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error: company not found", func(t *testing.T) {
+		repo := new(CompanyRepositoryMock)
+		repo.On("GetByID", mock.Anything, 999).Return(nil, domain.NewNotFoundError("company"))
+
+		svc := service.NewCompanyService(repo)
+		err := svc.UpdateCompany(context.Background(), 999, &domain.CompanyPatch{})
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestCompanyService_CompanyChecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		company     *domain.Company
+		repoErr     error
+		expectedErr error
+	}{
+		{
+			name:        "Error: company does not exist",
+			repoErr:     domain.NewNotFoundError("company"),
+			expectedErr: domain.ErrValidation,
+		},
+		{
+			name:        "Error: company is inactive",
+			company:     &domain.Company{ID: 9999, Active: false},
+			expectedErr: domain.ErrValidation,
+		},
+		{
+			name:        "Error: company lookup fails",
+			repoErr:     domain.NewUnavailableError(errors.New("connection refused")),
+			expectedErr: domain.ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			empRepo := new(EmployeeRepositoryMock)
+			deptRepo := new(DepartmentRepositoryMock)
+			companyRepo := new(CompanyRepositoryMock)
+
+			if tt.company != nil {
+				companyRepo.On("GetByID", mock.Anything, 9999).Return(tt.company, nil)
+			} else {
+				companyRepo.On("GetByID", mock.Anything, 9999).Return(nil, tt.repoErr)
+			}
+
+			empSvc := service.NewEmployeeService(empRepo, deptRepo, companyRepo, &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+			_, err := empSvc.CreateEmployee(context.Background(), &domain.Employee{Name: "John", CompanyID: 9999})
+			assert.ErrorIs(t, err, tt.expectedErr)
+
+			deptSvc := service.NewDepartmentService(deptRepo, companyRepo, &eventLog{}, inlineTx{})
+			_, err = deptSvc.GetOrCreate(context.Background(), &domain.Department{CompanyID: 9999, Name: "HR"})
+			assert.ErrorIs(t, err, tt.expectedErr)
+
+			empRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			deptRepo.AssertNotCalled(t, "GetOrCreate", mock.Anything, mock.Anything)
+		})
+	}
+}
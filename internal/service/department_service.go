@@ -1,31 +1,226 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
 )
 
 type DepartmentService struct {
-	repo DepartmentRepository
+	repo        DepartmentRepository
+	companyRepo CompanyRepository
+	outboxRepo  OutboxRepository
+	tx          Transactor
 }
 
-func NewDepartmentService(repo DepartmentRepository) *DepartmentService {
-	return &DepartmentService{repo: repo}
+func NewDepartmentService(repo DepartmentRepository, companyRepo CompanyRepository, outboxRepo OutboxRepository, tx Transactor) *DepartmentService {
+	return &DepartmentService{repo: repo, companyRepo: companyRepo, outboxRepo: outboxRepo, tx: tx}
 }
 
-func (s *DepartmentService) GetOrCreate(dept *domain.Department) (int, error) {
-	id, err := s.repo.GetOrCreate(dept)
+func (s *DepartmentService) GetOrCreate(ctx context.Context, dept *domain.Department) (int, error) {
+	if err := ensureActiveCompany(ctx, s.companyRepo, dept.CompanyID); err != nil {
+		return 0, err
+	}
+
+	var id int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := checkParentDepartment(ctx, s.repo, dept); err != nil {
+			return err
+		}
+
+		var created bool
+		var err error
+		id, created, err = s.repo.GetOrCreate(ctx, dept)
+		if err != nil {
+			return fmt.Errorf("failed to get or create department: %w", err)
+		}
+		if !created {
+			return nil
+		}
+		return addDepartmentCreated(ctx, s.outboxRepo, dept, id)
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to get or create department: %w", err)
+		return 0, err
 	}
 	return id, nil
 }
 
-func (s *DepartmentService) GetDepartment(id int) (*domain.Department, error) {
-	dept, err := s.repo.GetByID(id)
+func (s *DepartmentService) GetDepartment(ctx context.Context, id int) (*domain.Department, error) {
+	dept, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get department: %w", err)
 	}
 	return dept, nil
 }
+
+func (s *DepartmentService) GetCompanyDepartments(ctx context.Context, companyID int) ([]*domain.DepartmentSummary, error) {
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+
+	departments, err := s.repo.ListByCompany(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get departments: %w", err)
+	}
+	return departments, nil
+}
+
+// GetDepartmentTree returns the top-level departments of the company with
+// the departments below them nested, each level sorted by name.
+func (s *DepartmentService) GetDepartmentTree(ctx context.Context, companyID int) ([]*domain.DepartmentNode, error) {
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+
+	departments, err := s.repo.ListByCompany(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get departments: %w", err)
+	}
+	return buildDepartmentTree(departments), nil
+}
+
+// UpdateDepartment changes the non-empty fields of dept. A non-nil ParentID
+// moves the department under that parent, or to the top level if it is 0.
+// A DepartmentUpdated event is recorded if anything changed.
+func (s *DepartmentService) UpdateDepartment(ctx context.Context, dept *domain.Department) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetByID(ctx, dept.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get department: %w", err)
+		}
+		if dept.ParentID != nil && *dept.ParentID != 0 {
+			if err := s.repo.LockHierarchy(ctx, current.CompanyID); err != nil {
+				return fmt.Errorf("failed to lock department hierarchy: %w", err)
+			}
+			moved := *current
+			moved.ParentID = dept.ParentID
+			if err := checkParentDepartment(ctx, s.repo, &moved); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.Update(ctx, dept); err != nil {
+			return fmt.Errorf("failed to update department: %w", err)
+		}
+		updated, err := s.repo.GetByID(ctx, dept.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get department: %w", err)
+		}
+		if updated.Name == current.Name && updated.Phone == current.Phone && sameID(updated.ParentID, current.ParentID) {
+			return nil
+		}
+		return addDepartmentEvent(ctx, s.outboxRepo, domain.EventDepartmentUpdated, updated)
+	})
+}
+
+// DeleteDepartment deletes the department and records a DepartmentDeleted
+// event carrying it as it was.
+func (s *DepartmentService) DeleteDepartment(ctx context.Context, id int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get department: %w", err)
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete department: %w", err)
+		}
+		return addDepartmentEvent(ctx, s.outboxRepo, domain.EventDepartmentDeleted, current)
+	})
+}
+
+// checkParentDepartment makes sure the parent of dept is a department of the
+// same company that is neither dept nor below it.
+func checkParentDepartment(ctx context.Context, repo DepartmentRepository, dept *domain.Department) error {
+	if dept.ParentID == nil {
+		return nil
+	}
+
+	parent, err := repo.GetByID(ctx, *dept.ParentID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewValidationError("parentId", fmt.Sprintf("parent department %d does not exist", *dept.ParentID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get parent department: %w", err)
+	}
+	if parent.CompanyID != dept.CompanyID {
+		return domain.NewValidationError("parentId", "parent department belongs to another company")
+	}
+
+	// New departments have nothing below them yet.
+	if dept.ID == 0 {
+		return nil
+	}
+	seen := map[int]bool{}
+	for ancestor := parent; !seen[ancestor.ID]; {
+		if ancestor.ID == dept.ID {
+			return domain.NewValidationError("parentId", "department cannot be moved under itself or a department below it")
+		}
+		if ancestor.ParentID == nil {
+			return nil
+		}
+		seen[ancestor.ID] = true
+		ancestor, err = repo.GetByID(ctx, *ancestor.ParentID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent department: %w", err)
+		}
+	}
+	return nil
+}
+
+// buildDepartmentTree nests departments under their parents, keeping their
+// order, and rolls headcounts up the tree. Should the parents of a
+// department ever loop back to it, the first department of the loop is put
+// at the top level, so that no department is left out.
+func buildDepartmentTree(departments []*domain.DepartmentSummary) []*domain.DepartmentNode {
+	nodes := make(map[int]*domain.DepartmentNode, len(departments))
+	for _, d := range departments {
+		nodes[d.ID] = &domain.DepartmentNode{DepartmentSummary: *d, Children: []*domain.DepartmentNode{}}
+	}
+
+	cut := map[int]bool{}
+	for _, d := range departments {
+		seen := map[int]bool{d.ID: true}
+		for up := nodes[d.ID]; up.ParentID != nil && !cut[up.ID]; {
+			parent, ok := nodes[*up.ParentID]
+			if !ok {
+				break
+			}
+			if parent.ID == d.ID {
+				cut[d.ID] = true
+				break
+			}
+			if seen[parent.ID] {
+				break
+			}
+			seen[parent.ID] = true
+			up = parent
+		}
+	}
+
+	roots := []*domain.DepartmentNode{}
+	for _, d := range departments {
+		node := nodes[d.ID]
+		if d.ParentID != nil && !cut[d.ID] {
+			if parent, ok := nodes[*d.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	for _, root := range roots {
+		rollUpHeadcount(root)
+	}
+	return roots
+}
+
+func rollUpHeadcount(node *domain.DepartmentNode) int {
+	node.TotalEmployeeCount = node.EmployeeCount
+	for _, child := range node.Children {
+		node.TotalEmployeeCount += rollUpHeadcount(child)
+	}
+	return node.TotalEmployeeCount
+}
@@ -1,32 +1,59 @@
 package service_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
 	"github.com/Hexes-rgb/employee-service/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type DepartmentRepositoryMock struct {
 	mock.Mock
 }
 
-func (m *DepartmentRepositoryMock) GetOrCreate(dept *domain.Department) (int, error) {
-	args := m.Called(dept)
-	return args.Int(0), args.Error(1)
+func (m *DepartmentRepositoryMock) GetOrCreate(ctx context.Context, dept *domain.Department) (int, bool, error) {
+	args := m.Called(ctx, dept)
+	return args.Int(0), args.Bool(1), args.Error(2)
 }
 
-func (m *DepartmentRepositoryMock) GetByID(id int) (*domain.Department, error) {
-	args := m.Called(id)
+func (m *DepartmentRepositoryMock) GetByID(ctx context.Context, id int) (*domain.Department, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Department), args.Error(1)
 }
 
+func (m *DepartmentRepositoryMock) ListByCompany(ctx context.Context, companyID int) ([]*domain.DepartmentSummary, error) {
+	args := m.Called(ctx, companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DepartmentSummary), args.Error(1)
+}
+
+func (m *DepartmentRepositoryMock) Update(ctx context.Context, dept *domain.Department) error {
+	args := m.Called(ctx, dept)
+	return args.Error(0)
+}
+
+func (m *DepartmentRepositoryMock) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *DepartmentRepositoryMock) LockHierarchy(ctx context.Context, companyID int) error {
+	args := m.Called(ctx, companyID)
+	return args.Error(0)
+}
+
 func TestDepartmentService_GetOrCreate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -43,11 +70,11 @@ func TestDepartmentService_GetOrCreate(t *testing.T) {
 				Phone:     "+123456789",
 			},
 			mockSetup: func(m *DepartmentRepositoryMock) {
-				m.On("GetOrCreate", &domain.Department{
+				m.On("GetOrCreate", mock.Anything, &domain.Department{
 					CompanyID: 1,
 					Name:      "Engineering",
 					Phone:     "+123456789",
-				}).Return(42, nil)
+				}).Return(42, true, nil)
 			},
 			expectedID: 42,
 		},
@@ -58,10 +85,10 @@ func TestDepartmentService_GetOrCreate(t *testing.T) {
 				Name:      "HR",
 			},
 			mockSetup: func(m *DepartmentRepositoryMock) {
-				m.On("GetOrCreate", &domain.Department{
+				m.On("GetOrCreate", mock.Anything, &domain.Department{
 					CompanyID: 1,
 					Name:      "HR",
-				}).Return(43, nil)
+				}).Return(43, true, nil)
 			},
 			expectedID: 43,
 		},
@@ -72,10 +99,10 @@ func TestDepartmentService_GetOrCreate(t *testing.T) {
 				Name:      "Finance",
 			},
 			mockSetup: func(m *DepartmentRepositoryMock) {
-				m.On("GetOrCreate", &domain.Department{
+				m.On("GetOrCreate", mock.Anything, &domain.Department{
 					CompanyID: 1,
 					Name:      "Finance",
-				}).Return(0, errors.New("database connection failed"))
+				}).Return(0, false, errors.New("database connection failed"))
 			},
 			expectedErr: "failed to get or create department: database connection failed",
 		},
@@ -86,8 +113,8 @@ func TestDepartmentService_GetOrCreate(t *testing.T) {
 			repo := new(DepartmentRepositoryMock)
 			tt.mockSetup(repo)
 
-			svc := service.NewDepartmentService(repo)
-			id, err := svc.GetOrCreate(tt.inputDept)
+			svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+			id, err := svc.GetOrCreate(context.Background(), tt.inputDept)
 
 			assert.Equal(t, tt.expectedID, id)
 			if tt.expectedErr != "" {
@@ -112,7 +139,7 @@ func TestDepartmentService_GetDepartment(t *testing.T) {
 			name:    "Success: get full department",
 			inputID: 1,
 			mockSetup: func(m *DepartmentRepositoryMock) {
-				m.On("GetByID", 1).Return(&domain.Department{
+				m.On("GetByID", mock.Anything, 1).Return(&domain.Department{
 					ID:        1,
 					CompanyID: 1,
 					Name:      "Engineering",
@@ -130,7 +157,7 @@ func TestDepartmentService_GetDepartment(t *testing.T) {
 			name:    "Success: get department without phone",
 			inputID: 2,
 			mockSetup: func(m *DepartmentRepositoryMock) {
-				m.On("GetByID", 2).Return(&domain.Department{
+				m.On("GetByID", mock.Anything, 2).Return(&domain.Department{
 					ID:        2,
 					CompanyID: 1,
 					Name:      "HR",
@@ -146,7 +173,7 @@ func TestDepartmentService_GetDepartment(t *testing.T) {
 			name:    "Error: department not found",
 			inputID: 999,
 			mockSetup: func(m *DepartmentRepositoryMock) {
-				m.On("GetByID", 999).Return(nil, errors.New("not found"))
+				m.On("GetByID", mock.Anything, 999).Return(nil, errors.New("not found"))
 			},
 			expectedErr: "failed to get department: not found",
 		},
@@ -157,8 +184,8 @@ func TestDepartmentService_GetDepartment(t *testing.T) {
 			repo := new(DepartmentRepositoryMock)
 			tt.mockSetup(repo)
 
-			svc := service.NewDepartmentService(repo)
-			dept, err := svc.GetDepartment(tt.inputID)
+			svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+			dept, err := svc.GetDepartment(context.Background(), tt.inputID)
 
 			assert.Equal(t, tt.expected, dept)
 			if tt.expectedErr != "" {
@@ -170,3 +197,224 @@ func TestDepartmentService_GetDepartment(t *testing.T) {
 		})
 	}
 }
+
+func TestDepartmentService_GetCompanyDepartments(t *testing.T) {
+	t.Run("Success: departments with employee counts", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		expected := []*domain.DepartmentSummary{
+			{Department: domain.Department{ID: 1, CompanyID: 1, Name: "Engineering"}, EmployeeCount: 12},
+			{Department: domain.Department{ID: 2, CompanyID: 1, Name: "HR"}},
+		}
+		repo.On("ListByCompany", mock.Anything, 1).Return(expected, nil)
+
+		svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+		departments, err := svc.GetCompanyDepartments(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, departments)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestDepartmentService_UpdateDepartment(t *testing.T) {
+	stored := func() *domain.Department {
+		return &domain.Department{ID: 1, CompanyID: 1, Name: "Engineering", Phone: "+100"}
+	}
+
+	tests := []struct {
+		name           string
+		getErr         error
+		repoErr        error
+		updated        *domain.Department
+		expectedErr    error
+		expectedEvents []domain.EventType
+	}{
+		{
+			name:           "Success: rename department",
+			updated:        &domain.Department{ID: 1, CompanyID: 1, Name: "Platform", Phone: "+100"},
+			expectedEvents: []domain.EventType{domain.EventDepartmentUpdated},
+		},
+		{
+			name:    "Success: unchanged department records no event",
+			updated: stored(),
+		},
+		{
+			name:        "Error: department not found",
+			getErr:      domain.NewNotFoundError("department"),
+			expectedErr: domain.ErrNotFound,
+		},
+		{
+			name:        "Error: name taken in company",
+			repoErr:     domain.NewConflictError("department", "name"),
+			expectedErr: domain.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(DepartmentRepositoryMock)
+			dept := &domain.Department{ID: 1, Name: "Platform"}
+			if tt.getErr != nil {
+				repo.On("GetByID", mock.Anything, 1).Return(nil, tt.getErr)
+			} else {
+				repo.On("GetByID", mock.Anything, 1).Return(stored(), nil).Once()
+				repo.On("Update", mock.Anything, dept).Return(tt.repoErr)
+			}
+			if tt.updated != nil {
+				repo.On("GetByID", mock.Anything, 1).Return(tt.updated, nil).Once()
+			}
+
+			events := &eventLog{}
+			svc := service.NewDepartmentService(repo, activeCompanies(), events, inlineTx{})
+			err := svc.UpdateDepartment(context.Background(), dept)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.expectedEvents, events.types())
+			if len(events.events) > 0 {
+				assert.Equal(t, domain.AggregateDepartment, events.events[0].AggregateType)
+				assert.JSONEq(t, `{"department":{"id":1,"companyId":1,"name":"Platform","phone":"+100","parentId":null}}`,
+					string(events.events[0].Payload))
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDepartmentService_DeleteDepartment(t *testing.T) {
+	t.Run("Success: delete department", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		repo.On("GetByID", mock.Anything, 1).Return(&domain.Department{ID: 1, CompanyID: 1, Name: "Engineering"}, nil)
+		repo.On("Delete", mock.Anything, 1).Return(nil)
+
+		events := &eventLog{}
+		svc := service.NewDepartmentService(repo, activeCompanies(), events, inlineTx{})
+		err := svc.DeleteDepartment(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.EventType{domain.EventDepartmentDeleted}, events.types())
+		assert.Equal(t, 1, events.events[0].AggregateID)
+		assert.Equal(t, 1, events.events[0].CompanyID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error: department not found", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		repo.On("GetByID", mock.Anything, 999).Return(nil, domain.NewNotFoundError("department"))
+
+		events := &eventLog{}
+		svc := service.NewDepartmentService(repo, activeCompanies(), events, inlineTx{})
+		err := svc.DeleteDepartment(context.Background(), 999)
+
+		assert.EqualError(t, err, "failed to get department: department not found")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Empty(t, events.events)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: department still in use", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		repo.On("GetByID", mock.Anything, 1).Return(&domain.Department{ID: 1, CompanyID: 1}, nil)
+		repo.On("Delete", mock.Anything, 1).Return(fmt.Errorf("%w: department is still referenced by employee departmentId", domain.ErrConflict))
+
+		events := &eventLog{}
+		svc := service.NewDepartmentService(repo, activeCompanies(), events, inlineTx{})
+		err := svc.DeleteDepartment(context.Background(), 1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Empty(t, events.events)
+	})
+}
+
+func TestDepartmentService_Hierarchy(t *testing.T) {
+	dept := func(id int, parentID *int) *domain.Department {
+		return &domain.Department{ID: id, CompanyID: 1, Name: strconv.Itoa(id), ParentID: parentID}
+	}
+
+	t.Run("Success: tree rolls up headcounts", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		repo.On("ListByCompany", mock.Anything, 1).Return([]*domain.DepartmentSummary{
+			{Department: *dept(1, nil), EmployeeCount: 1},
+			{Department: *dept(2, ptrInt(1)), EmployeeCount: 2},
+			{Department: *dept(3, ptrInt(2)), EmployeeCount: 4},
+			{Department: *dept(4, nil)},
+		}, nil)
+
+		svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+		roots, err := svc.GetDepartmentTree(context.Background(), 1)
+
+		require.NoError(t, err)
+		require.Len(t, roots, 2)
+		assert.Equal(t, 7, roots[0].TotalEmployeeCount)
+		assert.Equal(t, 6, roots[0].Children[0].TotalEmployeeCount)
+		assert.Equal(t, 0, roots[1].TotalEmployeeCount)
+		assert.Empty(t, roots[1].Children)
+	})
+
+	t.Run("Success: departments in a loop are not hidden", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		repo.On("ListByCompany", mock.Anything, 1).Return([]*domain.DepartmentSummary{
+			{Department: *dept(1, ptrInt(2))},
+			{Department: *dept(2, ptrInt(1))},
+			{Department: *dept(3, ptrInt(2))},
+			{Department: *dept(4, ptrInt(4))},
+		}, nil)
+
+		svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+		roots, err := svc.GetDepartmentTree(context.Background(), 1)
+
+		require.NoError(t, err)
+		require.Len(t, roots, 2)
+		assert.Equal(t, 1, roots[0].ID)
+		require.Len(t, roots[0].Children, 1)
+		assert.Equal(t, 2, roots[0].Children[0].ID)
+		require.Len(t, roots[0].Children[0].Children, 1)
+		assert.Equal(t, 3, roots[0].Children[0].Children[0].ID)
+		assert.Equal(t, 4, roots[1].ID)
+	})
+
+	t.Run("Success: hierarchy is locked before the parents are read", func(t *testing.T) {
+		var calls []string
+		repo := new(DepartmentRepositoryMock)
+		repo.On("GetByID", mock.Anything, 1).Return(dept(1, nil), nil).Once()
+		repo.On("LockHierarchy", mock.Anything, 1).Return(nil).
+			Run(func(mock.Arguments) { calls = append(calls, "lock") })
+		repo.On("GetByID", mock.Anything, 2).Return(dept(2, nil), nil).
+			Run(func(mock.Arguments) { calls = append(calls, "parent") })
+		repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		repo.On("GetByID", mock.Anything, 1).Return(dept(1, ptrInt(2)), nil)
+
+		svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+		err := svc.UpdateDepartment(context.Background(), &domain.Department{ID: 1, ParentID: ptrInt(2)})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"lock", "parent"}, calls)
+	})
+
+	t.Run("Error: move under a department below it", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		repo.On("GetByID", mock.Anything, 1).Return(dept(1, nil), nil)
+		repo.On("GetByID", mock.Anything, 2).Return(dept(2, ptrInt(1)), nil)
+		repo.On("GetByID", mock.Anything, 3).Return(dept(3, ptrInt(2)), nil)
+		repo.On("LockHierarchy", mock.Anything, 1).Return(nil)
+
+		svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+		err := svc.UpdateDepartment(context.Background(), &domain.Department{ID: 1, ParentID: ptrInt(3)})
+
+		assert.Equal(t, "parentId", violatedField(t, err))
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: parent of another company", func(t *testing.T) {
+		repo := new(DepartmentRepositoryMock)
+		repo.On("GetByID", mock.Anything, 5).Return(&domain.Department{ID: 5, CompanyID: 2}, nil)
+
+		svc := service.NewDepartmentService(repo, activeCompanies(), &eventLog{}, inlineTx{})
+		_, err := svc.GetOrCreate(context.Background(), &domain.Department{CompanyID: 1, Name: "Team", ParentID: ptrInt(5)})
+
+		assert.Equal(t, "parentId", violatedField(t, err))
+	})
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+const (
+	DefaultExpiryWindowDays = 30
+	MaxExpiryWindowDays     = 3650
+)
+
+const errPrimaryDocument = "the primary document is the passport of the employee and changes with their passportType and passportNumber"
+
+// DocumentService manages the identity documents of employees. Documents of
+// deleted employees are kept but cannot be reached until the employee is
+// restored. The primary document is the passport of the employee and is
+// written by EmployeeService together with it, so here it can only have
+// its issuing country and dates changed.
+type DocumentService struct {
+	repo        DocumentRepository
+	empRepo     EmployeeRepository
+	companyRepo CompanyRepository
+	tx          Transactor
+}
+
+func NewDocumentService(repo DocumentRepository, empRepo EmployeeRepository, companyRepo CompanyRepository, tx Transactor) *DocumentService {
+	return &DocumentService{repo: repo, empRepo: empRepo, companyRepo: companyRepo, tx: tx}
+}
+
+// CreateDocument adds a secondary document to the employee.
+func (s *DocumentService) CreateDocument(ctx context.Context, employeeID int, doc *domain.IdentityDocument) (*domain.IdentityDocument, error) {
+	doc.EmployeeID = employeeID
+	if doc.Primary {
+		return nil, domain.NewValidationError("primary", errPrimaryDocument)
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.empRepo.GetByID(ctx, employeeID); err != nil {
+			return fmt.Errorf("failed to get employee: %w", err)
+		}
+
+		id, err := s.repo.Create(ctx, doc)
+		if err != nil {
+			return fmt.Errorf("failed to create document: %w", err)
+		}
+		doc.ID = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (s *DocumentService) GetDocument(ctx context.Context, employeeID, id int) (*domain.IdentityDocument, error) {
+	if _, err := s.empRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+	return s.document(ctx, employeeID, id)
+}
+
+func (s *DocumentService) ListDocuments(ctx context.Context, employeeID int) ([]*domain.IdentityDocument, error) {
+	if _, err := s.empRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	docs, err := s.repo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+	return docs, nil
+}
+
+// UpdateDocument replaces the fields of a document of the employee. Whether
+// it is primary cannot change, nor can the type and number of the primary
+// document.
+func (s *DocumentService) UpdateDocument(ctx context.Context, employeeID int, doc *domain.IdentityDocument) (*domain.IdentityDocument, error) {
+	doc.EmployeeID = employeeID
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.empRepo.GetByID(ctx, employeeID); err != nil {
+			return fmt.Errorf("failed to get employee: %w", err)
+		}
+		current, err := s.document(ctx, employeeID, doc.ID)
+		if err != nil {
+			return err
+		}
+		if doc.Primary != current.Primary {
+			return domain.NewValidationError("primary", errPrimaryDocument)
+		}
+		if current.Primary && doc.Type != current.Type {
+			return domain.NewValidationError("type", errPrimaryDocument)
+		}
+		if current.Primary && doc.Number != current.Number {
+			return domain.NewValidationError("number", errPrimaryDocument)
+		}
+
+		if err := s.repo.Update(ctx, doc); err != nil {
+			return fmt.Errorf("failed to update document: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// DeleteDocument deletes a secondary document of the employee.
+func (s *DocumentService) DeleteDocument(ctx context.Context, employeeID, id int) error {
+	doc, err := s.GetDocument(ctx, employeeID, id)
+	if err != nil {
+		return err
+	}
+	if doc.Primary {
+		return fmt.Errorf("%w: the primary document is the passport of the employee and cannot be deleted", domain.ErrConflict)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// GetExpiringDocuments returns the documents of the company's employees that
+// expire within the next days days, including today. A zero days means
+// DefaultExpiryWindowDays.
+func (s *DocumentService) GetExpiringDocuments(ctx context.Context, companyID, days int) ([]*domain.ExpiringDocument, error) {
+	if days == 0 {
+		days = DefaultExpiryWindowDays
+	}
+	if days < 0 || days > MaxExpiryWindowDays {
+		return nil, domain.NewValidationError("days", fmt.Sprintf("days must be between 1 and %d", MaxExpiryWindowDays))
+	}
+
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+
+	today := domain.Today()
+	docs, err := s.repo.ListExpiring(ctx, companyID, today, today.AddDays(days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring documents: %w", err)
+	}
+	return docs, nil
+}
+
+// document returns the document if it belongs to the employee; documents of
+// other employees are reported as not found.
+func (s *DocumentService) document(ctx context.Context, employeeID, id int) (*domain.IdentityDocument, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	if doc.EmployeeID != employeeID {
+		return nil, domain.NewNotFoundError("document")
+	}
+	return doc, nil
+}
@@ -0,0 +1,221 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type DocumentRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *DocumentRepositoryMock) Create(ctx context.Context, doc *domain.IdentityDocument) (int, error) {
+	args := m.Called(ctx, doc)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *DocumentRepositoryMock) GetByID(ctx context.Context, id int) (*domain.IdentityDocument, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdentityDocument), args.Error(1)
+}
+
+func (m *DocumentRepositoryMock) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.IdentityDocument, error) {
+	args := m.Called(ctx, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.IdentityDocument), args.Error(1)
+}
+
+func (m *DocumentRepositoryMock) Update(ctx context.Context, doc *domain.IdentityDocument) error {
+	args := m.Called(ctx, doc)
+	return args.Error(0)
+}
+
+func (m *DocumentRepositoryMock) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *DocumentRepositoryMock) ListExpiring(ctx context.Context, companyID int, from, to domain.Date) ([]*domain.ExpiringDocument, error) {
+	args := m.Called(ctx, companyID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ExpiringDocument), args.Error(1)
+}
+
+func TestDocumentService_CreateDocument(t *testing.T) {
+	t.Run("Success: document is created in a transaction", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+		empRepo := new(EmployeeRepositoryMock)
+		tx := &recordingTx{}
+
+		empRepo.On("GetByID", mock.MatchedBy(inTx), 1).Return(storedEmployee(), nil)
+		repo.On("Create", mock.MatchedBy(inTx), mock.AnythingOfType("*domain.IdentityDocument")).Return(7, nil)
+
+		svc := service.NewDocumentService(repo, empRepo, activeCompanies(), tx)
+		doc, err := svc.CreateDocument(context.Background(), 1, &domain.IdentityDocument{
+			Type: "work_permit", Number: "WP-1", IssuingCountry: "RU",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 7, doc.ID)
+		assert.Equal(t, 1, doc.EmployeeID)
+		assert.Equal(t, 1, tx.calls)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error: primary documents come from the employee passport", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+
+		svc := service.NewDocumentService(repo, new(EmployeeRepositoryMock), activeCompanies(), inlineTx{})
+		_, err := svc.CreateDocument(context.Background(), 1, &domain.IdentityDocument{
+			Type: "work_permit", Number: "WP-1", IssuingCountry: "RU", Primary: true,
+		})
+
+		assert.Equal(t, "primary", violatedField(t, err))
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: unknown employee", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+		empRepo := new(EmployeeRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 999).Return(nil, domain.NewNotFoundError("employee"))
+
+		svc := service.NewDocumentService(repo, empRepo, activeCompanies(), inlineTx{})
+		_, err := svc.CreateDocument(context.Background(), 999, &domain.IdentityDocument{})
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_UpdateDocument(t *testing.T) {
+	primary := func() *domain.IdentityDocument {
+		return &domain.IdentityDocument{ID: 7, EmployeeID: 1, Type: "internal", Number: "1234567890", IssuingCountry: "RU", Primary: true}
+	}
+
+	t.Run("Success: primary document keeps the employee passport", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+		empRepo := new(EmployeeRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		repo.On("GetByID", mock.Anything, 7).Return(primary(), nil)
+		repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.IdentityDocument")).Return(nil)
+
+		expires, err := domain.ParseDate("2030-01-01")
+		require.NoError(t, err)
+		doc := primary()
+		doc.IssuingCountry, doc.ExpiresOn = "KZ", &expires
+
+		svc := service.NewDocumentService(repo, empRepo, activeCompanies(), inlineTx{})
+		_, err = svc.UpdateDocument(context.Background(), 1, doc)
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	for name, change := range map[string]func(doc *domain.IdentityDocument){
+		"primary": func(doc *domain.IdentityDocument) { doc.Primary = false },
+		"type":    func(doc *domain.IdentityDocument) { doc.Type = "foreign" },
+		"number":  func(doc *domain.IdentityDocument) { doc.Number = "0987654321" },
+	} {
+		t.Run("Error: primary document "+name+" changes only with the employee", func(t *testing.T) {
+			repo := new(DocumentRepositoryMock)
+			empRepo := new(EmployeeRepositoryMock)
+
+			empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+			repo.On("GetByID", mock.Anything, 7).Return(primary(), nil)
+
+			doc := primary()
+			change(doc)
+			svc := service.NewDocumentService(repo, empRepo, activeCompanies(), inlineTx{})
+			_, err := svc.UpdateDocument(context.Background(), 1, doc)
+
+			assert.Equal(t, name, violatedField(t, err))
+			repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Error: secondary document cannot become primary", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+		empRepo := new(EmployeeRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		repo.On("GetByID", mock.Anything, 8).Return(&domain.IdentityDocument{ID: 8, EmployeeID: 1}, nil)
+
+		svc := service.NewDocumentService(repo, empRepo, activeCompanies(), inlineTx{})
+		_, err := svc.UpdateDocument(context.Background(), 1, &domain.IdentityDocument{ID: 8, Primary: true})
+
+		assert.Equal(t, "primary", violatedField(t, err))
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: document of another employee", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+		empRepo := new(EmployeeRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		repo.On("GetByID", mock.Anything, 7).Return(&domain.IdentityDocument{ID: 7, EmployeeID: 2}, nil)
+
+		svc := service.NewDocumentService(repo, empRepo, activeCompanies(), inlineTx{})
+		_, err := svc.UpdateDocument(context.Background(), 1, &domain.IdentityDocument{ID: 7})
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_DeleteDocument(t *testing.T) {
+	t.Run("Error: primary document", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+		empRepo := new(EmployeeRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		repo.On("GetByID", mock.Anything, 7).Return(&domain.IdentityDocument{ID: 7, EmployeeID: 1, Primary: true}, nil)
+
+		svc := service.NewDocumentService(repo, empRepo, activeCompanies(), inlineTx{})
+		err := svc.DeleteDocument(context.Background(), 1, 7)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_GetExpiringDocuments(t *testing.T) {
+	t.Run("Success: default window", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+		today := domain.Today()
+
+		repo.On("ListExpiring", mock.Anything, 1, today, today.AddDays(service.DefaultExpiryWindowDays)).
+			Return([]*domain.ExpiringDocument{}, nil)
+
+		svc := service.NewDocumentService(repo, new(EmployeeRepositoryMock), activeCompanies(), inlineTx{})
+		docs, err := svc.GetExpiringDocuments(context.Background(), 1, 0)
+
+		require.NoError(t, err)
+		assert.Empty(t, docs)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error: window too long", func(t *testing.T) {
+		repo := new(DocumentRepositoryMock)
+
+		svc := service.NewDocumentService(repo, new(EmployeeRepositoryMock), activeCompanies(), inlineTx{})
+		_, err := svc.GetExpiringDocuments(context.Background(), 1, service.MaxExpiryWindowDays+1)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		repo.AssertNotCalled(t, "ListExpiring", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// audit appends an entry for a write to employee id to its hash chain. It
+// must run in the transaction of the write: the write locks the employee
+// row, so entries of one employee are chained one at a time.
+func (s *EmployeeService) audit(ctx context.Context, action domain.AuditAction, id int, before, after *domain.Employee) error {
+	prev, err := s.auditRepo.Last(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get last audit entry: %w", err)
+	}
+
+	entry := &domain.AuditEntry{
+		EmployeeID: id,
+		Action:     action,
+		Actor:      domain.ActorFromContext(ctx),
+		RequestID:  domain.RequestIDFromContext(ctx),
+		OccurredAt: time.Now(),
+		Changes:    domain.DiffEmployees(before, after),
+	}
+	entry.Seal(prev)
+
+	if err := s.auditRepo.Append(ctx, entry); err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	return nil
+}
+
+// GetEmployeeHistory returns the audit trail of the employee, oldest entry
+// first. Deleted employees keep their history.
+func (s *EmployeeService) GetEmployeeHistory(ctx context.Context, id int) ([]*domain.AuditEntry, error) {
+	entries, err := s.auditRepo.ListByEmployee(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employee history: %w", err)
+	}
+
+	if len(entries) == 0 {
+		// Employees created before auditing started have no entries.
+		if _, err := s.empRepo.GetByID(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to get employee: %w", err)
+		}
+	}
+
+	return entries, nil
+}
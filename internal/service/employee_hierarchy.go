@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// GetDirectReports returns the employees reporting to the manager directly.
+func (s *EmployeeService) GetDirectReports(ctx context.Context, id int) ([]*domain.Employee, error) {
+	if _, err := s.empRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	reports, err := s.empRepo.GetDirectReports(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get direct reports: %w", err)
+	}
+	return reports, nil
+}
+
+// GetManagementChain returns the managers of the employee from their own
+// manager up to the top of the company.
+func (s *EmployeeService) GetManagementChain(ctx context.Context, id int) ([]*domain.Employee, error) {
+	if _, err := s.empRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	chain, err := s.empRepo.GetManagementChain(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get management chain: %w", err)
+	}
+	return chain, nil
+}
+
+// GetSubordinates returns everyone below the manager, nearest levels first.
+func (s *EmployeeService) GetSubordinates(ctx context.Context, id int) ([]*domain.Subordinate, error) {
+	if _, err := s.empRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	subordinates, err := s.empRepo.GetSubordinates(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subordinates: %w", err)
+	}
+	return subordinates, nil
+}
+
+// checkReportingLines validates an update of current into emp. The manager
+// is only checked when it or the company changes, so that an employee whose
+// manager has left can still be updated. An employee with direct reports
+// cannot change company, since the reports would be left with a manager
+// from another one.
+func (s *EmployeeService) checkReportingLines(ctx context.Context, current, emp *domain.Employee) error {
+	if current.CompanyID != emp.CompanyID {
+		reports, err := s.empRepo.GetDirectReports(ctx, emp.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get direct reports: %w", err)
+		}
+		if len(reports) > 0 {
+			return domain.NewValidationError("companyId", "employee with direct reports cannot move to another company")
+		}
+	}
+	if sameID(current.ManagerID, emp.ManagerID) && current.CompanyID == emp.CompanyID {
+		return nil
+	}
+	return s.checkManager(ctx, emp)
+}
+
+// checkManager makes sure the manager of emp is a live employee of the same
+// company who does not already report to emp, directly or not.
+func (s *EmployeeService) checkManager(ctx context.Context, emp *domain.Employee) error {
+	if emp.ManagerID == nil {
+		return nil
+	}
+	if *emp.ManagerID == emp.ID {
+		return domain.NewValidationError("managerId", "employee cannot be their own manager")
+	}
+
+	manager, err := s.empRepo.GetByID(ctx, *emp.ManagerID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewValidationError("managerId", fmt.Sprintf("manager %d does not exist", *emp.ManagerID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get manager: %w", err)
+	}
+	if manager.CompanyID != emp.CompanyID {
+		return domain.NewValidationError("managerId", "manager belongs to another company")
+	}
+
+	// A new employee has nobody reporting to them yet.
+	if emp.ID == 0 {
+		return nil
+	}
+	cycle, err := s.reportsTo(ctx, emp.CompanyID, manager.ID, emp.ID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return domain.NewValidationError("managerId",
+			fmt.Sprintf("employee %d already reports to employee %d, which would make a cycle", manager.ID, emp.ID))
+	}
+	return nil
+}
+
+// reportsTo tells whether managerID is id or reports to it, directly or
+// through other managers, deleted ones included: a deleted manager can be
+// restored, and the cycle with them. It locks the reporting lines of the
+// company first, so the answer holds until the transaction ends.
+func (s *EmployeeService) reportsTo(ctx context.Context, companyID, managerID, id int) (bool, error) {
+	if err := s.empRepo.LockReportingLines(ctx, companyID); err != nil {
+		return false, fmt.Errorf("failed to lock reporting lines: %w", err)
+	}
+	if managerID == id {
+		return true, nil
+	}
+
+	above, err := s.empRepo.GetManagerIDs(ctx, managerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get management chain: %w", err)
+	}
+	for _, m := range above {
+		if m == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
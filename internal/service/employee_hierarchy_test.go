@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/service"
+)
+
+func TestEmployeeService_Managers(t *testing.T) {
+	manager := func(id, companyID int) *domain.Employee {
+		emp := storedEmployee()
+		emp.ID, emp.CompanyID = id, companyID
+		return emp
+	}
+
+	t.Run("Error: new manager already reports to the employee", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("GetByID", mock.Anything, 3).Return(manager(3, 1), nil)
+		empRepo.On("LockReportingLines", mock.Anything, 1).Return(nil)
+		empRepo.On("GetManagerIDs", mock.Anything, 3).Return([]int{2, 1}, nil)
+
+		svc := service.NewEmployeeService(empRepo, new(DepartmentRepositoryMock), activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 1, 0, mergePatch(t, `{"managerId":3}`))
+
+		assert.Equal(t, "managerId", violatedField(t, err))
+		empRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: manager of another company", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		empRepo.On("GetByID", mock.Anything, 3).Return(manager(3, 2), nil)
+
+		emp := storedEmployee()
+		emp.ID, emp.Version, emp.Department, emp.DepartmentID, emp.ManagerID = 0, 0, nil, nil, ptrInt(3)
+		svc := service.NewEmployeeService(empRepo, new(DepartmentRepositoryMock), activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.CreateEmployee(context.Background(), emp)
+
+		assert.Equal(t, "managerId", violatedField(t, err))
+		empRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: unchanged manager is not checked again", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		stored := storedEmployee()
+		stored.ManagerID = ptrInt(3)
+		empRepo.On("GetByID", mock.Anything, 1).Return(stored, nil)
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil)
+
+		svc := service.NewEmployeeService(empRepo, new(DepartmentRepositoryMock), activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		emp, err := svc.UpdateEmployee(context.Background(), 1, 0, mergePatch(t, `{"phone":"+70000000000"}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, ptrInt(3), emp.ManagerID)
+		empRepo.AssertNotCalled(t, "GetByID", mock.Anything, 3)
+	})
+
+	t.Run("Success: reporting lines are locked before the chain is read", func(t *testing.T) {
+		var calls []string
+		empRepo := new(EmployeeRepositoryMock)
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("GetByID", mock.Anything, 3).Return(manager(3, 1), nil)
+		empRepo.On("LockReportingLines", mock.Anything, 1).Return(nil).
+			Run(func(mock.Arguments) { calls = append(calls, "lock") })
+		empRepo.On("GetManagerIDs", mock.Anything, 3).Return([]int{}, nil).
+			Run(func(mock.Arguments) { calls = append(calls, "chain") })
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil)
+
+		svc := service.NewEmployeeService(empRepo, new(DepartmentRepositoryMock), activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 1, 0, mergePatch(t, `{"managerId":3}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"lock", "chain"}, calls)
+	})
+
+	t.Run("Error: restore would close a cycle", func(t *testing.T) {
+		restored := storedEmployee()
+		restored.ManagerID = ptrInt(3)
+		empRepo := new(EmployeeRepositoryMock)
+		empRepo.On("Restore", mock.Anything, 1).Return(nil)
+		empRepo.On("GetByID", mock.Anything, 1).Return(restored, nil)
+		empRepo.On("LockReportingLines", mock.Anything, 1).Return(nil)
+		empRepo.On("GetManagerIDs", mock.Anything, 3).Return([]int{2, 1}, nil)
+
+		tx := &recordingTx{}
+		events := &eventLog{}
+		svc := service.NewEmployeeService(empRepo, new(DepartmentRepositoryMock), activeCompanies(), &auditLog{}, events, &assignmentLog{}, &documentLog{}, tx)
+		err := svc.RestoreEmployee(context.Background(), 1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.True(t, tx.rolledBack)
+		assert.Empty(t, events.types())
+	})
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/validation"
+)
+
+// patchEmployeeDocument applies patch to doc and decodes the result strictly:
+// fields that are not part of the document, such as id or version, are
+// rejected instead of being silently dropped.
+func patchEmployeeDocument(doc *domain.EmployeeDocument, patch domain.Patch) (*domain.EmployeeDocument, error) {
+	original, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode employee: %w", err)
+	}
+
+	patched, err := patch.Apply(original)
+	if err != nil {
+		if errors.Is(err, domain.ErrConflict) || errors.Is(err, domain.ErrValidation) {
+			return nil, err
+		}
+		return nil, domain.NewValidationError("", err.Error())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+
+	var result domain.EmployeeDocument
+	if err := dec.Decode(&result); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, domain.NewValidationError(typeErr.Field, typeErr.Field+" must be "+typeErr.Type.String())
+		}
+		return nil, domain.NewValidationError("", "patched employee is invalid: "+err.Error())
+	}
+
+	return &result, nil
+}
+
+// validateEmployeeDocument checks the employee as it will be written, after
+// the patch has been applied.
+func validateEmployeeDocument(doc *domain.EmployeeDocument) error {
+	v := validation.New()
+
+	emp := &domain.Employee{
+		Name:           doc.Name,
+		Surname:        doc.Surname,
+		Phone:          doc.Phone,
+		CompanyID:      doc.CompanyID,
+		PassportNumber: doc.PassportNumber,
+		Department:     doc.Department,
+	}
+	if doc.PassportType != nil {
+		v.Check(*doc.PassportType != "", "passportType", validation.CodeInvalid, "employee passportType must be null or non-empty")
+		emp.PassportType = *doc.PassportType
+	}
+	validation.Employee(v, emp)
+
+	return v.Err()
+}
@@ -1,117 +1,412 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
 )
 
 type EmployeeService struct {
-	empRepo  EmployeeRepository
-	deptRepo DepartmentRepository
+	empRepo     EmployeeRepository
+	deptRepo    DepartmentRepository
+	companyRepo CompanyRepository
+	auditRepo   AuditRepository
+	outboxRepo  OutboxRepository
+	assignRepo  AssignmentRepository
+	docRepo     DocumentRepository
+	tx          Transactor
 }
 
 func NewEmployeeService(
 	empRepo EmployeeRepository,
 	deptRepo DepartmentRepository,
+	companyRepo CompanyRepository,
+	auditRepo AuditRepository,
+	outboxRepo OutboxRepository,
+	assignRepo AssignmentRepository,
+	docRepo DocumentRepository,
+	tx Transactor,
 ) *EmployeeService {
 	return &EmployeeService{
-		empRepo:  empRepo,
-		deptRepo: deptRepo,
+		empRepo:     empRepo,
+		deptRepo:    deptRepo,
+		companyRepo: companyRepo,
+		auditRepo:   auditRepo,
+		outboxRepo:  outboxRepo,
+		assignRepo:  assignRepo,
+		docRepo:     docRepo,
+		tx:          tx,
 	}
 }
 
-func (s *EmployeeService) CreateEmployee(emp *domain.Employee) (int, error) {
-	if emp.Department != nil {
-		deptID, err := s.deptRepo.GetOrCreate(emp.Department)
+func (s *EmployeeService) CreateEmployee(ctx context.Context, emp *domain.Employee) (int, error) {
+	if err := s.checkCompanies(ctx, emp); err != nil {
+		return 0, err
+	}
+
+	var id int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkDepartment(ctx, nil, emp); err != nil {
+			return err
+		}
+		if err := s.attachDepartment(ctx, emp); err != nil {
+			return err
+		}
+		if err := s.checkManager(ctx, emp); err != nil {
+			return err
+		}
+
+		var err error
+		id, err = s.empRepo.Create(ctx, emp)
 		if err != nil {
-			return 0, fmt.Errorf("failed to get or create department: %w", err)
+			return fmt.Errorf("failed to create employee: %w", err)
+		}
+		if err := s.savePassport(ctx, nil, id, emp); err != nil {
+			return err
+		}
+		if emp.DepartmentID != nil {
+			if _, err := s.assign(ctx, id, emp.DepartmentID, domain.Today()); err != nil {
+				return err
+			}
+		}
+		if err := s.audit(ctx, domain.AuditCreate, id, nil, emp); err != nil {
+			return err
 		}
-		emp.DepartmentID = &deptID
-	}
 
-	id, err := s.empRepo.Create(emp)
+		created := *emp
+		created.ID = id
+		return addEmployeeEvent(ctx, s.outboxRepo, domain.EventEmployeeCreated, &created, nil)
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to create employee: %w", err)
+		return 0, err
 	}
 
 	return id, nil
 }
 
-func (s *EmployeeService) GetEmployee(id int) (*domain.Employee, error) {
-	emp, err := s.empRepo.GetByID(id)
+func (s *EmployeeService) GetEmployee(ctx context.Context, id int) (*domain.Employee, error) {
+	emp, err := s.empRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get employee: %w", err)
 	}
 
-	if emp.DepartmentID != nil {
-		dept, err := s.deptRepo.GetByID(*emp.DepartmentID)
+	return emp, nil
+}
+
+// UpdateEmployee applies patch to the JSON document of the employee and
+// writes the result after validating it. If version is non-zero and no
+// longer current, a *domain.VersionConflictError is returned. The whole
+// read-patch-write cycle runs in one transaction and the write is guarded by
+// the version that was read, so concurrent updates are never lost.
+func (s *EmployeeService) UpdateEmployee(ctx context.Context, id, version int, patch domain.Patch) (*domain.Employee, error) {
+	var emp *domain.Employee
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.empRepo.GetByID(ctx, id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get department: %w", err)
+			return fmt.Errorf("failed to get employee: %w", err)
+		}
+		if version != 0 && version != current.Version {
+			return domain.NewVersionConflictError("employee", version)
+		}
+
+		doc, err := patchEmployeeDocument(domain.NewEmployeeDocument(current), patch)
+		if err != nil {
+			return err
+		}
+		if err := validateEmployeeDocument(doc); err != nil {
+			return err
+		}
+
+		emp = &domain.Employee{ID: current.ID, Version: current.Version}
+		doc.ApplyTo(emp)
+
+		if err := s.checkCompanies(ctx, emp); err != nil {
+			return err
+		}
+		if err := s.checkDepartment(ctx, current, emp); err != nil {
+			return err
+		}
+		if err := s.attachDepartment(ctx, emp); err != nil {
+			return err
+		}
+		if err := s.checkReportingLines(ctx, current, emp); err != nil {
+			return err
+		}
+
+		if err := s.empRepo.Update(ctx, emp); err != nil {
+			return fmt.Errorf("failed to update employee: %w", err)
+		}
+		if err := s.savePassport(ctx, current, id, emp); err != nil {
+			return err
 		}
-		emp.Department = dept
+		if !sameID(current.DepartmentID, emp.DepartmentID) {
+			if _, err := s.assign(ctx, id, emp.DepartmentID, domain.Today()); err != nil {
+				return err
+			}
+		}
+		if err := s.audit(ctx, domain.AuditUpdate, id, current, emp); err != nil {
+			return err
+		}
+
+		changes := domain.DiffEmployees(current, emp)
+		if len(changes) == 0 {
+			return nil
+		}
+		return addEmployeeEvent(ctx, s.outboxRepo, domain.EventEmployeeUpdated, emp, changes)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return emp, nil
 }
 
-func (s *EmployeeService) UpdateEmployee(emp *domain.Employee) error {
-	if emp.Department != nil {
-		deptID, err := s.deptRepo.GetOrCreate(emp.Department)
-		if err != nil {
-			return fmt.Errorf("failed to get or create department: %w", err)
+// attachDepartment gets or creates emp.Department and points emp at it. It is
+// called inside the employee write transaction, so a department created for
+// an employee that then fails to save is rolled back with it, together with
+// its DepartmentCreated event.
+func (s *EmployeeService) attachDepartment(ctx context.Context, emp *domain.Employee) error {
+	if emp.Department == nil {
+		return nil
+	}
+	if err := checkParentDepartment(ctx, s.deptRepo, emp.Department); err != nil {
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			return domain.NewValidationError("department."+verr.Field, verr.Message)
 		}
-		emp.DepartmentID = &deptID
+		return err
+	}
+
+	deptID, created, err := s.deptRepo.GetOrCreate(ctx, emp.Department)
+	if err != nil {
+		return fmt.Errorf("failed to get or create department: %w", err)
+	}
+	if created {
+		if err := addDepartmentCreated(ctx, s.outboxRepo, emp.Department, deptID); err != nil {
+			return err
+		}
+	}
+	emp.DepartmentID = &deptID
+	return nil
+}
+
+// savePassport writes the primary document of employee id, whose type and
+// number are the passportType and passportNumber of emp: it is created with
+// the employee and updated whenever its passport changes. On update, current
+// is the stored employee; an employee without a primary document gets one,
+// issued by the company's default country. A type and number taken by
+// another document are reported as a conflict on passportNumber.
+func (s *EmployeeService) savePassport(ctx context.Context, current *domain.Employee, id int, emp *domain.Employee) error {
+	if current != nil && current.PassportType == emp.PassportType && current.PassportNumber == emp.PassportNumber {
+		return nil
 	}
 
-	if err := s.empRepo.Update(emp); err != nil {
-		return fmt.Errorf("failed to update employee: %w", err)
+	var primary *domain.IdentityDocument
+	if current != nil {
+		docs, err := s.docRepo.ListByEmployee(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get documents: %w", err)
+		}
+		for _, doc := range docs {
+			if doc.Primary {
+				primary = doc
+			}
+		}
 	}
+	if primary == nil {
+		company, err := s.companyRepo.GetByID(ctx, emp.CompanyID)
+		if err != nil {
+			return fmt.Errorf("failed to get company: %w", err)
+		}
+		primary = &domain.IdentityDocument{EmployeeID: id, IssuingCountry: company.DefaultCountry, Primary: true}
+	}
+	primary.Type = domain.PassportDocumentType(emp.PassportType)
+	primary.Number = emp.PassportNumber
 
+	var err error
+	if primary.ID == 0 {
+		_, err = s.docRepo.Create(ctx, primary)
+	} else {
+		err = s.docRepo.Update(ctx, primary)
+	}
+	var conflictErr *domain.ConflictError
+	if errors.As(err, &conflictErr) {
+		return domain.NewConflictError("employee", "passportNumber")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save primary document: %w", err)
+	}
 	return nil
 }
 
-func (s *EmployeeService) DeleteEmployee(id int) error {
-	if err := s.empRepo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete employee: %w", err)
+// checkCompanies makes sure the company emp is being attached to exists and
+// is active, and that a department given in full belongs to it. Zero IDs are
+// left for validation.
+func (s *EmployeeService) checkCompanies(ctx context.Context, emp *domain.Employee) error {
+	if emp.CompanyID != 0 {
+		if err := ensureActiveCompany(ctx, s.companyRepo, emp.CompanyID); err != nil {
+			return err
+		}
+	}
+	if emp.Department != nil && emp.Department.CompanyID != 0 && emp.Department.CompanyID != emp.CompanyID {
+		return domain.NewValidationError("department.companyId", "department belongs to another company")
 	}
 	return nil
 }
 
-func (s *EmployeeService) GetCompanyEmployees(companyID int) ([]*domain.Employee, error) {
-	employees, err := s.empRepo.GetByCompany(companyID)
+// checkDepartment makes sure the department emp points at by ID belongs to
+// the company of emp. Departments given in full are checked by
+// checkCompanies before they are created. On update, current is the stored
+// employee and nothing is checked unless the department or company changes.
+func (s *EmployeeService) checkDepartment(ctx context.Context, current, emp *domain.Employee) error {
+	if emp.DepartmentID == nil || emp.Department != nil {
+		return nil
+	}
+	if current != nil && sameID(current.DepartmentID, emp.DepartmentID) && current.CompanyID == emp.CompanyID {
+		return nil
+	}
+
+	dept, err := s.deptRepo.GetByID(ctx, *emp.DepartmentID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewValidationError("departmentId", fmt.Sprintf("department %d does not exist", *emp.DepartmentID))
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get employees: %w", err)
+		return fmt.Errorf("failed to get department: %w", err)
 	}
+	if dept.CompanyID != emp.CompanyID {
+		return domain.NewValidationError("departmentId", "department belongs to another company")
+	}
+	return nil
+}
 
-	for _, emp := range employees {
-		if emp.DepartmentID != nil {
-			dept, err := s.deptRepo.GetByID(*emp.DepartmentID)
+// DeleteEmployee soft-deletes the employee. A non-zero version must be
+// current, otherwise a *domain.VersionConflictError is returned.
+func (s *EmployeeService) DeleteEmployee(ctx context.Context, id, version int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.empRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get employee: %w", err)
+		}
+
+		if err := s.empRepo.Delete(ctx, id, version); err != nil {
+			return fmt.Errorf("failed to delete employee: %w", err)
+		}
+		if err := s.audit(ctx, domain.AuditDelete, id, current, nil); err != nil {
+			return err
+		}
+		return addEmployeeEvent(ctx, s.outboxRepo, domain.EventEmployeeDeleted, current, nil)
+	})
+}
+
+// RestoreEmployee undeletes the employee. It is a conflict if their manager
+// has come to report to them in the meantime.
+func (s *EmployeeService) RestoreEmployee(ctx context.Context, id int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.empRepo.Restore(ctx, id); err != nil {
+			return fmt.Errorf("failed to restore employee: %w", err)
+		}
+
+		restored, err := s.empRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get employee: %w", err)
+		}
+		if restored.ManagerID != nil {
+			cycle, err := s.reportsTo(ctx, restored.CompanyID, *restored.ManagerID, id)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get department: %w", err)
+				return err
+			}
+			if cycle {
+				return fmt.Errorf("%w: restoring employee %d would make a reporting cycle", domain.ErrConflict, id)
 			}
-			emp.Department = dept
 		}
+		if err := s.audit(ctx, domain.AuditRestore, id, nil, restored); err != nil {
+			return err
+		}
+		return addEmployeeEvent(ctx, s.outboxRepo, domain.EventEmployeeRestored, restored, nil)
+	})
+}
+
+func (s *EmployeeService) GetCompanyEmployees(ctx context.Context, companyID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+
+	result, err := s.empRepo.GetByCompany(ctx, companyID, filter, normalizePage(page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employees: %w", err)
 	}
+	result.Company = company
 
-	return employees, nil
+	return result, nil
 }
 
-func (s *EmployeeService) GetDepartmentEmployees(companyID, deptId int) ([]*domain.Employee, error) {
-	employees, err := s.empRepo.GetByDepartment(companyID, deptId)
+func (s *EmployeeService) GetDepartmentEmployees(ctx context.Context, companyID, deptId int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+
+	result, err := s.empRepo.GetByDepartment(ctx, companyID, deptId, filter, normalizePage(page))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get employees: %w", err)
 	}
+	result.Company = company
 
-	for _, emp := range employees {
-		if emp.DepartmentID != nil {
-			dept, err := s.deptRepo.GetByID(*emp.DepartmentID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get department: %w", err)
-			}
-			emp.Department = dept
-		}
+	return result, nil
+}
+
+// ExportEmployees passes every employee of the company matching filter to fn
+// in ID order, without loading them all at once. An error returned by fn
+// stops the export and is returned as is.
+func (s *EmployeeService) ExportEmployees(ctx context.Context, companyID int, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return fmt.Errorf("failed to get company: %w", err)
+	}
+
+	var fnErr error
+	err := s.empRepo.StreamByCompany(ctx, companyID, filter, func(emp *domain.Employee) error {
+		fnErr = fn(emp)
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		return fmt.Errorf("failed to export employees: %w", err)
+	}
+	return err
+}
+
+func normalizePage(page domain.PageRequest) domain.PageRequest {
+	if page.Limit <= 0 {
+		page.Limit = domain.DefaultPageLimit
+	}
+	if page.Limit > domain.MaxPageLimit {
+		page.Limit = domain.MaxPageLimit
+	}
+	if page.SortBy == "" {
+		page.SortBy = "id"
+	}
+	return page
+}
+
+func (s *EmployeeService) SearchEmployees(ctx context.Context, companyID int, q string, limit int) ([]*domain.SearchResult, error) {
+	variants := searchVariants(q)
+	if len(variants) == 0 {
+		return nil, domain.NewValidationError("q", "search query must contain letters or digits")
+	}
+
+	if limit <= 0 {
+		limit = domain.DefaultSearchLimit
+	}
+	if limit > domain.MaxSearchLimit {
+		limit = domain.MaxSearchLimit
+	}
+
+	results, err := s.empRepo.Search(ctx, companyID, domain.SearchQuery{Variants: variants, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search employees: %w", err)
 	}
 
-	return employees, nil
+	return results, nil
 }
@@ -1,62 +1,366 @@
 package service_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/jsonpatch"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
 	"github.com/Hexes-rgb/employee-service/internal/service"
+	"github.com/Hexes-rgb/employee-service/internal/validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type EmployeeRepositoryMock struct {
 	mock.Mock
 }
 
-func (m *EmployeeRepositoryMock) Create(emp *domain.Employee) (int, error) {
-	args := m.Called(emp)
+func (m *EmployeeRepositoryMock) Create(ctx context.Context, emp *domain.Employee) (int, error) {
+	args := m.Called(ctx, emp)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *EmployeeRepositoryMock) GetByID(id int) (*domain.Employee, error) {
-	args := m.Called(id)
+func (m *EmployeeRepositoryMock) GetByID(ctx context.Context, id int) (*domain.Employee, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Employee), args.Error(1)
 }
 
-func (m *EmployeeRepositoryMock) Update(emp *domain.Employee) error {
-	args := m.Called(emp)
+func (m *EmployeeRepositoryMock) Update(ctx context.Context, emp *domain.Employee) error {
+	args := m.Called(ctx, emp)
 	return args.Error(0)
 }
 
-func (m *EmployeeRepositoryMock) Delete(id int) error {
-	args := m.Called(id)
+func (m *EmployeeRepositoryMock) Delete(ctx context.Context, id, version int) error {
+	args := m.Called(ctx, id, version)
 	return args.Error(0)
 }
 
-func (m *EmployeeRepositoryMock) GetByCompany(companyID int) ([]*domain.Employee, error) {
-	args := m.Called(companyID)
+func (m *EmployeeRepositoryMock) Restore(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *EmployeeRepositoryMock) GetByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	args := m.Called(ctx, companyID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmployeePage), args.Error(1)
+}
+
+func (m *EmployeeRepositoryMock) GetByDepartment(ctx context.Context, companyID, deptID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	args := m.Called(ctx, companyID, deptID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmployeePage), args.Error(1)
+}
+
+func (m *EmployeeRepositoryMock) Search(ctx context.Context, companyID int, query domain.SearchQuery) ([]*domain.SearchResult, error) {
+	args := m.Called(ctx, companyID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SearchResult), args.Error(1)
+}
+
+func (m *EmployeeRepositoryMock) StreamByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error {
+	args := m.Called(ctx, companyID, filter, fn)
+	return args.Error(0)
+}
+
+func (m *EmployeeRepositoryMock) GetDirectReports(ctx context.Context, managerID int) ([]*domain.Employee, error) {
+	args := m.Called(ctx, managerID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.Employee), args.Error(1)
 }
 
-func (m *EmployeeRepositoryMock) GetByDepartment(companyID, deptID int) ([]*domain.Employee, error) {
-	args := m.Called(companyID, deptID)
+func (m *EmployeeRepositoryMock) GetManagementChain(ctx context.Context, id int) ([]*domain.Employee, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.Employee), args.Error(1)
 }
 
+func (m *EmployeeRepositoryMock) GetSubordinates(ctx context.Context, managerID int) ([]*domain.Subordinate, error) {
+	args := m.Called(ctx, managerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Subordinate), args.Error(1)
+}
+
+func (m *EmployeeRepositoryMock) GetManagerIDs(ctx context.Context, id int) ([]int, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *EmployeeRepositoryMock) LockReportingLines(ctx context.Context, companyID int) error {
+	args := m.Called(ctx, companyID)
+	return args.Error(0)
+}
+
+// inlineTx runs the unit of work directly, without a transaction.
+type inlineTx struct{}
+
+func (inlineTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type txKey struct{}
+
+// recordingTx marks the context passed to the unit of work, so tests can check
+// which repository calls ran inside it, and records the outcome.
+type recordingTx struct {
+	calls      int
+	rolledBack bool
+}
+
+func (tx *recordingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx.calls++
+	err := fn(context.WithValue(ctx, txKey{}, tx))
+	tx.rolledBack = err != nil
+	return err
+}
+
+func inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+// auditLog is an in-memory AuditRepository.
+type auditLog struct {
+	entries []*domain.AuditEntry
+}
+
+func (l *auditLog) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.ID = len(l.entries) + 1
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *auditLog) Last(ctx context.Context, employeeID int) (*domain.AuditEntry, error) {
+	entries, _ := l.ListByEmployee(ctx, employeeID)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[len(entries)-1], nil
+}
+
+func (l *auditLog) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.AuditEntry, error) {
+	entries := []*domain.AuditEntry{}
+	for _, e := range l.entries {
+		if e.EmployeeID == employeeID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// eventLog is an in-memory OutboxRepository.
+type eventLog struct {
+	events []*domain.Event
+}
+
+func (l *eventLog) Add(ctx context.Context, event *domain.Event) error {
+	event.ID = int64(len(l.events) + 1)
+	l.events = append(l.events, event)
+	return nil
+}
+
+func (l *eventLog) types() []domain.EventType {
+	types := make([]domain.EventType, len(l.events))
+	for i, e := range l.events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+// documentLog is an in-memory DocumentRepository.
+type documentLog struct {
+	docs []*domain.IdentityDocument
+}
+
+func (l *documentLog) Create(ctx context.Context, doc *domain.IdentityDocument) (int, error) {
+	for _, stored := range l.docs {
+		if stored.Type == doc.Type && stored.Number == doc.Number {
+			return 0, domain.NewConflictError("document", "number")
+		}
+	}
+	c := *doc
+	c.ID = len(l.docs) + 1
+	l.docs = append(l.docs, &c)
+	return c.ID, nil
+}
+
+func (l *documentLog) GetByID(ctx context.Context, id int) (*domain.IdentityDocument, error) {
+	for _, stored := range l.docs {
+		if stored.ID == id {
+			c := *stored
+			return &c, nil
+		}
+	}
+	return nil, domain.NewNotFoundError("document")
+}
+
+func (l *documentLog) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.IdentityDocument, error) {
+	var docs []*domain.IdentityDocument
+	for _, stored := range l.docs {
+		if stored.EmployeeID == employeeID {
+			c := *stored
+			docs = append(docs, &c)
+		}
+	}
+	return docs, nil
+}
+
+func (l *documentLog) Update(ctx context.Context, doc *domain.IdentityDocument) error {
+	for i, stored := range l.docs {
+		if stored.ID == doc.ID {
+			c := *doc
+			l.docs[i] = &c
+			return nil
+		}
+	}
+	return domain.NewNotFoundError("document")
+}
+
+func (l *documentLog) Delete(ctx context.Context, id int) error {
+	return errors.New("not implemented")
+}
+
+func (l *documentLog) ListExpiring(ctx context.Context, companyID int, from, to domain.Date) ([]*domain.ExpiringDocument, error) {
+	return nil, errors.New("not implemented")
+}
+
+// assignmentLog is an in-memory AssignmentRepository.
+type assignmentLog struct {
+	assignments []*domain.Assignment
+}
+
+func (l *assignmentLog) Create(ctx context.Context, a *domain.Assignment) (int, error) {
+	c := *a
+	c.ID = len(l.assignments) + 1
+	l.assignments = append(l.assignments, &c)
+	return c.ID, nil
+}
+
+func (l *assignmentLog) Update(ctx context.Context, a *domain.Assignment) error {
+	for i, stored := range l.assignments {
+		if stored.ID == a.ID {
+			c := *a
+			l.assignments[i] = &c
+			return nil
+		}
+	}
+	return domain.NewNotFoundError("assignment")
+}
+
+func (l *assignmentLog) Delete(ctx context.Context, id int) error {
+	for i, stored := range l.assignments {
+		if stored.ID == id {
+			l.assignments = append(l.assignments[:i], l.assignments[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NewNotFoundError("assignment")
+}
+
+func (l *assignmentLog) Last(ctx context.Context, employeeID int) (*domain.Assignment, error) {
+	var last *domain.Assignment
+	for _, a := range l.assignments {
+		if a.EmployeeID == employeeID && (last == nil || a.StartDate.After(last.StartDate.Time)) {
+			c := *a
+			last = &c
+		}
+	}
+	return last, nil
+}
+
+func (l *assignmentLog) ListByEmployee(ctx context.Context, employeeID int) ([]*domain.Assignment, error) {
+	assignments := []*domain.Assignment{}
+	for _, a := range l.assignments {
+		if a.EmployeeID == employeeID {
+			c := *a
+			assignments = append(assignments, &c)
+		}
+	}
+	return assignments, nil
+}
+
+func (l *assignmentLog) ListDue(ctx context.Context, day domain.Date, limit int) ([]*domain.Assignment, error) {
+	due := []*domain.Assignment{}
+	for _, a := range l.assignments {
+		if a.EndDate == nil && !a.StartDate.After(day.Time) && len(due) < limit {
+			c := *a
+			due = append(due, &c)
+		}
+	}
+	return due, nil
+}
+
 func ptrInt(i int) *int {
 	return &i
 }
 
+func TestEmployeeService_Atomicity(t *testing.T) {
+	dept := &domain.Department{CompanyID: 1, Name: "Engineering", Phone: "+123456789"}
+
+	t.Run("Error: employee create failure rolls back department upsert", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		tx := &recordingTx{}
+
+		deptRepo.On("GetOrCreate", mock.MatchedBy(inTx), dept).Return(42, true, nil)
+		empRepo.On("Create", mock.MatchedBy(inTx), mock.AnythingOfType("*domain.Employee")).
+			Return(0, domain.NewConflictError("employee", "phone"))
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, tx)
+		_, err := svc.CreateEmployee(context.Background(), &domain.Employee{CompanyID: 1, Department: dept})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Equal(t, 1, tx.calls)
+		assert.True(t, tx.rolledBack)
+		empRepo.AssertExpectations(t)
+		deptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success: update runs department upsert and employee write in one transaction", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		tx := &recordingTx{}
+
+		empRepo.On("GetByID", mock.MatchedBy(inTx), 1).Return(storedEmployee(), nil)
+		deptRepo.On("GetOrCreate", mock.MatchedBy(inTx), dept).Return(42, true, nil)
+		empRepo.On("Update", mock.MatchedBy(inTx), mock.AnythingOfType("*domain.Employee")).Return(nil)
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, tx)
+		_, err := svc.UpdateEmployee(context.Background(), 1, 0,
+			mergePatch(t, `{"department":{"companyId":1,"name":"Engineering","phone":"+123456789"}}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, tx.calls)
+		assert.False(t, tx.rolledBack)
+		empRepo.AssertExpectations(t)
+		deptRepo.AssertExpectations(t)
+	})
+}
+
 func TestEmployeeService_CreateEmployee(t *testing.T) {
 	t.Run("Success: Creating an employee with a department", func(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
@@ -78,10 +382,10 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 			Department:     inputDept,
 		}
 
-		deptRepo.On("GetOrCreate", inputDept).Return(42, nil)
+		deptRepo.On("GetOrCreate", mock.Anything, inputDept).Return(42, true, nil)
 
-		empRepo.On("Create", mock.AnythingOfType("*domain.Employee")).Return(100, nil).Run(func(args mock.Arguments) {
-			emp := args.Get(0).(*domain.Employee)
+		empRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(100, nil).Run(func(args mock.Arguments) {
+			emp := args.Get(1).(*domain.Employee)
 			assert.Equal(t, "John", emp.Name)
 			assert.Equal(t, "Doe", emp.Surname)
 			assert.Equal(t, "+79998887766", emp.Phone)
@@ -92,8 +396,8 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 			assert.Equal(t, inputDept, emp.Department)
 		})
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		id, err := svc.CreateEmployee(expectedEmployee)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		id, err := svc.CreateEmployee(context.Background(), expectedEmployee)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 100, id)
@@ -111,10 +415,10 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 			CompanyID: 1,
 		}
 
-		empRepo.On("Create", inputEmployee).Return(101, nil)
+		empRepo.On("Create", mock.Anything, inputEmployee).Return(101, nil)
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		id, err := svc.CreateEmployee(inputEmployee)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		id, err := svc.CreateEmployee(context.Background(), inputEmployee)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 101, id)
@@ -138,10 +442,10 @@ func TestEmployeeService_CreateEmployee(t *testing.T) {
 			Department: inputDept,
 		}
 
-		deptRepo.On("GetOrCreate", inputDept).Return(0, errors.New("db error"))
+		deptRepo.On("GetOrCreate", mock.Anything, inputDept).Return(0, false, errors.New("db error"))
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		_, err := svc.CreateEmployee(inputEmployee)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.CreateEmployee(context.Background(), inputEmployee)
 
 		assert.EqualError(t, err, "failed to get or create department: db error")
 		deptRepo.AssertExpectations(t)
@@ -153,24 +457,23 @@ func TestEmployeeService_GetEmployee(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
 		deptRepo := new(DepartmentRepositoryMock)
 
-		empRepo.On("GetByID", 1).Return(&domain.Employee{
+		empRepo.On("GetByID", mock.Anything, 1).Return(&domain.Employee{
 			ID:           1,
 			Name:         "John",
 			Surname:      "Doe",
 			Phone:        "+79998887766",
 			CompanyID:    1,
 			DepartmentID: ptrInt(42),
+			Department: &domain.Department{
+				ID:        42,
+				CompanyID: 1,
+				Name:      "Engineering",
+				Phone:     "+123456789",
+			},
 		}, nil)
 
-		deptRepo.On("GetByID", 42).Return(&domain.Department{
-			ID:        42,
-			CompanyID: 1,
-			Name:      "Engineering",
-			Phone:     "+123456789",
-		}, nil)
-
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		emp, err := svc.GetEmployee(1)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		emp, err := svc.GetEmployee(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.Equal(t, &domain.Employee{
@@ -188,23 +491,46 @@ func TestEmployeeService_GetEmployee(t *testing.T) {
 			},
 		}, emp)
 		empRepo.AssertExpectations(t)
-		deptRepo.AssertExpectations(t)
+		deptRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Error: employee not found", func(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
 		deptRepo := new(DepartmentRepositoryMock)
 
-		empRepo.On("GetByID", 999).Return(nil, errors.New("not found"))
+		empRepo.On("GetByID", mock.Anything, 999).Return(nil, errors.New("not found"))
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		_, err := svc.GetEmployee(999)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.GetEmployee(context.Background(), 999)
 
 		assert.EqualError(t, err, "failed to get employee: not found")
 		empRepo.AssertExpectations(t)
 	})
 }
 
+// storedEmployee is the employee the update tests start from.
+func storedEmployee() *domain.Employee {
+	return &domain.Employee{
+		ID:             1,
+		Name:           "John",
+		Surname:        "Doe",
+		Phone:          "+79998887766",
+		CompanyID:      1,
+		DepartmentID:   ptrInt(42),
+		PassportType:   "internal",
+		PassportNumber: "1234567890",
+		Department:     &domain.Department{ID: 42, CompanyID: 1, Name: "Engineering"},
+		Version:        3,
+	}
+}
+
+func mergePatch(t *testing.T, body string) domain.Patch {
+	t.Helper()
+	patch, err := jsonpatch.NewMergePatch([]byte(body))
+	require.NoError(t, err)
+	return patch
+}
+
 func TestEmployeeService_UpdateEmployee(t *testing.T) {
 	t.Run("Success: Employee update with department", func(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
@@ -216,25 +542,138 @@ func TestEmployeeService_UpdateEmployee(t *testing.T) {
 			Phone:     "+987654321",
 		}
 
-		deptRepo.On("GetOrCreate", newDept).Return(43, nil)
-		empRepo.On("Update", mock.AnythingOfType("*domain.Employee")).Return(nil).Run(func(args mock.Arguments) {
-			emp := args.Get(0).(*domain.Employee)
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		deptRepo.On("GetOrCreate", mock.Anything, newDept).Return(43, true, nil)
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil).Run(func(args mock.Arguments) {
+			emp := args.Get(1).(*domain.Employee)
 			assert.Equal(t, 1, emp.ID)
 			assert.Equal(t, 43, *emp.DepartmentID)
 			assert.Equal(t, newDept, emp.Department)
+			assert.Equal(t, "John", emp.Name)
 		})
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		err := svc.UpdateEmployee(&domain.Employee{
-			ID:         1,
-			Department: newDept,
-		})
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 1, 3,
+			mergePatch(t, `{"department":{"companyId":1,"name":"New Department","phone":"+987654321"}}`))
 
 		assert.NoError(t, err)
 		empRepo.AssertExpectations(t)
 		deptRepo.AssertExpectations(t)
 	})
 
+	t.Run("Success: null clears nullable fields", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil).Run(func(args mock.Arguments) {
+			emp := args.Get(1).(*domain.Employee)
+			assert.Nil(t, emp.DepartmentID)
+			assert.Empty(t, emp.PassportType)
+			assert.Equal(t, "Doe", emp.Surname)
+			assert.Equal(t, 3, emp.Version)
+		})
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 1, 3, mergePatch(t, `{"departmentId":null,"passportType":null}`))
+
+		assert.NoError(t, err)
+		empRepo.AssertExpectations(t)
+		deptRepo.AssertNotCalled(t, "GetOrCreate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: JSON Patch with passing test", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil).Run(func(args mock.Arguments) {
+			assert.Equal(t, "Doe-Smith", args.Get(1).(*domain.Employee).Surname)
+		})
+
+		patch, err := jsonpatch.NewJSONPatch([]byte(`[
+			{"op":"test","path":"/surname","value":"Doe"},
+			{"op":"replace","path":"/surname","value":"Doe-Smith"}
+		]`))
+		require.NoError(t, err)
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err = svc.UpdateEmployee(context.Background(), 1, 0, patch)
+
+		assert.NoError(t, err)
+		empRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name          string
+		version       int
+		patch         func(t *testing.T) domain.Patch
+		expectedErr   error
+		expectedField string
+	}{
+		{
+			name:    "Error: failed JSON Patch test is a conflict",
+			version: 3,
+			patch: func(t *testing.T) domain.Patch {
+				patch, err := jsonpatch.NewJSONPatch([]byte(`[{"op":"test","path":"/surname","value":"Roe"}]`))
+				require.NoError(t, err)
+				return patch
+			},
+			expectedErr: domain.ErrConflict,
+		},
+		{
+			name:          "Error: clearing a required field",
+			version:       3,
+			patch:         func(t *testing.T) domain.Patch { return mergePatch(t, `{"name":null}`) },
+			expectedErr:   domain.ErrValidation,
+			expectedField: "name",
+		},
+		{
+			name:          "Error: too long",
+			version:       3,
+			patch:         func(t *testing.T) domain.Patch { return mergePatch(t, `{"phone":"+7 999 000-00-00 ext. 42"}`) },
+			expectedErr:   domain.ErrValidation,
+			expectedField: "phone",
+		},
+		{
+			name:          "Error: wrong type",
+			version:       3,
+			patch:         func(t *testing.T) domain.Patch { return mergePatch(t, `{"companyId":"one"}`) },
+			expectedErr:   domain.ErrValidation,
+			expectedField: "companyId",
+		},
+		{
+			name:        "Error: read-only field",
+			version:     3,
+			patch:       func(t *testing.T) domain.Patch { return mergePatch(t, `{"version":7}`) },
+			expectedErr: domain.ErrValidation,
+		},
+		{
+			name:        "Error: stale version",
+			version:     2,
+			patch:       func(t *testing.T) domain.Patch { return mergePatch(t, `{"name":"Jack"}`) },
+			expectedErr: domain.ErrVersionMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			empRepo := new(EmployeeRepositoryMock)
+			deptRepo := new(DepartmentRepositoryMock)
+
+			empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+
+			svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+			_, err := svc.UpdateEmployee(context.Background(), 1, tt.version, tt.patch(t))
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedField != "" {
+				assert.Equal(t, tt.expectedField, violatedField(t, err))
+			}
+			empRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+
 	t.Run("Error: Failed to update department", func(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
 		deptRepo := new(DepartmentRepositoryMock)
@@ -245,13 +684,12 @@ func TestEmployeeService_UpdateEmployee(t *testing.T) {
 			Phone:     "+1122334455",
 		}
 
-		deptRepo.On("GetOrCreate", newDept).Return(0, errors.New("db error"))
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		deptRepo.On("GetOrCreate", mock.Anything, newDept).Return(0, false, errors.New("db error"))
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		err := svc.UpdateEmployee(&domain.Employee{
-			ID:         1,
-			Department: newDept,
-		})
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 1, 0,
+			mergePatch(t, `{"department":{"companyId":1,"name":"Finance","phone":"+1122334455"}}`))
 
 		assert.EqualError(t, err, "failed to get or create department: db error")
 		deptRepo.AssertExpectations(t)
@@ -263,10 +701,11 @@ func TestEmployeeService_DeleteEmployee(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
 		deptRepo := new(DepartmentRepositoryMock)
 
-		empRepo.On("Delete", 1).Return(nil)
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("Delete", mock.Anything, 1, 3).Return(nil)
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		err := svc.DeleteEmployee(1)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		err := svc.DeleteEmployee(context.Background(), 1, 3)
 
 		assert.NoError(t, err)
 		empRepo.AssertExpectations(t)
@@ -276,36 +715,231 @@ func TestEmployeeService_DeleteEmployee(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
 		deptRepo := new(DepartmentRepositoryMock)
 
-		empRepo.On("Delete", 999).Return(errors.New("db error"))
+		empRepo.On("GetByID", mock.Anything, 999).Return(storedEmployee(), nil)
+		empRepo.On("Delete", mock.Anything, 999, 0).Return(errors.New("db error"))
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		err := svc.DeleteEmployee(999)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		err := svc.DeleteEmployee(context.Background(), 999, 0)
 
 		assert.EqualError(t, err, "failed to delete employee: db error")
 		empRepo.AssertExpectations(t)
 	})
 }
 
+func TestEmployeeService_Rehire(t *testing.T) {
+	ctx := context.Background()
+	svc, companyID := newMemoryEmployeeService(t)
+	hire := func(phone string) (int, error) {
+		return svc.CreateEmployee(ctx, &domain.Employee{
+			Name: "John", Surname: "Doe", Phone: phone, CompanyID: companyID,
+			PassportType: "internal", PassportNumber: "1234567890",
+		})
+	}
+
+	id, err := hire("+79990000001")
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteEmployee(ctx, id, 0))
+
+	t.Run("Success: rehired person reuses their passport", func(t *testing.T) {
+		rehiredID, err := hire("+79990000002")
+		require.NoError(t, err)
+		assert.NotEqual(t, id, rehiredID)
+	})
+
+	t.Run("Error: restore while the passport is taken", func(t *testing.T) {
+		assert.ErrorIs(t, svc.RestoreEmployee(ctx, id), domain.ErrConflict)
+	})
+}
+
+// newMemoryEmployeeService returns an EmployeeService backed by the memory
+// repositories and the ID of an active company in them.
+func newMemoryEmployeeService(tb testing.TB) (*service.EmployeeService, int) {
+	tb.Helper()
+
+	store := memory.NewStore()
+	companyRepo := memory.NewCompanyRepo(store)
+	companyID, err := companyRepo.Create(context.Background(), &domain.Company{
+		LegalName: "Acme LLC", TaxID: "7701234567", DefaultCountry: "RU", Active: true,
+	})
+	require.NoError(tb, err)
+
+	svc := service.NewEmployeeService(memory.NewEmployeeRepo(store), memory.NewDepartmentRepo(store), companyRepo,
+		memory.NewAuditRepo(store), memory.NewOutboxRepo(store), memory.NewAssignmentRepo(store), memory.NewDocumentRepo(store), store)
+	return svc, companyID
+}
+
+func TestEmployeeService_Audit(t *testing.T) {
+	empRepo := new(EmployeeRepositoryMock)
+	deptRepo := new(DepartmentRepositoryMock)
+	audit := &auditLog{}
+
+	empRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(1, nil)
+	empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+	empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil)
+	empRepo.On("Delete", mock.Anything, 1, 0).Return(nil)
+	deptRepo.On("GetByID", mock.Anything, 42).Return(storedEmployee().Department, nil)
+
+	svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), audit, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+	ctx := domain.WithRequestID(domain.WithActor(context.Background(), "alice"), "req-1")
+
+	stored := storedEmployee()
+	stored.ID, stored.Version, stored.Department = 0, 0, nil
+	_, err := svc.CreateEmployee(ctx, stored)
+	require.NoError(t, err)
+	_, err = svc.UpdateEmployee(ctx, 1, 0, mergePatch(t, `{"phone":"+70000000000"}`))
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteEmployee(context.Background(), 1, 0))
+
+	history, err := svc.GetEmployeeHistory(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	t.Run("Success: entries record action, actor and request", func(t *testing.T) {
+		assert.Equal(t, domain.AuditCreate, history[0].Action)
+		assert.Len(t, history[0].Changes, 7)
+		assert.Equal(t, "alice", history[1].Actor)
+		assert.Equal(t, "req-1", history[1].RequestID)
+		assert.Equal(t, []domain.FieldChange{{
+			Field:  "phone",
+			Before: json.RawMessage(`"+79998887766"`),
+			After:  json.RawMessage(`"+70000000000"`),
+		}}, history[1].Changes)
+		assert.Equal(t, domain.AuditDelete, history[2].Action)
+		assert.Equal(t, domain.SystemActor, history[2].Actor)
+		assert.JSONEq(t, `null`, string(history[2].Changes[0].After))
+	})
+
+	t.Run("Success: chain verifies", func(t *testing.T) {
+		assert.NoError(t, domain.VerifyAuditChain(history))
+	})
+
+	t.Run("Error: altered entry breaks the chain", func(t *testing.T) {
+		tampered := *history[1]
+		tampered.Actor = "mallory"
+		err := domain.VerifyAuditChain([]*domain.AuditEntry{history[0], &tampered, history[2]})
+		assert.ErrorIs(t, err, domain.ErrAuditChainBroken)
+	})
+
+	t.Run("Error: removed entry breaks the chain", func(t *testing.T) {
+		err := domain.VerifyAuditChain([]*domain.AuditEntry{history[0], history[2]})
+		assert.ErrorIs(t, err, domain.ErrAuditChainBroken)
+	})
+
+	t.Run("Error: history of unknown employee", func(t *testing.T) {
+		empRepo.On("GetByID", mock.Anything, 999).Return(nil, domain.NewNotFoundError("employee"))
+		_, err := svc.GetEmployeeHistory(ctx, 999)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestEmployeeService_Events(t *testing.T) {
+	dept := &domain.Department{CompanyID: 1, Name: "Engineering", Phone: "+123456789"}
+
+	t.Run("Success: writes record events in the transaction", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		events := &eventLog{}
+
+		deptRepo.On("GetOrCreate", mock.Anything, dept).Return(42, true, nil)
+		empRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(1, nil)
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil)
+		empRepo.On("Delete", mock.Anything, 1, 0).Return(nil)
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, events, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.CreateEmployee(context.Background(), &domain.Employee{CompanyID: 1, Name: "John", Department: dept})
+		require.NoError(t, err)
+		_, err = svc.UpdateEmployee(context.Background(), 1, 0, mergePatch(t, `{"phone":"+70000000000"}`))
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteEmployee(context.Background(), 1, 0))
+
+		assert.Equal(t, []domain.EventType{
+			domain.EventDepartmentCreated,
+			domain.EventEmployeeCreated,
+			domain.EventEmployeeUpdated,
+			domain.EventEmployeeDeleted,
+		}, events.types())
+
+		assert.Equal(t, "department:42", events.events[0].Key())
+		assert.Equal(t, "employee:1", events.events[1].Key())
+		assert.JSONEq(t, `"John"`, string(mustJSONField(t, events.events[1].Payload, "employee", "name")))
+
+		var updated domain.EmployeeEventPayload
+		require.NoError(t, json.Unmarshal(events.events[2].Payload, &updated))
+		require.Len(t, updated.Changes, 1)
+		assert.Equal(t, "phone", updated.Changes[0].Field)
+	})
+
+	t.Run("Success: existing department and no-op update record nothing extra", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		events := &eventLog{}
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(nil)
+		deptRepo.On("GetOrCreate", mock.Anything, mock.Anything).Return(42, false, nil)
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, events, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 1, 0, mergePatch(t, `{}`))
+		require.NoError(t, err)
+
+		assert.Empty(t, events.events)
+	})
+
+	t.Run("Error: events of a failed write are rolled back with it", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		events := &eventLog{}
+		tx := &recordingTx{}
+
+		deptRepo.On("GetOrCreate", mock.Anything, dept).Return(42, true, nil)
+		empRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Employee")).
+			Return(0, domain.NewConflictError("employee", "phone"))
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, events, &assignmentLog{}, &documentLog{}, tx)
+		_, err := svc.CreateEmployee(context.Background(), &domain.Employee{CompanyID: 1, Department: dept})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		// The department event was written in the transaction that was
+		// rolled back, so it is never relayed.
+		assert.True(t, tx.rolledBack)
+		assert.Equal(t, 1, tx.calls)
+		assert.Equal(t, []domain.EventType{domain.EventDepartmentCreated}, events.types())
+	})
+}
+
+func mustJSONField(t *testing.T, doc json.RawMessage, path ...string) json.RawMessage {
+	t.Helper()
+	for _, key := range path {
+		var obj map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(doc, &obj))
+		doc = obj[key]
+	}
+	return doc
+}
+
 func TestEmployeeService_GetCompanyEmployees(t *testing.T) {
 	t.Run("Success: Getting employees of a company with departments", func(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
 		deptRepo := new(DepartmentRepositoryMock)
 
-		empRepo.On("GetByCompany", 1).Return([]*domain.Employee{
-			{ID: 1, Name: "John", CompanyID: 1, DepartmentID: ptrInt(42)},
-			{ID: 2, Name: "Jane", CompanyID: 1},
-		}, nil)
-
-		deptRepo.On("GetByID", 42).Return(&domain.Department{
-			ID:        42,
-			CompanyID: 1,
-			Name:      "Engineering",
+		empRepo.On("GetByCompany", mock.Anything, 1, domain.EmployeeFilter{}, domain.PageRequest{Limit: domain.DefaultPageLimit, SortBy: "id"}).Return(&domain.EmployeePage{
+			Items: []*domain.Employee{
+				{
+					ID: 1, Name: "John", CompanyID: 1, DepartmentID: ptrInt(42),
+					Department: &domain.Department{ID: 42, CompanyID: 1, Name: "Engineering"},
+				},
+				{ID: 2, Name: "Jane", CompanyID: 1},
+			},
+			NextCursor: "next",
 		}, nil)
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		employees, err := svc.GetCompanyEmployees(1)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		page, err := svc.GetCompanyEmployees(context.Background(), 1, domain.EmployeeFilter{}, domain.PageRequest{})
 
 		assert.NoError(t, err)
+		assert.Equal(t, "next", page.NextCursor)
+		assert.Equal(t, "Acme LLC", page.Company.LegalName)
 		assert.Equal(t, []*domain.Employee{
 			{
 				ID:           1,
@@ -323,9 +957,27 @@ func TestEmployeeService_GetCompanyEmployees(t *testing.T) {
 				Name:      "Jane",
 				CompanyID: 1,
 			},
-		}, employees)
+		}, page.Items)
 		empRepo.AssertExpectations(t)
-		deptRepo.AssertExpectations(t)
+		deptRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: Empty page is not an error", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		empRepo.On("GetByCompany", mock.Anything, 7, domain.EmployeeFilter{NoDepartment: true}, domain.PageRequest{Limit: domain.MaxPageLimit, IncludeTotal: true, SortBy: "id"}).
+			Return(&domain.EmployeePage{Items: []*domain.Employee{}}, nil)
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		page, err := svc.GetCompanyEmployees(context.Background(), 7, domain.EmployeeFilter{NoDepartment: true}, domain.PageRequest{Limit: 10000, IncludeTotal: true})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.Items)
+		assert.Empty(t, page.NextCursor)
+		empRepo.AssertExpectations(t)
+		deptRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
 	})
 }
 
@@ -334,18 +986,17 @@ func TestEmployeeService_GetDepartmentEmployees(t *testing.T) {
 		empRepo := new(EmployeeRepositoryMock)
 		deptRepo := new(DepartmentRepositoryMock)
 
-		empRepo.On("GetByDepartment", 1, 42).Return([]*domain.Employee{
-			{ID: 1, Name: "John", CompanyID: 1, DepartmentID: ptrInt(42)},
-		}, nil)
-
-		deptRepo.On("GetByID", 42).Return(&domain.Department{
-			ID:        42,
-			CompanyID: 1,
-			Name:      "Engineering",
+		empRepo.On("GetByDepartment", mock.Anything, 1, 42, domain.EmployeeFilter{SurnamePrefix: "Do"}, domain.PageRequest{Limit: 10, SortBy: "surname", SortDesc: true}).Return(&domain.EmployeePage{
+			Items: []*domain.Employee{
+				{
+					ID: 1, Name: "John", CompanyID: 1, DepartmentID: ptrInt(42),
+					Department: &domain.Department{ID: 42, CompanyID: 1, Name: "Engineering"},
+				},
+			},
 		}, nil)
 
-		svc := service.NewEmployeeService(empRepo, deptRepo)
-		employees, err := svc.GetDepartmentEmployees(1, 42)
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		page, err := svc.GetDepartmentEmployees(context.Background(), 1, 42, domain.EmployeeFilter{SurnamePrefix: "Do"}, domain.PageRequest{Limit: 10, SortBy: "surname", SortDesc: true})
 
 		assert.NoError(t, err)
 		assert.Equal(t, []*domain.Employee{
@@ -360,8 +1011,268 @@ func TestEmployeeService_GetDepartmentEmployees(t *testing.T) {
 					Name:      "Engineering",
 				},
 			},
-		}, employees)
+		}, page.Items)
+		empRepo.AssertExpectations(t)
+		deptRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestEmployeeService_DomainErrors(t *testing.T) {
+	t.Run("Error: conflict survives wrapping", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		inputEmployee := &domain.Employee{Name: "John", CompanyID: 1, Phone: "+79998887766"}
+		empRepo.On("Create", mock.Anything, inputEmployee).Return(0, domain.NewConflictError("employee", "phone"))
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.CreateEmployee(context.Background(), inputEmployee)
+
+		var conflictErr *domain.ConflictError
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "phone", conflictErr.Field)
+		assert.EqualError(t, err, "failed to create employee: employee with this phone already exists")
+	})
+
+	t.Run("Error: not found survives wrapping", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 999).Return(nil, domain.NewNotFoundError("employee"))
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 999, 0, mergePatch(t, `{"name":"John"}`))
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.NotErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("Error: unavailable keeps the cause", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		cause := errors.New("connection refused")
+		empRepo.On("GetByID", mock.Anything, 1).Return(nil, domain.NewUnavailableError(cause))
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		err := svc.DeleteEmployee(context.Background(), 1, 0)
+
+		assert.ErrorIs(t, err, domain.ErrUnavailable)
+		assert.ErrorIs(t, err, cause)
+	})
+
+	t.Run("Error: concurrent write is a typed conflict", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Employee")).Return(domain.NewVersionConflictError("employee", 3))
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.UpdateEmployee(context.Background(), 1, 0, mergePatch(t, `{"name":"Jack"}`))
+
+		var versionErr *domain.VersionConflictError
+		assert.ErrorAs(t, err, &versionErr)
+		assert.Equal(t, 3, versionErr.Expected)
+		assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+func TestEmployeeService_ContextPropagation(t *testing.T) {
+	t.Run("Success: request context reaches writes", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+		hasRequestCtx := mock.MatchedBy(func(c context.Context) bool {
+			return c.Value(ctxKey{}) == "request"
+		})
+
+		dept := &domain.Department{CompanyID: 1, Name: "Engineering"}
+		deptRepo.On("GetOrCreate", hasRequestCtx, dept).Return(42, true, nil)
+		empRepo.On("Create", hasRequestCtx, mock.AnythingOfType("*domain.Employee")).Return(1, nil)
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.CreateEmployee(ctx, &domain.Employee{CompanyID: 1, Department: dept})
+
+		assert.NoError(t, err)
 		empRepo.AssertExpectations(t)
 		deptRepo.AssertExpectations(t)
 	})
+
+	t.Run("Success: request context reaches reads", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+		hasRequestCtx := mock.MatchedBy(func(c context.Context) bool {
+			return c.Value(ctxKey{}) == "request"
+		})
+
+		empRepo.On("GetByID", hasRequestCtx, 1).Return(storedEmployee(), nil)
+
+		svc := service.NewEmployeeService(empRepo, new(DepartmentRepositoryMock), activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.GetEmployee(ctx, 1)
+
+		assert.NoError(t, err)
+		empRepo.AssertExpectations(t)
+	})
+}
+
+func TestEmployeeService_SearchEmployees(t *testing.T) {
+	tests := []struct {
+		name     string
+		q        string
+		limit    int
+		expected domain.SearchQuery
+	}{
+		{
+			name:     "Success: Cyrillic query is also searched in Latin",
+			q:        "Иванов",
+			expected: domain.SearchQuery{Variants: []string{"иванов", "ivanov"}, Limit: domain.DefaultSearchLimit},
+		},
+		{
+			name:     "Success: Latin query is also searched in Cyrillic",
+			q:        "Shchukin Ivan",
+			limit:    5,
+			expected: domain.SearchQuery{Variants: []string{"shchukin ivan", "щукин иван"}, Limit: 5},
+		},
+		{
+			name:     "Success: tsquery syntax is stripped and digits kept",
+			q:        "+7 (999) & !",
+			limit:    1000,
+			expected: domain.SearchQuery{Variants: []string{"7 999"}, Limit: domain.MaxSearchLimit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			empRepo := new(EmployeeRepositoryMock)
+			deptRepo := new(DepartmentRepositoryMock)
+
+			results := []*domain.SearchResult{{Employee: &domain.Employee{ID: 1}, Rank: 0.5}}
+			empRepo.On("Search", mock.Anything, 1, tt.expected).Return(results, nil)
+
+			svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+			got, err := svc.SearchEmployees(context.Background(), 1, tt.q, tt.limit)
+
+			assert.NoError(t, err)
+			assert.Equal(t, results, got)
+			empRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Error: query without words", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		svc := service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		_, err := svc.SearchEmployees(context.Background(), 1, "&|!", 0)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		empRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// countingEmployeeRepo, countingDepartmentRepo and countingCompanyRepo count
+// the repository calls an employee listing can make.
+type countingEmployeeRepo struct {
+	service.EmployeeRepository
+	calls *int
+}
+
+func (r countingEmployeeRepo) GetByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	*r.calls++
+	return r.EmployeeRepository.GetByCompany(ctx, companyID, filter, page)
+}
+
+func (r countingEmployeeRepo) GetByDepartment(ctx context.Context, companyID, deptID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error) {
+	*r.calls++
+	return r.EmployeeRepository.GetByDepartment(ctx, companyID, deptID, filter, page)
+}
+
+type countingDepartmentRepo struct {
+	service.DepartmentRepository
+	calls *int
+}
+
+func (r countingDepartmentRepo) GetByID(ctx context.Context, id int) (*domain.Department, error) {
+	*r.calls++
+	return r.DepartmentRepository.GetByID(ctx, id)
+}
+
+type countingCompanyRepo struct {
+	service.CompanyRepository
+	calls *int
+}
+
+func (r countingCompanyRepo) GetByID(ctx context.Context, id int) (*domain.Company, error) {
+	*r.calls++
+	return r.CompanyRepository.GetByID(ctx, id)
+}
+
+// BenchmarkEmployeeService_GetCompanyEmployees reports the repository calls
+// per listing against the memory repositories. Departments come joined from
+// the employee repository, so queries/op stays the same for every page size.
+func BenchmarkEmployeeService_GetCompanyEmployees(b *testing.B) {
+	for _, size := range []int{10, 100, 500} {
+		b.Run(fmt.Sprintf("employees=%d", size), func(b *testing.B) {
+			ctx := context.Background()
+			store := memory.NewStore()
+			companyRepo := memory.NewCompanyRepo(store)
+			companyID, err := companyRepo.Create(ctx, &domain.Company{
+				LegalName: "Acme LLC", TaxID: "7701234567", DefaultCountry: "RU", Active: true,
+			})
+			require.NoError(b, err)
+
+			var calls int
+			svc := service.NewEmployeeService(
+				countingEmployeeRepo{memory.NewEmployeeRepo(store), &calls},
+				countingDepartmentRepo{memory.NewDepartmentRepo(store), &calls},
+				countingCompanyRepo{companyRepo, &calls},
+				memory.NewAuditRepo(store), memory.NewOutboxRepo(store), memory.NewAssignmentRepo(store), memory.NewDocumentRepo(store), store)
+			for i := 0; i < size; i++ {
+				_, err := svc.CreateEmployee(ctx, &domain.Employee{
+					Name: "John", Phone: fmt.Sprintf("+7999%07d", i), CompanyID: companyID,
+					PassportNumber: strconv.Itoa(i),
+					Department: &domain.Department{
+						CompanyID: companyID, Name: fmt.Sprintf("Dept %d", i%10), Phone: fmt.Sprintf("+100%d", i%10),
+					},
+				})
+				require.NoError(b, err)
+			}
+
+			calls = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				page, err := svc.GetCompanyEmployees(ctx, companyID, domain.EmployeeFilter{}, domain.PageRequest{Limit: size})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(page.Items) != size || page.Items[size-1].Department == nil {
+					b.Fatalf("got %d employees, want %d with departments", len(page.Items), size)
+				}
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(calls)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+// violatedField returns the field named by a validation error, whether it
+// lists violations or names a single field.
+func violatedField(t *testing.T, err error) string {
+	t.Helper()
+
+	var violationsErr *validation.Error
+	if errors.As(err, &violationsErr) {
+		return validation.Dotted(violationsErr.Violations[0].Field)
+	}
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	return validationErr.Field
 }
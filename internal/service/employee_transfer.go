@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// TransferEmployee moves the employee to another department of their company
+// on the effective date of the transfer. A transfer effective today moves the
+// employee at once; a later one is recorded as a pending assignment and
+// carried out by ApplyDueTransfers when the day comes.
+func (s *EmployeeService) TransferEmployee(ctx context.Context, id int, transfer *domain.Transfer) (*domain.Assignment, error) {
+	today := domain.Today()
+	day := today
+	if transfer.EffectiveDate != nil {
+		day = *transfer.EffectiveDate
+	}
+	if day.Before(today.Time) {
+		return nil, domain.NewValidationError("effectiveDate", "effectiveDate cannot be in the past")
+	}
+
+	var assignment *domain.Assignment
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		emp, err := s.empRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get employee: %w", err)
+		}
+
+		dept, err := s.deptRepo.GetByID(ctx, transfer.DepartmentID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.NewValidationError("departmentId", fmt.Sprintf("department %d does not exist", transfer.DepartmentID))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get department: %w", err)
+		}
+		if dept.CompanyID != emp.CompanyID {
+			return domain.NewValidationError("departmentId", "department belongs to another company")
+		}
+
+		last, err := s.assignRepo.Last(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get assignments: %w", err)
+		}
+		if last != nil && last.EndDate == nil && last.DepartmentID == dept.ID {
+			return domain.NewValidationError("departmentId", "employee is already assigned to this department")
+		}
+
+		assignment, err = s.assign(ctx, id, &dept.ID, day)
+		if err != nil {
+			return err
+		}
+		if day.After(today.Time) {
+			return nil
+		}
+		return s.moveToDepartment(ctx, emp, dept.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+// GetEmployeeAssignments returns the department assignments of the employee,
+// oldest first, including a pending one.
+func (s *EmployeeService) GetEmployeeAssignments(ctx context.Context, id int) ([]*domain.Assignment, error) {
+	if _, err := s.empRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	assignments, err := s.assignRepo.ListByEmployee(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+// ApplyDueTransfers moves employees whose pending transfer has become
+// effective, up to limit of them, each in its own transaction. A transfer
+// into a department that is gone or no longer belongs to the employee's
+// company is cancelled; one that fails otherwise is left for the next call.
+// Either way the rest of the batch goes on, and the failures are returned
+// together. It returns how many employees were moved.
+func (s *EmployeeService) ApplyDueTransfers(ctx context.Context, limit int) (int, error) {
+	due, err := s.assignRepo.ListDue(ctx, domain.Today(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due transfers: %w", err)
+	}
+
+	applied := 0
+	var errs []error
+	for _, a := range due {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		var cancelled error
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			cancelled, err = s.applyTransfer(ctx, a)
+			return err
+		})
+		if err == nil {
+			err = cancelled
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to transfer employee %d: %w", a.EmployeeID, err))
+			continue
+		}
+		applied++
+	}
+	return applied, errors.Join(errs...)
+}
+
+// applyTransfer moves the employee into the department of the due
+// assignment a. If the department is gone or belongs to another company than
+// the employee's current one, the transfer is cancelled instead and the
+// reason is returned as cancelled.
+func (s *EmployeeService) applyTransfer(ctx context.Context, a *domain.Assignment) (cancelled, err error) {
+	emp, err := s.empRepo.GetByID(ctx, a.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+	if emp.DepartmentID != nil && *emp.DepartmentID == a.DepartmentID {
+		return nil, nil
+	}
+
+	dept, err := s.deptRepo.GetByID(ctx, a.DepartmentID)
+	if errors.Is(err, domain.ErrNotFound) {
+		cancelled = domain.NewValidationError("departmentId", fmt.Sprintf("department %d does not exist", a.DepartmentID))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get department: %w", err)
+	} else if dept.CompanyID != emp.CompanyID {
+		cancelled = domain.NewValidationError("departmentId", "department belongs to another company")
+	}
+	if cancelled != nil {
+		return cancelled, s.cancelTransfer(ctx, emp, a)
+	}
+
+	return nil, s.moveToDepartment(ctx, emp, a.DepartmentID)
+}
+
+// cancelTransfer drops the pending assignment a of emp and reopens the
+// assignment it closed, if that is the department emp is still in.
+func (s *EmployeeService) cancelTransfer(ctx context.Context, emp *domain.Employee, a *domain.Assignment) error {
+	assignments, err := s.assignRepo.ListByEmployee(ctx, emp.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get assignments: %w", err)
+	}
+	if err := s.assignRepo.Delete(ctx, a.ID); err != nil {
+		return fmt.Errorf("failed to delete assignment: %w", err)
+	}
+
+	for _, prev := range assignments {
+		if prev.EndDate == nil || !prev.EndDate.Equal(a.StartDate.Time) {
+			continue
+		}
+		if emp.DepartmentID == nil || *emp.DepartmentID != prev.DepartmentID {
+			return nil
+		}
+		prev.EndDate = nil
+		if err := s.assignRepo.Update(ctx, prev); err != nil {
+			return fmt.Errorf("failed to update assignment: %w", err)
+		}
+	}
+	return nil
+}
+
+// assign records that the employee is in the department from day on, or in
+// none if deptID is nil, closing their current assignment. Assignments only
+// grow at the end, so day cannot come before a pending transfer; a second
+// move on the same day replaces the first.
+func (s *EmployeeService) assign(ctx context.Context, employeeID int, deptID *int, day domain.Date) (*domain.Assignment, error) {
+	last, err := s.assignRepo.Last(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+
+	if last != nil && last.StartDate.After(day.Time) {
+		return nil, fmt.Errorf("%w: employee has a transfer pending on %s", domain.ErrConflict, last.StartDate)
+	}
+
+	if last != nil && last.StartDate.Equal(day.Time) {
+		if deptID == nil {
+			if err := s.assignRepo.Delete(ctx, last.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete assignment: %w", err)
+			}
+			return nil, nil
+		}
+		last.DepartmentID = *deptID
+		last.EndDate = nil
+		if err := s.assignRepo.Update(ctx, last); err != nil {
+			return nil, fmt.Errorf("failed to update assignment: %w", err)
+		}
+		return last, nil
+	}
+
+	if last != nil && last.EndDate == nil {
+		end := day
+		last.EndDate = &end
+		if err := s.assignRepo.Update(ctx, last); err != nil {
+			return nil, fmt.Errorf("failed to update assignment: %w", err)
+		}
+	}
+	if deptID == nil {
+		return nil, nil
+	}
+
+	assignment := &domain.Assignment{EmployeeID: employeeID, DepartmentID: *deptID, StartDate: day}
+	id, err := s.assignRepo.Create(ctx, assignment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create assignment: %w", err)
+	}
+	assignment.ID = id
+	return assignment, nil
+}
+
+// moveToDepartment points the employee at the department, with the audit
+// entry and event of any other update.
+func (s *EmployeeService) moveToDepartment(ctx context.Context, current *domain.Employee, deptID int) error {
+	emp := *current
+	emp.DepartmentID = &deptID
+	emp.Department = nil
+
+	if err := s.empRepo.Update(ctx, &emp); err != nil {
+		return fmt.Errorf("failed to update employee: %w", err)
+	}
+	if err := s.audit(ctx, domain.AuditUpdate, emp.ID, current, &emp); err != nil {
+		return err
+	}
+	return addEmployeeEvent(ctx, s.outboxRepo, domain.EventEmployeeUpdated, &emp, domain.DiffEmployees(current, &emp))
+}
+
+// sameID reports whether a and b refer to the same entity or are both nil.
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
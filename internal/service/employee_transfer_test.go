@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/service"
+)
+
+func TestEmployeeService_TransferEmployee(t *testing.T) {
+	today := domain.Today()
+
+	newService := func(empRepo *EmployeeRepositoryMock, deptRepo *DepartmentRepositoryMock, assignments *assignmentLog, events *eventLog) *service.EmployeeService {
+		return service.NewEmployeeService(empRepo, deptRepo, activeCompanies(), &auditLog{}, events, assignments, &documentLog{}, inlineTx{})
+	}
+
+	t.Run("Success: a future transfer is only recorded", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		assignments := &assignmentLog{assignments: []*domain.Assignment{
+			{ID: 1, EmployeeID: 1, DepartmentID: 42, StartDate: today.AddDays(-30)},
+		}}
+		events := &eventLog{}
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		deptRepo.On("GetByID", mock.Anything, 7).Return(&domain.Department{ID: 7, CompanyID: 1}, nil)
+
+		later := today.AddDays(3)
+		a, err := newService(empRepo, deptRepo, assignments, events).
+			TransferEmployee(context.Background(), 1, &domain.Transfer{DepartmentID: 7, EffectiveDate: &later})
+		require.NoError(t, err)
+
+		assert.Equal(t, later, a.StartDate)
+		require.Len(t, assignments.assignments, 2)
+		assert.Equal(t, &later, assignments.assignments[0].EndDate)
+		assert.Empty(t, events.events)
+		empRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: department of another company", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		deptRepo.On("GetByID", mock.Anything, 7).Return(&domain.Department{ID: 7, CompanyID: 2}, nil)
+
+		_, err := newService(empRepo, deptRepo, &assignmentLog{}, &eventLog{}).
+			TransferEmployee(context.Background(), 1, &domain.Transfer{DepartmentID: 7})
+		assert.Equal(t, "departmentId", violatedField(t, err))
+	})
+
+	t.Run("Success: due transfers move employees", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		assignments := &assignmentLog{assignments: []*domain.Assignment{
+			{ID: 1, EmployeeID: 1, DepartmentID: 42, StartDate: today.AddDays(-30), EndDate: &today},
+			{ID: 2, EmployeeID: 1, DepartmentID: 7, StartDate: today},
+		}}
+		events := &eventLog{}
+
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("Update", mock.Anything, mock.MatchedBy(func(e *domain.Employee) bool {
+			return *e.DepartmentID == 7
+		})).Return(nil).Once()
+		deptRepo := new(DepartmentRepositoryMock)
+		deptRepo.On("GetByID", mock.Anything, 7).Return(&domain.Department{ID: 7, CompanyID: 1}, nil)
+
+		applied, err := newService(empRepo, deptRepo, assignments, events).
+			ApplyDueTransfers(context.Background(), 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, applied)
+		assert.Equal(t, []domain.EventType{domain.EventEmployeeUpdated}, events.types())
+		empRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error: a failing transfer does not hold up the batch", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		assignments := &assignmentLog{assignments: []*domain.Assignment{
+			{ID: 1, EmployeeID: 1, DepartmentID: 42, StartDate: today.AddDays(-30), EndDate: &today},
+			{ID: 2, EmployeeID: 1, DepartmentID: 7, StartDate: today},
+			{ID: 3, EmployeeID: 2, DepartmentID: 42, StartDate: today.AddDays(-30), EndDate: &today},
+			{ID: 4, EmployeeID: 2, DepartmentID: 7, StartDate: today},
+		}}
+		events := &eventLog{}
+
+		second := storedEmployee()
+		second.ID = 2
+		empRepo.On("GetByID", mock.Anything, 1).Return(storedEmployee(), nil)
+		empRepo.On("GetByID", mock.Anything, 2).Return(second, nil)
+		empRepo.On("Update", mock.Anything, mock.MatchedBy(func(e *domain.Employee) bool { return e.ID == 1 })).
+			Return(errors.New("connection reset")).Once()
+		empRepo.On("Update", mock.Anything, mock.MatchedBy(func(e *domain.Employee) bool { return e.ID == 2 })).
+			Return(nil).Once()
+		deptRepo.On("GetByID", mock.Anything, 7).Return(&domain.Department{ID: 7, CompanyID: 1}, nil)
+
+		applied, err := newService(empRepo, deptRepo, assignments, events).
+			ApplyDueTransfers(context.Background(), 10)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to transfer employee 1")
+
+		assert.Equal(t, 1, applied)
+		assert.Len(t, assignments.assignments, 4, "the failed transfer is tried again later")
+		assert.Equal(t, []domain.EventType{domain.EventEmployeeUpdated}, events.types())
+		empRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error: a transfer into the employee's former company is cancelled", func(t *testing.T) {
+		empRepo := new(EmployeeRepositoryMock)
+		deptRepo := new(DepartmentRepositoryMock)
+		assignments := &assignmentLog{assignments: []*domain.Assignment{
+			{ID: 1, EmployeeID: 1, DepartmentID: 42, StartDate: today.AddDays(-30), EndDate: &today},
+			{ID: 2, EmployeeID: 1, DepartmentID: 7, StartDate: today},
+			{ID: 3, EmployeeID: 2, DepartmentID: 43, StartDate: today},
+		}}
+		events := &eventLog{}
+
+		moved := storedEmployee()
+		moved.CompanyID = 2
+		second := storedEmployee()
+		second.ID = 2
+		empRepo.On("GetByID", mock.Anything, 1).Return(moved, nil)
+		empRepo.On("GetByID", mock.Anything, 2).Return(second, nil)
+		deptRepo.On("GetByID", mock.Anything, 7).Return(&domain.Department{ID: 7, CompanyID: 1}, nil)
+		deptRepo.On("GetByID", mock.Anything, 43).Return(nil, domain.NewNotFoundError("department"))
+
+		applied, err := newService(empRepo, deptRepo, assignments, events).
+			ApplyDueTransfers(context.Background(), 10)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+
+		assert.Equal(t, 0, applied)
+		assert.Empty(t, events.types())
+		require.Len(t, assignments.assignments, 1, "cancelled transfers are not due again")
+		assert.Equal(t, 42, assignments.assignments[0].DepartmentID)
+		assert.Nil(t, assignments.assignments[0].EndDate, "the employee stays where they were")
+		empRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
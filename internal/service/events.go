@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// addEvent writes an event to the outbox. It must run in the transaction of
+// the change it describes, so the event is published if and only if the
+// change is committed.
+func addEvent(ctx context.Context, outbox OutboxRepository, typ domain.EventType, aggregateType string, aggregateID, companyID int, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", typ, err)
+	}
+
+	event := &domain.Event{
+		Type:          typ,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		CompanyID:     companyID,
+		Payload:       data,
+		OccurredAt:    time.Now().UTC(),
+	}
+	if err := outbox.Add(ctx, event); err != nil {
+		return fmt.Errorf("failed to add %s event: %w", typ, err)
+	}
+	return nil
+}
+
+func addEmployeeEvent(ctx context.Context, outbox OutboxRepository, typ domain.EventType, emp *domain.Employee, changes []domain.FieldChange) error {
+	payload := domain.EmployeeEventPayload{Employee: emp, Changes: changes}
+	return addEvent(ctx, outbox, typ, domain.AggregateEmployee, emp.ID, emp.CompanyID, payload)
+}
+
+// addDepartmentCreated records a department created by GetOrCreate under id.
+func addDepartmentCreated(ctx context.Context, outbox OutboxRepository, dept *domain.Department, id int) error {
+	created := *dept
+	created.ID = id
+	return addDepartmentEvent(ctx, outbox, domain.EventDepartmentCreated, &created)
+}
+
+func addDepartmentEvent(ctx context.Context, outbox OutboxRepository, typ domain.EventType, dept *domain.Department) error {
+	payload := domain.DepartmentEventPayload{Department: dept}
+	return addEvent(ctx, outbox, typ, domain.AggregateDepartment, dept.ID, dept.CompanyID, payload)
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/validation"
+)
+
+// importJobRetention is how long finished import jobs can still be looked up.
+const importJobRetention = 24 * time.Hour
+
+var (
+	// errImportRolledBack aborts the transaction of a failed all-or-nothing
+	// import.
+	errImportRolledBack = errors.New("import rolled back")
+	// errImportsStopped refuses jobs once Shutdown has been called.
+	errImportsStopped = errors.New("import service is shutting down")
+)
+
+// ImportService bulk-creates employees from parsed import rows, either while
+// the caller waits or as a background job. Every row goes through
+// EmployeeService.CreateEmployee, so imported employees get the same checks
+// and audit entries as those created one by one.
+//
+// Jobs are kept in the memory of the process that started them: they do not
+// survive a restart, and with several instances behind a load balancer a job
+// can only be looked up on the instance that runs it. Shutdown lets running
+// jobs finish before the process exits.
+type ImportService struct {
+	employees   *EmployeeService
+	companyRepo CompanyRepository
+	tx          Transactor
+
+	// running tracks job goroutines; cancelJobs cancels their contexts.
+	running    sync.WaitGroup
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+
+	mu      sync.Mutex
+	jobs    map[string]*domain.ImportJob
+	stopped bool
+}
+
+func NewImportService(employees *EmployeeService, companyRepo CompanyRepository, tx Transactor) *ImportService {
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	return &ImportService{
+		employees:   employees,
+		companyRepo: companyRepo,
+		tx:          tx,
+		jobsCtx:     jobsCtx,
+		cancelJobs:  cancelJobs,
+		jobs:        make(map[string]*domain.ImportJob),
+	}
+}
+
+// ImportEmployees imports rows into the company and returns the per-row
+// report. Row failures are part of the report; an error is only returned if
+// the import could not be carried out at all.
+func (s *ImportService) ImportEmployees(ctx context.Context, companyID int, rows []domain.ImportRow, mode domain.ImportMode) (*domain.ImportReport, error) {
+	if err := s.checkImport(ctx, companyID, mode); err != nil {
+		return nil, err
+	}
+	return s.run(ctx, rows, mode, func(int) {})
+}
+
+// StartImport checks the request and runs the import in the background. The
+// job outlives ctx but keeps its values, so audit entries still name the
+// actor and request that started it. After Shutdown it fails with
+// domain.ErrUnavailable.
+func (s *ImportService) StartImport(ctx context.Context, companyID int, rows []domain.ImportRow, mode domain.ImportMode) (*domain.ImportJob, error) {
+	if err := s.checkImport(ctx, companyID, mode); err != nil {
+		return nil, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start import: %w", err)
+	}
+
+	job := &domain.ImportJob{
+		ID:        id,
+		CompanyID: companyID,
+		Mode:      mode,
+		Status:    domain.ImportJobRunning,
+		Rows:      len(rows),
+		StartedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil, domain.NewUnavailableError(errImportsStopped)
+	}
+	s.evictFinishedJobs()
+	s.jobs[job.ID] = job
+	started := *job
+	s.running.Add(1)
+	s.mu.Unlock()
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stopCancel := context.AfterFunc(s.jobsCtx, cancel)
+	go func() {
+		defer s.running.Done()
+		defer cancel()
+		defer stopCancel()
+		s.runJob(jobCtx, job, rows)
+	}()
+
+	return &started, nil
+}
+
+// Shutdown refuses new jobs and waits for the running ones to finish. If ctx
+// is done first, the jobs are cancelled, which rolls back all-or-nothing
+// imports, and ctx.Err() is returned once they have stopped.
+func (s *ImportService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancelJobs()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// GetImportJob returns a snapshot of a job of the company.
+func (s *ImportService) GetImportJob(ctx context.Context, companyID int, id string) (*domain.ImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.CompanyID != companyID {
+		return nil, domain.NewNotFoundError("import job")
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+func (s *ImportService) runJob(ctx context.Context, job *domain.ImportJob, rows []domain.ImportRow) {
+	report, err := s.run(ctx, rows, job.Mode, func(processed int) {
+		s.mu.Lock()
+		job.Processed = processed
+		s.mu.Unlock()
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := time.Now().UTC()
+	job.FinishedAt = &finished
+	if err != nil {
+		job.Status = domain.ImportJobFailed
+		job.Error = importErrorReason(err)
+		return
+	}
+	job.Status = domain.ImportJobCompleted
+	job.Processed = len(rows)
+	job.Report = report
+}
+
+// evictFinishedJobs drops jobs that finished more than importJobRetention
+// ago. The caller holds s.mu.
+func (s *ImportService) evictFinishedJobs() {
+	cutoff := time.Now().Add(-importJobRetention)
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (s *ImportService) checkImport(ctx context.Context, companyID int, mode domain.ImportMode) error {
+	if mode != domain.ImportAllOrNothing && mode != domain.ImportBestEffort {
+		return domain.NewValidationError("mode", fmt.Sprintf("import mode must be %q or %q", domain.ImportAllOrNothing, domain.ImportBestEffort))
+	}
+	return ensureActiveCompany(ctx, s.companyRepo, companyID)
+}
+
+func (s *ImportService) run(ctx context.Context, rows []domain.ImportRow, mode domain.ImportMode, progress func(processed int)) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{Mode: mode, Rows: make([]domain.ImportRowResult, len(rows))}
+
+	var err error
+	if mode == domain.ImportAllOrNothing {
+		err = s.runAllOrNothing(ctx, rows, report, progress)
+	} else {
+		err = s.runBestEffort(ctx, rows, report, progress)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case domain.ImportCreated:
+			report.Created++
+		case domain.ImportSkipped:
+			report.Skipped++
+		case domain.ImportFailed:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// runBestEffort creates rows one by one, each in its own transaction. Rows
+// whose phone or passport number is already taken are skipped, so a file can
+// safely be imported again after fixing the failed rows.
+func (s *ImportService) runBestEffort(ctx context.Context, rows []domain.ImportRow, report *domain.ImportReport, progress func(int)) error {
+	for i, row := range rows {
+		if row.Err != nil {
+			report.Rows[i] = failedRow(row.Line, row.Err)
+			progress(i + 1)
+			continue
+		}
+
+		id, err := s.employees.CreateEmployee(ctx, row.Employee)
+		var conflictErr *domain.ConflictError
+		switch {
+		case err == nil:
+			report.Rows[i] = domain.ImportRowResult{Line: row.Line, Status: domain.ImportCreated, EmployeeID: id}
+		case errors.As(err, &conflictErr):
+			report.Rows[i] = domain.ImportRowResult{
+				Line:   row.Line,
+				Status: domain.ImportSkipped,
+				Field:  conflictErr.Field,
+				Reason: conflictErr.Error(),
+			}
+		case isFatalImportError(ctx, err):
+			return err
+		default:
+			report.Rows[i] = failedRow(row.Line, err)
+		}
+		progress(i + 1)
+	}
+	return nil
+}
+
+// runAllOrNothing creates every row in one transaction. Rows that fail to
+// parse are reported without touching the database; otherwise the first row
+// that cannot be created rolls the whole import back.
+func (s *ImportService) runAllOrNothing(ctx context.Context, rows []domain.ImportRow, report *domain.ImportReport, progress func(int)) error {
+	invalid := false
+	for i, row := range rows {
+		if row.Err != nil {
+			report.Rows[i] = failedRow(row.Line, row.Err)
+			invalid = true
+		}
+	}
+	if invalid {
+		skipRest(rows, report, "not imported: other rows are invalid")
+		report.RolledBack = true
+		progress(len(rows))
+		return nil
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for i, row := range rows {
+			id, err := s.employees.CreateEmployee(ctx, row.Employee)
+			if err != nil {
+				if isFatalImportError(ctx, err) {
+					return err
+				}
+				report.Rows[i] = failedRow(row.Line, err)
+				skipRest(rows, report, "not imported: import rolled back")
+				return errImportRolledBack
+			}
+			report.Rows[i] = domain.ImportRowResult{Line: row.Line, Status: domain.ImportCreated, EmployeeID: id}
+			progress(i + 1)
+		}
+		return nil
+	})
+	if errors.Is(err, errImportRolledBack) {
+		report.RolledBack = true
+		progress(len(rows))
+		return nil
+	}
+	return err
+}
+
+// skipRest marks every row that has not failed as skipped, including rows
+// created in a rolled back transaction.
+func skipRest(rows []domain.ImportRow, report *domain.ImportReport, reason string) {
+	for i := range rows {
+		if report.Rows[i].Status == domain.ImportFailed {
+			continue
+		}
+		report.Rows[i] = domain.ImportRowResult{Line: rows[i].Line, Status: domain.ImportSkipped, Reason: reason}
+	}
+}
+
+func failedRow(line int, err error) domain.ImportRowResult {
+	result := domain.ImportRowResult{Line: line, Status: domain.ImportFailed, Reason: importErrorReason(err)}
+
+	var violationsErr *validation.Error
+	var validationErr *domain.ValidationError
+	var conflictErr *domain.ConflictError
+	switch {
+	case errors.As(err, &violationsErr):
+		result.Field = validation.Dotted(violationsErr.Violations[0].Field)
+	case errors.As(err, &validationErr):
+		result.Field = validationErr.Field
+	case errors.As(err, &conflictErr):
+		result.Field = conflictErr.Field
+	}
+	return result
+}
+
+// importErrorReason is the text of err shown in import reports and job
+// status. Errors about the imported data are shown as they are; the details
+// of any other failure are only logged, as they are for other requests.
+func importErrorReason(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrValidation), errors.Is(err, domain.ErrConflict), errors.Is(err, domain.ErrNotFound):
+		return err.Error()
+	case errors.Is(err, domain.ErrUnavailable):
+		log.Printf("Import failed, storage unavailable: %v", err)
+		return "storage unavailable"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "import cancelled"
+	default:
+		log.Printf("Import failed: %v", err)
+		return "internal error"
+	}
+}
+
+// isFatalImportError reports whether err stops the whole import rather than
+// failing a single row.
+func isFatalImportError(ctx context.Context, err error) bool {
+	return errors.Is(err, domain.ErrUnavailable) || ctx.Err() != nil
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
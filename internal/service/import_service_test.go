@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingTx holds every unit of work until release is closed or its context
+// is cancelled.
+type blockingTx struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingTx() *blockingTx {
+	return &blockingTx{entered: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (tx *blockingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx.entered <- struct{}{}
+	select {
+	case <-tx.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestImportService_Shutdown(t *testing.T) {
+	rows := []domain.ImportRow{{Line: 2, Employee: &domain.Employee{Name: "John"}}}
+
+	start := func(t *testing.T) (*service.ImportService, *blockingTx, *domain.ImportJob) {
+		tx := newBlockingTx()
+		svc := service.NewImportService(nil, activeCompanies(), tx)
+		job, err := svc.StartImport(context.Background(), 1, rows, domain.ImportAllOrNothing)
+		require.NoError(t, err)
+		<-tx.entered
+		return svc, tx, job
+	}
+
+	t.Run("Success: running jobs are drained", func(t *testing.T) {
+		svc, tx, job := start(t)
+
+		stopped := make(chan error)
+		go func() { stopped <- svc.Shutdown(context.Background()) }()
+
+		select {
+		case <-stopped:
+			t.Fatal("Shutdown returned while a job was running")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(tx.release)
+		require.NoError(t, <-stopped)
+
+		finished, err := svc.GetImportJob(context.Background(), 1, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportJobCompleted, finished.Status)
+
+		_, err = svc.StartImport(context.Background(), 1, rows, domain.ImportAllOrNothing)
+		assert.ErrorIs(t, err, domain.ErrUnavailable, "no jobs after shutdown")
+	})
+
+	t.Run("Error: jobs still running at the deadline are cancelled", func(t *testing.T) {
+		svc, _, job := start(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, svc.Shutdown(ctx), context.DeadlineExceeded)
+
+		finished, err := svc.GetImportJob(context.Background(), 1, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportJobFailed, finished.Status)
+		assert.Equal(t, "import cancelled", finished.Error)
+	})
+}
+
+func TestImportService_ErrorDetails(t *testing.T) {
+	rows := func() []domain.ImportRow {
+		return []domain.ImportRow{{Line: 2, Employee: &domain.Employee{Name: "John", CompanyID: 1}}}
+	}
+	importing := func(createErr error) *service.ImportService {
+		empRepo := new(EmployeeRepositoryMock)
+		empRepo.On("Create", mock.Anything, mock.Anything).Return(0, createErr)
+		employees := service.NewEmployeeService(empRepo, new(DepartmentRepositoryMock), activeCompanies(), &auditLog{}, &eventLog{}, &assignmentLog{}, &documentLog{}, inlineTx{})
+		return service.NewImportService(employees, activeCompanies(), inlineTx{})
+	}
+
+	t.Run("Success: data errors are reported as they are", func(t *testing.T) {
+		svc := importing(domain.NewConflictError("employee", "phone"))
+		report, err := svc.ImportEmployees(context.Background(), 1, rows(), domain.ImportAllOrNothing)
+
+		require.NoError(t, err)
+		assert.Equal(t, "failed to create employee: employee with this phone already exists", report.Rows[0].Reason)
+	})
+
+	t.Run("Error: internal errors are not shown in the row report", func(t *testing.T) {
+		svc := importing(errors.New(`pq: relation "employees" does not exist`))
+		report, err := svc.ImportEmployees(context.Background(), 1, rows(), domain.ImportBestEffort)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportFailed, report.Rows[0].Status)
+		assert.Equal(t, "internal error", report.Rows[0].Reason)
+	})
+
+	t.Run("Error: storage errors are not shown in the job status", func(t *testing.T) {
+		svc := importing(domain.NewUnavailableError(errors.New("dial tcp 10.0.0.5:5432: connection refused")))
+		job, err := svc.StartImport(context.Background(), 1, rows(), domain.ImportBestEffort)
+		require.NoError(t, err)
+		require.NoError(t, svc.Shutdown(context.Background()))
+
+		finished, err := svc.GetImportJob(context.Background(), 1, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportJobFailed, finished.Status)
+		assert.Equal(t, "storage unavailable", finished.Error)
+	})
+}
@@ -1,17 +1,117 @@
 package service
 
-import "github.com/Hexes-rgb/employee-service/internal/domain"
+import (
+	"context"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// Transactor runs a unit of work: repository calls made with the context
+// passed to fn commit or roll back together, depending on whether fn returns
+// an error.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
 
 type EmployeeRepository interface {
-	Create(emp *domain.Employee) (int, error)
-	GetByID(id int) (*domain.Employee, error)
-	Update(emp *domain.Employee) error
-	Delete(id int) error
-	GetByCompany(companyID int) ([]*domain.Employee, error)
-	GetByDepartment(companyID, deptName int) ([]*domain.Employee, error)
+	Create(ctx context.Context, emp *domain.Employee) (int, error)
+	GetByID(ctx context.Context, id int) (*domain.Employee, error)
+	Update(ctx context.Context, emp *domain.Employee) error
+	Delete(ctx context.Context, id, version int) error
+	Restore(ctx context.Context, id int) error
+	GetByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error)
+	GetByDepartment(ctx context.Context, companyID, deptID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error)
+	Search(ctx context.Context, companyID int, query domain.SearchQuery) ([]*domain.SearchResult, error)
+	StreamByCompany(ctx context.Context, companyID int, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+	GetDirectReports(ctx context.Context, managerID int) ([]*domain.Employee, error)
+	// GetManagementChain returns the managers above the employee, nearest
+	// first.
+	GetManagementChain(ctx context.Context, id int) ([]*domain.Employee, error)
+	GetSubordinates(ctx context.Context, managerID int) ([]*domain.Subordinate, error)
+	// GetManagerIDs returns the IDs of the managers above the employee,
+	// nearest first, deleted managers included. A cycle ends the list after
+	// the first repeated ID.
+	GetManagerIDs(ctx context.Context, id int) ([]int, error)
+	// LockReportingLines holds the reporting lines of the company until the
+	// transaction ends, so concurrent manager changes cannot close a cycle
+	// between them.
+	LockReportingLines(ctx context.Context, companyID int) error
+}
+
+// AuditRepository stores the append-only employee audit trail.
+type AuditRepository interface {
+	Append(ctx context.Context, entry *domain.AuditEntry) error
+	// Last returns the latest entry of the employee, or nil if it has none.
+	Last(ctx context.Context, employeeID int) (*domain.AuditEntry, error)
+	ListByEmployee(ctx context.Context, employeeID int) ([]*domain.AuditEntry, error)
+}
+
+// OutboxRepository stores events in the transaction of the change they
+// describe, for the relay to publish.
+type OutboxRepository interface {
+	Add(ctx context.Context, event *domain.Event) error
 }
 
 type DepartmentRepository interface {
-	GetOrCreate(dept *domain.Department) (int, error)
-	GetByID(id int) (*domain.Department, error)
+	// GetOrCreate returns the ID of the company's department with the name
+	// of dept, creating it first if there is none; created tells which.
+	GetOrCreate(ctx context.Context, dept *domain.Department) (id int, created bool, err error)
+	GetByID(ctx context.Context, id int) (*domain.Department, error)
+	ListByCompany(ctx context.Context, companyID int) ([]*domain.DepartmentSummary, error)
+	Update(ctx context.Context, dept *domain.Department) error
+	Delete(ctx context.Context, id int) error
+	// LockHierarchy holds the department hierarchy of the company until the
+	// transaction ends, so concurrent moves cannot close a cycle between
+	// them.
+	LockHierarchy(ctx context.Context, companyID int) error
+}
+
+type AssignmentRepository interface {
+	Create(ctx context.Context, a *domain.Assignment) (int, error)
+	Update(ctx context.Context, a *domain.Assignment) error
+	Delete(ctx context.Context, id int) error
+	// Last returns the assignment of the employee that starts last, or nil
+	// if they have none.
+	Last(ctx context.Context, employeeID int) (*domain.Assignment, error)
+	ListByEmployee(ctx context.Context, employeeID int) ([]*domain.Assignment, error)
+	// ListDue returns open assignments that started on or before day while
+	// their live employee is still recorded in another department, oldest
+	// first.
+	ListDue(ctx context.Context, day domain.Date, limit int) ([]*domain.Assignment, error)
+}
+
+type DocumentRepository interface {
+	Create(ctx context.Context, doc *domain.IdentityDocument) (int, error)
+	GetByID(ctx context.Context, id int) (*domain.IdentityDocument, error)
+	ListByEmployee(ctx context.Context, employeeID int) ([]*domain.IdentityDocument, error)
+	Update(ctx context.Context, doc *domain.IdentityDocument) error
+	Delete(ctx context.Context, id int) error
+	// ListExpiring returns the documents of the company's live employees that
+	// expire between from and to inclusive, soonest first.
+	ListExpiring(ctx context.Context, companyID int, from, to domain.Date) ([]*domain.ExpiringDocument, error)
+}
+
+type CompanyRepository interface {
+	Create(ctx context.Context, company *domain.Company) (int, error)
+	GetByID(ctx context.Context, id int) (*domain.Company, error)
+	List(ctx context.Context) ([]*domain.Company, error)
+	Update(ctx context.Context, company *domain.Company) error
+	Delete(ctx context.Context, id int) error
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (int, error)
+	GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, companyID int) ([]*domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id int) error
+	// AddDelivery stores a new delivery; it does nothing if the subscription
+	// already has a delivery of the event, so an event relayed twice is
+	// delivered once.
+	AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error)
+	// ListDeliveries returns the latest deliveries of the subscription, newest
+	// first, optionally only those with status.
+	ListDeliveries(ctx context.Context, subscriptionID int, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
 }
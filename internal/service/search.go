@@ -0,0 +1,98 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// latinToCyrillic is ordered longest sequence first so that digraphs win
+// over single letters.
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"},
+	{"a", "а"}, {"b", "б"}, {"c", "ц"}, {"d", "д"}, {"e", "е"}, {"f", "ф"},
+	{"g", "г"}, {"h", "х"}, {"i", "и"}, {"j", "й"}, {"k", "к"}, {"l", "л"},
+	{"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"},
+	{"s", "с"}, {"t", "т"}, {"u", "у"}, {"v", "в"}, {"w", "в"}, {"x", "кс"},
+	{"y", "й"}, {"z", "з"},
+}
+
+// searchWords lowercases q and splits it into words made of letters and
+// digits only, dropping everything that could be tsquery syntax.
+func searchWords(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchVariants returns the normalised query followed by its
+// transliteration into the other script, if it differs.
+func searchVariants(q string) []string {
+	words := searchWords(q)
+	if len(words) == 0 {
+		return nil
+	}
+
+	alt := make([]string, len(words))
+	for i, w := range words {
+		alt[i] = transliterate(w)
+	}
+
+	variants := []string{strings.Join(words, " ")}
+	if v := strings.Join(alt, " "); v != variants[0] {
+		variants = append(variants, v)
+	}
+	return variants
+}
+
+// transliterate converts a Cyrillic word to Latin and anything else to
+// Cyrillic. Characters without a mapping, such as digits, are kept.
+func transliterate(word string) string {
+	var b strings.Builder
+
+	if strings.IndexFunc(word, isCyrillic) >= 0 {
+		for _, r := range word {
+			if latin, ok := cyrillicToLatin[r]; ok {
+				b.WriteString(latin)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	}
+
+	for i := 0; i < len(word); {
+		matched := false
+		for _, m := range latinToCyrillic {
+			if strings.HasPrefix(word[i:], m.latin) {
+				b.WriteString(m.cyrillic)
+				i += len(m.latin)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			r, size := utf8.DecodeRuneInString(word[i:])
+			b.WriteRune(r)
+			i += size
+		}
+	}
+	return b.String()
+}
+
+func isCyrillic(r rune) bool {
+	return unicode.Is(unicode.Cyrillic, r)
+}
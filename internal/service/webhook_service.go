@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+const (
+	DefaultDeliveryLimit = 50
+	MaxDeliveryLimit     = 500
+)
+
+// WebhookService manages webhook subscriptions and turns relayed events into
+// deliveries; sending them is up to webhook.Dispatcher.
+type WebhookService struct {
+	repo        WebhookRepository
+	companyRepo CompanyRepository
+}
+
+func NewWebhookService(repo WebhookRepository, companyRepo CompanyRepository) *WebhookService {
+	return &WebhookService{repo: repo, companyRepo: companyRepo}
+}
+
+// CreateSubscription creates an active subscription. A secret is generated if
+// sub has none; the returned subscription is the only one carrying it.
+func (s *WebhookService) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if err := ensureActiveCompany(ctx, s.companyRepo, sub.CompanyID); err != nil {
+		return nil, err
+	}
+
+	if sub.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook secret: %w", err)
+		}
+		sub.Secret = secret
+	}
+	if sub.EventTypes == nil {
+		sub.EventTypes = []domain.EventType{}
+	}
+	sub.Active = true
+	sub.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	id, err := s.repo.CreateSubscription(ctx, sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	sub.ID = id
+
+	return sub, nil
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, companyID, id int) (*domain.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, companyID, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context, companyID int) ([]*domain.WebhookSubscription, error) {
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+
+	subs, err := s.repo.ListSubscriptions(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+	return subs, nil
+}
+
+// UpdateSubscription applies patch and returns the subscription, with the
+// secret only if the patch changed it.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, companyID, id int, patch *domain.WebhookSubscriptionPatch) (*domain.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, companyID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.URL != nil {
+		sub.URL = *patch.URL
+	}
+	if patch.Secret != nil {
+		sub.Secret = *patch.Secret
+	}
+	if patch.EventTypes != nil {
+		sub.EventTypes = append([]domain.EventType{}, *patch.EventTypes...)
+	}
+	if patch.Active != nil {
+		sub.Active = *patch.Active
+	}
+
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	if patch.Secret == nil {
+		sub.Secret = ""
+	}
+	return sub, nil
+}
+
+// DeleteSubscription deletes the subscription together with its deliveries.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, companyID, id int) error {
+	if _, err := s.subscription(ctx, companyID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, companyID, subscriptionID int, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	if _, err := s.subscription(ctx, companyID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultDeliveryLimit
+	}
+	if limit > MaxDeliveryLimit {
+		limit = MaxDeliveryLimit
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, subscriptionID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ReplayDelivery schedules a failed delivery to be sent again right away,
+// with a fresh set of attempts.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, companyID, subscriptionID int, id int64) (*domain.WebhookDelivery, error) {
+	if _, err := s.subscription(ctx, companyID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return nil, domain.NewNotFoundError("webhook delivery")
+	}
+	if !delivery.Failed() {
+		return nil, domain.NewValidationError("status", fmt.Sprintf("only failed deliveries can be replayed, delivery %d is %s", id, delivery.Status))
+	}
+
+	now := time.Now().UTC()
+	delivery.Status = domain.WebhookPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// EnqueueEvent creates a delivery of event for every active subscription of
+// its company that accepts it. The outbox relay calls it for every event, so
+// webhooks see the same events, in the same order, as the message broker.
+func (s *WebhookService) EnqueueEvent(ctx context.Context, event *domain.Event) error {
+	subs, err := s.repo.ListSubscriptions(ctx, event.CompanyID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+
+	var payload json.RawMessage
+	for _, sub := range subs {
+		if !sub.Accepts(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("failed to encode event %d: %w", event.ID, err)
+			}
+		}
+
+		now := time.Now().UTC()
+		delivery := &domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         domain.WebhookPending,
+			NextAttemptAt:  &now,
+			CreatedAt:      now,
+		}
+		if err := s.repo.AddDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to add webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// subscription returns the subscription if it belongs to the company.
+func (s *WebhookService) subscription(ctx context.Context, companyID, id int) (*domain.WebhookSubscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if sub.CompanyID != companyID {
+		return nil, domain.NewNotFoundError("webhook subscription")
+	}
+	return sub, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type CompanyHandlers struct {
+	service CompanyService
+}
+
+func NewCompanyHandlers(s CompanyService) *CompanyHandlers {
+	return &CompanyHandlers{service: s}
+}
+
+func (h *CompanyHandlers) CreateCompany(w http.ResponseWriter, r *http.Request) {
+	var company domain.Company
+	if err := json.NewDecoder(r.Body).Decode(&company); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validateCompany(&company); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	id, err := h.service.CreateCompany(r.Context(), &company)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, IDResponse{ID: id})
+}
+
+func (h *CompanyHandlers) GetCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	company, err := h.service.GetCompany(r.Context(), id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, company)
+}
+
+func (h *CompanyHandlers) ListCompanies(w http.ResponseWriter, r *http.Request) {
+	companies, err := h.service.ListCompanies(r.Context())
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, companies)
+}
+
+func (h *CompanyHandlers) UpdateCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	var patch domain.CompanyPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validateCompanyPatch(&patch); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	if err := h.service.UpdateCompany(r.Context(), id, &patch); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Company updated successfully"})
+}
+
+func (h *CompanyHandlers) DeleteCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	if err := h.service.DeleteCompany(r.Context(), id); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Company deleted successfully"})
+}
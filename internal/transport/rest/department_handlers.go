@@ -24,13 +24,13 @@ func (h *DepartmentHandlers) GetOrCreateDepartment(w http.ResponseWriter, r *htt
 	}
 
 	if err := validateDepartment(&dept); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
-	id, err := h.service.GetOrCreate(&dept)
+	id, err := h.service.GetOrCreate(r.Context(), &dept)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
@@ -44,11 +44,102 @@ func (h *DepartmentHandlers) GetDepartment(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	dept, err := h.service.GetDepartment(id)
+	dept, err := h.service.GetDepartment(r.Context(), id)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, dept)
 }
+
+func (h *DepartmentHandlers) GetCompanyDepartments(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	departments, err := h.service.GetCompanyDepartments(r.Context(), companyID)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, departments)
+}
+
+// GetDepartmentTree returns the departments of the company nested under
+// their parents, with headcounts rolled up.
+func (h *DepartmentHandlers) GetDepartmentTree(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	tree, err := h.service.GetDepartmentTree(r.Context(), companyID)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tree)
+}
+
+func (h *DepartmentHandlers) UpdateDepartment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid department ID")
+		return
+	}
+
+	var body struct {
+		domain.Department
+		// ParentID is kept raw to tell null, which moves the department to
+		// the top level, from a missing field, which leaves it in place.
+		ParentID json.RawMessage `json:"parentId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	dept := body.Department
+	dept.ID = id
+	if len(body.ParentID) > 0 {
+		if err := json.Unmarshal(body.ParentID, &dept.ParentID); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if dept.ParentID == nil {
+			dept.ParentID = new(int)
+		}
+	}
+
+	if err := validateDepartmentUpdate(&dept); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	if err := h.service.UpdateDepartment(r.Context(), &dept); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Department updated successfully"})
+}
+
+func (h *DepartmentHandlers) DeleteDepartment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid department ID")
+		return
+	}
+
+	if err := h.service.DeleteDepartment(r.Context(), id); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Department deleted successfully"})
+}
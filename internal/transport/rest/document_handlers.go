@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type DocumentHandlers struct {
+	service DocumentService
+}
+
+type DocumentListResponse struct {
+	Items []*domain.IdentityDocument `json:"items"`
+}
+
+type ExpiringDocumentListResponse struct {
+	Items []*domain.ExpiringDocument `json:"items"`
+}
+
+func NewDocumentHandlers(s DocumentService) *DocumentHandlers {
+	return &DocumentHandlers{service: s}
+}
+
+func (h *DocumentHandlers) CreateDocument(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var doc domain.IdentityDocument
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validateDocument(&doc); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	created, err := h.service.CreateDocument(r.Context(), employeeID, &doc)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *DocumentHandlers) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	docs, err := h.service.ListDocuments(r.Context(), employeeID)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, DocumentListResponse{Items: docs})
+}
+
+func (h *DocumentHandlers) GetDocument(w http.ResponseWriter, r *http.Request) {
+	employeeID, id, ok := documentPath(w, r)
+	if !ok {
+		return
+	}
+
+	doc, err := h.service.GetDocument(r.Context(), employeeID, id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, doc)
+}
+
+// UpdateDocument replaces the document with the request body; fields left
+// out are cleared.
+func (h *DocumentHandlers) UpdateDocument(w http.ResponseWriter, r *http.Request) {
+	employeeID, id, ok := documentPath(w, r)
+	if !ok {
+		return
+	}
+
+	var doc domain.IdentityDocument
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	doc.ID = id
+
+	if err := validateDocument(&doc); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	updated, err := h.service.UpdateDocument(r.Context(), employeeID, &doc)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *DocumentHandlers) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	employeeID, id, ok := documentPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDocument(r.Context(), employeeID, id); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Document deleted successfully"})
+}
+
+func (h *DocumentHandlers) GetExpiringDocuments(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	var days int
+	if d := r.URL.Query().Get("days"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 {
+			respondWithDomainError(w, domain.NewValidationError("days", "days must be a positive integer"))
+			return
+		}
+		days = n
+	}
+
+	docs, err := h.service.GetExpiringDocuments(r.Context(), companyID, days)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ExpiringDocumentListResponse{Items: docs})
+}
+
+// documentPath parses the employee and document IDs of a document route,
+// responding with 400 if either is invalid.
+func documentPath(w http.ResponseWriter, r *http.Request) (employeeID, id int, ok bool) {
+	employeeID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return 0, 0, false
+	}
+	id, err = strconv.Atoi(r.PathValue("documentId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid document ID")
+		return 0, 0, false
+	}
+	return employeeID, id, true
+}
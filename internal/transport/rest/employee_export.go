@@ -0,0 +1,327 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// exportFlushRows is how many rows are written between flushes, so the
+// client receives the export in chunks rather than all at the end.
+const exportFlushRows = 500
+
+type exportField struct {
+	name  string
+	value func(*domain.Employee) interface{}
+}
+
+// exportFields lists the columns an export can contain, in their default
+// order. Department columns are empty for employees without a department.
+var exportFields = []exportField{
+	{"id", func(e *domain.Employee) interface{} { return e.ID }},
+	{"name", func(e *domain.Employee) interface{} { return e.Name }},
+	{"surname", func(e *domain.Employee) interface{} { return e.Surname }},
+	{"phone", func(e *domain.Employee) interface{} { return e.Phone }},
+	{"companyId", func(e *domain.Employee) interface{} { return e.CompanyID }},
+	{"passportType", func(e *domain.Employee) interface{} { return e.PassportType }},
+	{"passportNumber", func(e *domain.Employee) interface{} { return e.PassportNumber }},
+	{"departmentId", func(e *domain.Employee) interface{} { return e.DepartmentID }},
+	{"departmentName", func(e *domain.Employee) interface{} {
+		if e.Department == nil {
+			return nil
+		}
+		return e.Department.Name
+	}},
+	{"departmentPhone", func(e *domain.Employee) interface{} {
+		if e.Department == nil {
+			return nil
+		}
+		return e.Department.Phone
+	}},
+}
+
+// exportWriter writes exported employees in one format.
+type exportWriter interface {
+	writeHeader(fields []exportField) error
+	writeRow(fields []exportField, emp *domain.Employee) error
+	flush() error
+}
+
+// ExportEmployees streams the employees of a company as CSV or NDJSON. It is
+// registered without the request timeout: large exports take as long as the
+// client needs to read them and are cancelled when it goes away.
+func (h *EmployeeHandlers) ExportEmployees(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	filter, err := parseEmployeeFilter(r)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	fields, err := parseExportFields(query.Get("fields"))
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	if mask := query.Get("maskPassport"); mask != "" {
+		b, err := strconv.ParseBool(mask)
+		if err != nil {
+			respondWithDomainError(w, domain.NewValidationError("maskPassport", "maskPassport must be a boolean"))
+			return
+		}
+		if b {
+			fields = maskPassportField(fields)
+		}
+	}
+
+	escapeFormulas := true
+	if escape := query.Get("escapeFormulas"); escape != "" {
+		escapeFormulas, err = strconv.ParseBool(escape)
+		if err != nil {
+			respondWithDomainError(w, domain.NewValidationError("escapeFormulas", "escapeFormulas must be a boolean"))
+			return
+		}
+	}
+
+	var contentType, extension string
+	var newWriter func(io.Writer) exportWriter
+	switch format := query.Get("format"); format {
+	case "", "csv":
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+		newWriter = func(w io.Writer) exportWriter {
+			return &csvExportWriter{w: csv.NewWriter(w), escapeFormulas: escapeFormulas}
+		}
+	case "ndjson":
+		contentType, extension = "application/x-ndjson", "ndjson"
+		newWriter = func(w io.Writer) exportWriter { return &ndjsonExportWriter{w: bufio.NewWriter(w)} }
+	default:
+		respondWithDomainError(w, domain.NewValidationError("format", "format must be csv or ndjson"))
+		return
+	}
+
+	// The server write timeout is meant for ordinary requests.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	out := newWriter(w)
+	started, rows := false, 0
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="employees-%d.%s"`, companyID, extension))
+		w.WriteHeader(http.StatusOK)
+		return out.writeHeader(fields)
+	}
+
+	err = h.service.ExportEmployees(r.Context(), companyID, filter, func(emp *domain.Employee) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := out.writeRow(fields, emp); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := out.flush(); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err != nil {
+		if !started {
+			respondWithDomainError(w, err)
+			return
+		}
+		// The status line is gone; break the connection so that the client
+		// cannot mistake a truncated export for a complete one.
+		panic(http.ErrAbortHandler)
+	}
+
+	if err := out.flush(); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func parseExportFields(param string) ([]exportField, error) {
+	if param == "" {
+		return exportFields, nil
+	}
+
+	var fields []exportField
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		field, ok := findExportField(name)
+		if !ok {
+			return nil, domain.NewValidationError("fields", fmt.Sprintf("unknown export field %q, expected some of: %s", name, exportFieldNames()))
+		}
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+func findExportField(name string) (exportField, bool) {
+	for _, f := range exportFields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return exportField{}, false
+}
+
+func exportFieldNames() string {
+	names := make([]string, len(exportFields))
+	for i, f := range exportFields {
+		names[i] = f.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// maskPassportField returns fields with the passport number replaced by a
+// masked one.
+func maskPassportField(fields []exportField) []exportField {
+	masked := make([]exportField, len(fields))
+	for i, f := range fields {
+		if f.name == "passportNumber" {
+			f.value = func(e *domain.Employee) interface{} { return maskPassportNumber(e.PassportNumber) }
+		}
+		masked[i] = f
+	}
+	return masked
+}
+
+// maskPassportNumber keeps the last four characters of a passport number
+// and hides the rest; numbers of four characters or less are hidden fully.
+func maskPassportNumber(number string) string {
+	runes := []rune(number)
+	visible := 4
+	if len(runes) <= visible {
+		visible = 0
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+// csvExportWriter writes a header line and one record per employee. With
+// escapeFormulas, text cells that a spreadsheet would evaluate as a formula
+// are prefixed with a quote (CSV injection). Phones such as "+79991234567"
+// are left as they are, so the file can be imported back.
+type csvExportWriter struct {
+	w              *csv.Writer
+	escapeFormulas bool
+}
+
+func (c *csvExportWriter) writeHeader(fields []exportField) error {
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvExportWriter) writeRow(fields []exportField, emp *domain.Employee) error {
+	record := make([]string, len(fields))
+	for i, f := range fields {
+		switch v := f.value(emp).(type) {
+		case nil:
+		case string:
+			if c.escapeFormulas {
+				v = escapeFormula(v)
+			}
+			record[i] = v
+		case int:
+			record[i] = strconv.Itoa(v)
+		case *int:
+			if v != nil {
+				record[i] = strconv.Itoa(*v)
+			}
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula prefixes value with a quote if it starts with a character
+// that makes spreadsheets treat a cell as a formula. A plus followed only by
+// digits is a phone number, and at worst a spreadsheet reads it as one.
+func escapeFormula(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) || isPhoneNumber(value) {
+		return value
+	}
+	return "'" + value
+}
+
+// isPhoneNumber reports whether value is a plus followed by digits.
+func isPhoneNumber(value string) bool {
+	if len(value) < 2 || value[0] != '+' {
+		return false
+	}
+	for _, c := range value[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ndjsonExportWriter writes one JSON object per line with the fields in the
+// requested order.
+type ndjsonExportWriter struct {
+	w *bufio.Writer
+}
+
+func (n *ndjsonExportWriter) writeHeader([]exportField) error {
+	return nil
+}
+
+func (n *ndjsonExportWriter) writeRow(fields []exportField, emp *domain.Employee) error {
+	n.w.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+		value, err := json.Marshal(f.value(emp))
+		if err != nil {
+			return err
+		}
+		n.w.WriteString(strconv.Quote(f.name))
+		n.w.WriteByte(':')
+		n.w.Write(value)
+	}
+	n.w.WriteString("}\n")
+	return nil
+}
+
+func (n *ndjsonExportWriter) flush() error {
+	return n.w.Flush()
+}
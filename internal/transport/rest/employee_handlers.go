@@ -2,10 +2,13 @@ package rest
 
 import (
 	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/jsonpatch"
 )
 
 type EmployeeHandlers struct {
@@ -20,6 +23,14 @@ type MessageResponse struct {
 	Message string `json:"message"`
 }
 
+type SearchResponse struct {
+	Items []*domain.SearchResult `json:"items"`
+}
+
+type HistoryResponse struct {
+	Items []*domain.AuditEntry `json:"items"`
+}
+
 func NewEmployeeHandlers(s EmployeeService) *EmployeeHandlers {
 	return &EmployeeHandlers{service: s}
 }
@@ -32,18 +43,13 @@ func (h *EmployeeHandlers) CreateEmployee(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := validateEmployee(&emp); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
-		return
-	}
-
-	if err := validateDepartment(emp.Department); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
-	id, err := h.service.CreateEmployee(&emp)
+	id, err := h.service.CreateEmployee(r.Context(), &emp)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
@@ -57,15 +63,19 @@ func (h *EmployeeHandlers) GetEmployee(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	emp, err := h.service.GetEmployee(id)
+	emp, err := h.service.GetEmployee(r.Context(), id)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", etag(emp.Version))
 	respondWithJSON(w, http.StatusOK, emp)
 }
 
+// UpdateEmployee accepts an RFC 7396 merge patch (application/json or
+// application/merge-patch+json) or an RFC 6902 JSON Patch
+// (application/json-patch+json).
 func (h *EmployeeHandlers) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
@@ -73,26 +83,60 @@ func (h *EmployeeHandlers) UpdateEmployee(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	var emp domain.Employee
-	if err := json.NewDecoder(r.Body).Decode(&emp); err != nil {
+	version, err := h.expectedVersion(r, id)
+	if err != nil {
+		respondWithIfMatchError(w, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
-	emp.ID = id
 
-	if err := validateDepartment(emp.Department); err != nil {
+	var patch domain.Patch
+	switch mediaType(r) {
+	case "", "application/json", "application/merge-patch+json":
+		patch, err = jsonpatch.NewMergePatch(body)
+	case "application/json-patch+json":
+		patch, err = jsonpatch.NewJSONPatch(body)
+	default:
+		respondWithError(w, http.StatusUnsupportedMediaType,
+			"Content-Type must be application/merge-patch+json or application/json-patch+json")
+		return
+	}
+	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
 		return
 	}
 
-	if err := h.service.UpdateEmployee(&emp); err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	emp, err := h.service.UpdateEmployee(r.Context(), id, version, patch)
+	if err != nil {
+		respondWithDomainError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", etag(emp.Version))
 	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Employee updated successfully"})
 }
 
+// expectedVersion resolves the If-Match header of a write to employee id,
+// reading the employee only if several ETags are listed.
+func (h *EmployeeHandlers) expectedVersion(r *http.Request, id int) (int, error) {
+	m, err := parseIfMatch(r)
+	if err != nil {
+		return 0, err
+	}
+	return m.version(func() (int, error) {
+		emp, err := h.service.GetEmployee(r.Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return emp.Version, nil
+	})
+}
+
 func (h *EmployeeHandlers) DeleteEmployee(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
@@ -100,14 +144,51 @@ func (h *EmployeeHandlers) DeleteEmployee(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := h.service.DeleteEmployee(id); err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	version, err := h.expectedVersion(r, id)
+	if err != nil {
+		respondWithIfMatchError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteEmployee(r.Context(), id, version); err != nil {
+		respondWithDomainError(w, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Employee deleted successfully"})
 }
 
+func (h *EmployeeHandlers) RestoreEmployee(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	if err := h.service.RestoreEmployee(r.Context(), id); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Employee restored successfully"})
+}
+
+func (h *EmployeeHandlers) GetEmployeeHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	entries, err := h.service.GetEmployeeHistory(r.Context(), id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, HistoryResponse{Items: entries})
+}
+
 func (h *EmployeeHandlers) GetCompanyEmployees(w http.ResponseWriter, r *http.Request) {
 	companyID, err := strconv.Atoi(r.PathValue("companyId"))
 	if err != nil {
@@ -115,9 +196,21 @@ func (h *EmployeeHandlers) GetCompanyEmployees(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	employees, err := h.service.GetCompanyEmployees(companyID)
+	page, err := parsePageRequest(r)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	filter, err := parseEmployeeFilter(r)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	employees, err := h.service.GetCompanyEmployees(r.Context(), companyID, filter, page)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
@@ -137,11 +230,85 @@ func (h *EmployeeHandlers) GetDepartmentEmployees(w http.ResponseWriter, r *http
 		return
 	}
 
-	employees, err := h.service.GetDepartmentEmployees(companyID, deptId)
+	page, err := parsePageRequest(r)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	filter, err := parseEmployeeFilter(r)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	if asOf := r.URL.Query().Get("asOf"); asOf != "" {
+		day, err := domain.ParseDate(asOf)
+		if err != nil {
+			respondWithDomainError(w, domain.NewValidationError("asOf", "asOf must be a date in YYYY-MM-DD format"))
+			return
+		}
+		filter.AsOf = &day
+	}
+
+	if recursive := r.URL.Query().Get("recursive"); recursive != "" {
+		b, err := strconv.ParseBool(recursive)
+		if err != nil {
+			respondWithDomainError(w, domain.NewValidationError("recursive", "recursive must be a boolean"))
+			return
+		}
+		filter.IncludeSubdepartments = b
+	}
+
+	employees, err := h.service.GetDepartmentEmployees(r.Context(), companyID, deptId, filter, page)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, employees)
 }
+
+func (h *EmployeeHandlers) SearchEmployees(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		respondWithDomainError(w, domain.NewValidationError("q", "search query is required"))
+		return
+	}
+
+	limit := 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > domain.MaxSearchLimit {
+			respondWithDomainError(w, domain.NewValidationError("limit",
+				"limit must be between 1 and "+strconv.Itoa(domain.MaxSearchLimit)))
+			return
+		}
+	}
+
+	results, err := h.service.SearchEmployees(r.Context(), companyID, q, limit)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SearchResponse{Items: results})
+}
+
+func mediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return ""
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mt
+}
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// importColumns maps normalised CSV header names to the employee fields they
+// fill. Headers are matched ignoring case, spaces, dots, dashes and
+// underscores, so "passport_number" and "Department.Name" both work.
+var importColumns = map[string]string{
+	"name":            "name",
+	"surname":         "surname",
+	"phone":           "phone",
+	"passporttype":    "passportType",
+	"passportnumber":  "passportNumber",
+	"departmentname":  "department.name",
+	"departmentphone": "department.phone",
+}
+
+// requiredImportColumns must be present in every import file; the rest of
+// importColumns are optional.
+var requiredImportColumns = []string{"name", "surname", "phone", "passportNumber", "department.name", "department.phone"}
+
+// parseImportCSV reads an import file whose first record is the header. Rows
+// are validated one by one like POST /employees bodies; invalid rows are
+// returned with Err set so the report can list them. Problems with the file
+// as a whole, such as an unknown column, fail the call.
+func parseImportCSV(r io.Reader, companyID int, delimiter rune) ([]domain.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, domain.NewValidationError("", "import file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	fields, err := importHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []domain.ImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, importRow(line, record, fields, companyID))
+	}
+
+	if len(rows) == 0 {
+		return nil, domain.NewValidationError("", "import file has no rows")
+	}
+	return rows, nil
+}
+
+func importHeader(header []string) ([]string, error) {
+	fields := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+
+	for i, column := range header {
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		field, ok := importColumns[normaliseColumn(column)]
+		if !ok {
+			return nil, domain.NewValidationError(column, fmt.Sprintf("unknown import column %q", column))
+		}
+		if seen[field] {
+			return nil, domain.NewValidationError(field, fmt.Sprintf("import column %q is repeated", column))
+		}
+		seen[field] = true
+		fields[i] = field
+	}
+
+	for _, field := range requiredImportColumns {
+		if !seen[field] {
+			return nil, domain.NewValidationError(field, fmt.Sprintf("import column %q is required", field))
+		}
+	}
+	return fields, nil
+}
+
+func normaliseColumn(column string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", ".", "", "-", "", "_", "").Replace(strings.TrimSpace(column)))
+}
+
+func importRow(line int, record, fields []string, companyID int) domain.ImportRow {
+	row := domain.ImportRow{Line: line}
+	if len(record) != len(fields) {
+		row.Err = domain.NewValidationError("", fmt.Sprintf("row has %d fields, header has %d", len(record), len(fields)))
+		return row
+	}
+
+	emp := &domain.Employee{CompanyID: companyID}
+	dept := &domain.Department{CompanyID: companyID}
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		switch fields[i] {
+		case "name":
+			emp.Name = value
+		case "surname":
+			emp.Surname = value
+		case "phone":
+			emp.Phone = value
+		case "passportType":
+			emp.PassportType = value
+		case "passportNumber":
+			emp.PassportNumber = value
+		case "department.name":
+			dept.Name = value
+		case "department.phone":
+			dept.Phone = value
+		}
+	}
+	emp.Department = dept
+
+	if err := validateEmployee(emp); err != nil {
+		row.Err = err
+		return row
+	}
+
+	row.Employee = emp
+	return row
+}
+
+// isCSVError reports whether err comes from malformed CSV rather than from
+// the content of a well-formed file.
+func isCSVError(err error) bool {
+	var parseErr *csv.ParseError
+	return errors.As(err, &parseErr)
+}
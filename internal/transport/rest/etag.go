@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	errIfMatchMissing = errors.New("If-Match header is required")
+	errIfMatchInvalid = errors.New("If-Match must be * or a list of ETags")
+	errIfMatchFailed  = errors.New("no ETag in If-Match matches the current version")
+)
+
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatch is a parsed If-Match header (RFC 9110, section 13.1.1). any is set
+// for "*"; otherwise versions holds the versions named by the strong ETags in
+// the list. Weak ETags never match, as If-Match uses the strong comparison,
+// and neither do ETags this service does not issue.
+type ifMatch struct {
+	any      bool
+	versions []int
+}
+
+// parseIfMatch parses the If-Match header. It fails with errIfMatchMissing if
+// there is none and with errIfMatchInvalid if it is not valid syntax.
+func parseIfMatch(r *http.Request) (ifMatch, error) {
+	value := strings.TrimSpace(strings.Join(r.Header.Values("If-Match"), ","))
+	if value == "" {
+		return ifMatch{}, errIfMatchMissing
+	}
+	if value == "*" {
+		return ifMatch{any: true}, nil
+	}
+
+	var m ifMatch
+	for value != "" {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			break
+		}
+		weak := strings.HasPrefix(value, "W/")
+		if weak {
+			value = value[2:]
+		}
+		if !strings.HasPrefix(value, `"`) {
+			return ifMatch{}, errIfMatchInvalid
+		}
+		end := strings.IndexByte(value[1:], '"')
+		if end < 0 {
+			return ifMatch{}, errIfMatchInvalid
+		}
+		tag := value[1 : end+1]
+		value = strings.TrimLeft(value[end+2:], " \t")
+		if value != "" && value[0] != ',' {
+			return ifMatch{}, errIfMatchInvalid
+		}
+
+		if version, err := strconv.Atoi(tag); !weak && err == nil && version > 0 && strconv.Itoa(version) == tag {
+			m.versions = append(m.versions, version)
+		}
+	}
+	return m, nil
+}
+
+// version returns the version a write must be guarded by, given the current
+// one: 0 for "*", which skips the version check, or the version the client
+// expects. With several ETags listed, the current version is used if it is
+// among them. errIfMatchFailed is returned if no listed ETag can match.
+func (m ifMatch) version(current func() (int, error)) (int, error) {
+	switch {
+	case m.any:
+		return 0, nil
+	case len(m.versions) == 0:
+		return 0, errIfMatchFailed
+	case len(m.versions) == 1:
+		return m.versions[0], nil
+	}
+
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range m.versions {
+		if v == version {
+			return version, nil
+		}
+	}
+	return 0, errIfMatchFailed
+}
+
+// respondWithIfMatchError answers 428 when the precondition is missing, 412
+// when it cannot match and 400 when it cannot be parsed. Other errors come
+// from reading the current version.
+func respondWithIfMatchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errIfMatchMissing):
+		respondWithError(w, http.StatusPreconditionRequired, err.Error())
+	case errors.Is(err, errIfMatchFailed):
+		respondWithError(w, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, errIfMatchInvalid):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithDomainError(w, err)
+	}
+}
@@ -0,0 +1,1478 @@
+package rest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/auth"
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
+	"github.com/Hexes-rgb/employee-service/internal/service"
+	"github.com/Hexes-rgb/employee-service/internal/transport/rest"
+	"github.com/Hexes-rgb/employee-service/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter wires the real services to a fresh in-memory store.
+func newTestRouter() http.Handler {
+	return newTestRouterWithVerifier(nil)
+}
+
+// newTestRouterWithVerifier is newTestRouter with authentication required.
+func newTestRouterWithVerifier(verifier rest.TokenVerifier) http.Handler {
+	store := memory.NewStore()
+	empRepo := memory.NewEmployeeRepo(store)
+	deptRepo := memory.NewDepartmentRepo(store)
+	companyRepo := memory.NewCompanyRepo(store)
+
+	outboxRepo := memory.NewOutboxRepo(store)
+
+	empService := service.NewEmployeeService(empRepo, deptRepo, companyRepo, memory.NewAuditRepo(store), outboxRepo, memory.NewAssignmentRepo(store), memory.NewDocumentRepo(store), store)
+
+	return rest.NewRouter(
+		empService,
+		service.NewDepartmentService(deptRepo, companyRepo, outboxRepo, store),
+		service.NewCompanyService(companyRepo),
+		service.NewImportService(empService, companyRepo, store),
+		service.NewWebhookService(memory.NewWebhookRepo(store), companyRepo),
+		service.NewDocumentService(memory.NewDocumentRepo(store), empRepo, companyRepo, store),
+		verifier,
+		time.Second,
+	)
+}
+
+func doRequest(t *testing.T, router http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return doRequestWithHeaders(t, router, method, path, body, nil)
+}
+
+func doRequestWithHeaders(t *testing.T, router http.Handler, method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+}
+
+func createCompany(t *testing.T, router http.Handler) int {
+	t.Helper()
+
+	rec := doRequest(t, router, http.MethodPost, "/companies", map[string]interface{}{
+		"legalName":      "Acme LLC",
+		"taxId":          "7701234567",
+		"defaultCountry": "RU",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var resp rest.IDResponse
+	decode(t, rec, &resp)
+	return resp.ID
+}
+
+func employeeBody(companyID int, phone, passport string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":           "John",
+		"surname":        "Doe",
+		"phone":          phone,
+		"companyId":      companyID,
+		"passportType":   "internal",
+		"passportNumber": passport,
+		"department": map[string]interface{}{
+			"companyId": companyID,
+			"name":      "Engineering",
+			"phone":     "+100",
+		},
+	}
+}
+
+func TestEmployeeHandlers_ErrorMapping(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          interface{}
+		expectedCode  int
+		expectedField string
+	}{
+		{
+			name:          "Error: duplicate phone is a conflict",
+			method:        http.MethodPost,
+			path:          "/employees",
+			body:          employeeBody(companyID, "+1", "2"),
+			expectedCode:  http.StatusConflict,
+			expectedField: "phone",
+		},
+		{
+			name:          "Error: missing field is a validation error",
+			method:        http.MethodPost,
+			path:          "/employees",
+			body:          map[string]interface{}{"surname": "Doe"},
+			expectedCode:  http.StatusUnprocessableEntity,
+			expectedField: "name",
+		},
+		{
+			name:          "Error: unknown company is a validation error",
+			method:        http.MethodPost,
+			path:          "/employees",
+			body:          employeeBody(9999, "+2", "2"),
+			expectedCode:  http.StatusUnprocessableEntity,
+			expectedField: "companyId",
+		},
+		{
+			name:         "Error: unknown employee is not found",
+			method:       http.MethodGet,
+			path:         "/employees/999",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Error: deleting without If-Match is refused",
+			method:       http.MethodDelete,
+			path:         "/employees/999",
+			expectedCode: http.StatusPreconditionRequired,
+		},
+		{
+			name:         "Error: malformed ID is a bad request",
+			method:       http.MethodGet,
+			path:         "/employees/abc",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, router, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, tt.expectedCode, rec.Code, rec.Body.String())
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+			var problem rest.ProblemDetails
+			decode(t, rec, &problem)
+			assert.Equal(t, tt.expectedCode, problem.Status)
+			assert.Equal(t, tt.expectedField, problem.Field)
+		})
+	}
+}
+
+// failingCompanies fails every ListCompanies call with err.
+type failingCompanies struct {
+	rest.CompanyService
+	err error
+}
+
+func (c failingCompanies) ListCompanies(ctx context.Context) ([]*domain.Company, error) {
+	return nil, c.err
+}
+
+func TestErrorMapping_HidesInternalErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedCode   int
+		expectedDetail string
+	}{
+		{
+			name:           "unexpected error",
+			err:            errors.New(`failed to list companies: pq: relation "companies" does not exist`),
+			expectedCode:   http.StatusInternalServerError,
+			expectedDetail: "internal server error",
+		},
+		{
+			name:           "storage outage",
+			err:            domain.NewUnavailableError(errors.New("dial tcp 10.0.0.5:5432: connection refused")),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedDetail: "storage unavailable, retry later",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := rest.NewRouter(nil, nil, failingCompanies{err: tt.err}, nil, nil, nil, nil, time.Second)
+
+			rec := doRequest(t, router, http.MethodGet, "/companies", nil)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			var problem rest.ProblemDetails
+			decode(t, rec, &problem)
+			assert.Equal(t, tt.expectedDetail, problem.Detail)
+		})
+	}
+}
+
+func TestEmployeeHandlers_DepartmentOfAnotherCompany(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequest(t, router, http.MethodPost, "/companies", map[string]interface{}{
+		"legalName": "Globex LLC", "taxId": "7709876543", "defaultCountry": "RU",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var other rest.IDResponse
+	decode(t, rec, &other)
+
+	rec = doRequest(t, router, http.MethodPost, "/departments", map[string]interface{}{
+		"companyId": other.ID, "name": "Sales", "phone": "+900",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var foreign rest.IDResponse
+	decode(t, rec, &foreign)
+
+	violation := func(t *testing.T, rec *httptest.ResponseRecorder) string {
+		t.Helper()
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		var problem rest.ProblemDetails
+		decode(t, rec, &problem)
+		return problem.Field
+	}
+
+	body := employeeBody(companyID, "+1", "1")
+	body["department"].(map[string]interface{})["companyId"] = other.ID
+	rec = doRequest(t, router, http.MethodPost, "/employees", body)
+	assert.Equal(t, "department.companyId", violation(t, rec))
+
+	rec = doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created rest.IDResponse
+	decode(t, rec, &created)
+	path := "/employees/" + strconv.Itoa(created.ID)
+
+	patches := []struct {
+		contentType, body string
+	}{
+		{"application/merge-patch+json", fmt.Sprintf(`{"departmentId":%d}`, foreign.ID)},
+		{"application/merge-patch+json", fmt.Sprintf(`{"companyId":%d}`, other.ID)},
+		{"application/json-patch+json", fmt.Sprintf(`[{"op":"replace","path":"/departmentId","value":%d}]`, foreign.ID)},
+	}
+	for _, p := range patches {
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(p.body))
+		req.Header.Set("Content-Type", p.contentType)
+		req.Header.Set("If-Match", "*")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, "departmentId", violation(t, rec), p.body)
+	}
+}
+
+func TestEmployeeHandlers_ValidationErrors(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	body := employeeBody(companyID, "+1", strings.Repeat("9", 51))
+	body["name"] = ""
+	body["department"].(map[string]interface{})["phone"] = "+7 (999) 000-00-00 ext. 1"
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", body)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	var problem rest.ProblemDetails
+	decode(t, rec, &problem)
+	assert.Equal(t, "name", problem.Field)
+	assert.Equal(t, []validation.Violation{
+		{Field: "/name", Code: validation.CodeRequired, Message: "employee name is required"},
+		{Field: "/passportNumber", Code: validation.CodeTooLong, Message: "employee passport number must be at most 50 characters"},
+		{Field: "/department/phone", Code: validation.CodeTooLong, Message: "department phone must be at most 20 characters"},
+	}, problem.Errors)
+
+	rec = doRequest(t, router, http.MethodPost, "/departments", map[string]interface{}{
+		"companyId": companyID, "name": strings.Repeat("x", 256), "phone": "+100",
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	decode(t, rec, &problem)
+	assert.Equal(t, []validation.Violation{
+		{Field: "/name", Code: validation.CodeTooLong, Message: "department name must be at most 255 characters"},
+	}, problem.Errors)
+
+	var badRequest rest.ProblemDetails
+	rec = doRequest(t, router, http.MethodGet, "/employees/abc", nil)
+	decode(t, rec, &badRequest)
+	assert.Empty(t, badRequest.Errors)
+}
+
+func TestEmployeeHandlers_OptimisticConcurrency(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created rest.IDResponse
+	decode(t, rec, &created)
+	path := "/employees/" + strconv.Itoa(created.ID)
+
+	rec = doRequest(t, router, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	original := rec.Header().Get("ETag")
+	assert.Equal(t, `"1"`, original)
+
+	rename := func(name string) map[string]interface{} {
+		body := employeeBody(companyID, "+1", "1")
+		body["name"] = name
+		return body
+	}
+
+	t.Run("Success: update with current ETag returns the next one", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path, rename("Jack"),
+			map[string]string{"If-Match": original})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("Error: update with stale ETag fails the precondition", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path, rename("Jill"),
+			map[string]string{"If-Match": original})
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodGet, path, nil)
+		var emp domain.Employee
+		decode(t, rec, &emp)
+		assert.Equal(t, "Jack", emp.Name)
+	})
+
+	t.Run("Error: update without If-Match is refused", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPatch, path, rename("Jill"))
+		assert.Equal(t, http.StatusPreconditionRequired, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: malformed If-Match is a bad request", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path, rename("Jill"),
+			map[string]string{"If-Match": "2"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: weak ETag never matches", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path, rename("Jill"),
+			map[string]string{"If-Match": `W/"2"`})
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: list without the current ETag fails the precondition", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path, rename("Jill"),
+			map[string]string{"If-Match": `"1", W/"2", "02", "other"`})
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Success: list with the current ETag", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path, rename("Jack"),
+			map[string]string{"If-Match": `"1", "2"`})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("Error: delete with stale ETag fails the precondition", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodDelete, path, nil, map[string]string{"If-Match": original})
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Success: delete with current ETag", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodDelete, path, nil, map[string]string{"If-Match": `"3"`})
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: deleting unknown employee is not found", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodDelete, path, nil, map[string]string{"If-Match": "*"})
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+}
+
+func TestEmployeeHandlers_SoftDelete(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created rest.IDResponse
+	decode(t, rec, &created)
+	path := "/employees/" + strconv.Itoa(created.ID)
+	list := "/companies/" + strconv.Itoa(companyID) + "/employees"
+
+	rec = doRequestWithHeaders(t, router, http.MethodDelete, path, nil, map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(t, router, http.MethodGet, list, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `[]`, mustField(t, rec, "items"))
+
+	rec = doRequest(t, router, http.MethodGet, list+"?includeDeleted=true", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page struct {
+		Items []domain.Employee `json:"items"`
+	}
+	decode(t, rec, &page)
+	require.Len(t, page.Items, 1)
+	assert.NotNil(t, page.Items[0].DeletedAt)
+
+	rec = doRequest(t, router, http.MethodGet, list+"?includeDeleted=maybe", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = doRequest(t, router, http.MethodPost, path+"/restore", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+	rec = doRequest(t, router, http.MethodPost, path+"/restore", nil)
+	assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+}
+
+func TestEmployeeHandlers_History(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequestWithHeaders(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"),
+		map[string]string{"X-Actor": "alice", "X-Request-ID": "req-1"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "req-1", rec.Header().Get("X-Request-ID"))
+	var created rest.IDResponse
+	decode(t, rec, &created)
+	path := "/employees/" + strconv.Itoa(created.ID)
+
+	rec = doRequestWithHeaders(t, router, http.MethodPatch, path, map[string]interface{}{"departmentId": nil},
+		map[string]string{"If-Match": "*"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	generatedID := rec.Header().Get("X-Request-ID")
+	assert.Len(t, generatedID, 32)
+
+	rec = doRequestWithHeaders(t, router, http.MethodDelete, path, nil, map[string]string{"If-Match": "*"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodGet, path+"/history", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var history rest.HistoryResponse
+	decode(t, rec, &history)
+	require.Len(t, history.Items, 3)
+	assert.NoError(t, domain.VerifyAuditChain(history.Items))
+
+	assert.Equal(t, "unverified:alice", history.Items[0].Actor, "X-Actor is not trusted")
+	assert.Equal(t, "req-1", history.Items[0].RequestID)
+	assert.Equal(t, "anonymous", history.Items[1].Actor)
+	assert.Equal(t, generatedID, history.Items[1].RequestID)
+	require.Len(t, history.Items[1].Changes, 1)
+	assert.Equal(t, "departmentId", history.Items[1].Changes[0].Field)
+	assert.Equal(t, domain.AuditDelete, history.Items[2].Action)
+
+	rec = doRequest(t, router, http.MethodGet, "/employees/9999/history", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestEmployeeHandlers_Patch(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created rest.IDResponse
+	decode(t, rec, &created)
+	path := "/employees/" + strconv.Itoa(created.ID)
+
+	patch := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("If-Match", "*")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func() domain.Employee {
+		var emp domain.Employee
+		decode(t, doRequest(t, router, http.MethodGet, path, nil), &emp)
+		return emp
+	}
+
+	t.Run("Success: merge patch null detaches the department", func(t *testing.T) {
+		rec := patch("application/merge-patch+json", `{"departmentId":null,"passportType":null}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		emp := get()
+		assert.Nil(t, emp.DepartmentID)
+		assert.Nil(t, emp.Department)
+		assert.Empty(t, emp.PassportType)
+		assert.Equal(t, "John", emp.Name)
+	})
+
+	t.Run("Success: JSON Patch", func(t *testing.T) {
+		rec := patch("application/json-patch+json",
+			`[{"op":"test","path":"/name","value":"John"},{"op":"replace","path":"/name","value":"Jack"}]`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "Jack", get().Name)
+	})
+
+	t.Run("Error: failed JSON Patch test", func(t *testing.T) {
+		rec := patch("application/json-patch+json", `[{"op":"test","path":"/name","value":"John"}]`)
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: resulting document is invalid", func(t *testing.T) {
+		rec := patch("application/merge-patch+json", `{"surname":null}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: unsupported media type", func(t *testing.T) {
+		rec := patch("text/plain", `name=Jack`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code, rec.Body.String())
+	})
+}
+
+func TestEmployeeHandlers_GetCompanyEmployees(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	for i, phone := range []string{"+1", "+2", "+3"} {
+		rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, phone, string(rune('a'+i))))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	type page struct {
+		Company struct {
+			LegalName string `json:"legalName"`
+		} `json:"company"`
+		Items []struct {
+			ID         int `json:"id"`
+			Department struct {
+				Name string `json:"name"`
+			} `json:"department"`
+		} `json:"items"`
+		NextCursor string `json:"nextCursor"`
+		Total      int    `json:"total"`
+	}
+
+	rec := doRequest(t, router, http.MethodGet, "/companies/1/employees?limit=2&includeTotal=true", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var first page
+	decode(t, rec, &first)
+	assert.Equal(t, "Acme LLC", first.Company.LegalName)
+	assert.Len(t, first.Items, 2)
+	assert.Equal(t, "Engineering", first.Items[0].Department.Name)
+	assert.Equal(t, 3, first.Total)
+	require.NotEmpty(t, first.NextCursor)
+
+	rec = doRequest(t, router, http.MethodGet, "/companies/1/employees?limit=2&cursor="+first.NextCursor, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var second page
+	decode(t, rec, &second)
+	assert.Len(t, second.Items, 1)
+	assert.Empty(t, second.NextCursor)
+
+	rec = doRequest(t, router, http.MethodGet, "/companies/1/employees?departmentId=null", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `[]`, mustField(t, rec, "items"))
+
+	rec = doRequest(t, router, http.MethodGet, "/companies/1/employees?limit=0", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestImportHandlers_ImportEmployees(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+	path := "/companies/" + strconv.Itoa(companyID) + "/employees/import"
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	upload := func(query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	count := func() int {
+		rec := doRequest(t, router, http.MethodGet, "/companies/"+strconv.Itoa(companyID)+"/employees?includeTotal=true", nil)
+		var page struct {
+			Total int `json:"total"`
+		}
+		decode(t, rec, &page)
+		return page.Total
+	}
+
+	file := "Name,Surname,Phone,passport_number,Department.Name,Department.Phone\n" +
+		"Jane,Roe,+2,2,Engineering,+100\n" +
+		"John,Doe,+1,1,Engineering,+100\n" +
+		"Jim,,+3,3,Engineering,+100\n"
+
+	t.Run("Success: all-or-nothing with an invalid row imports nothing", func(t *testing.T) {
+		rec := upload("", file)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report domain.ImportReport
+		decode(t, rec, &report)
+		assert.True(t, report.RolledBack)
+		assert.Equal(t, 2, report.Skipped)
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, domain.ImportRowResult{Line: 4, Status: domain.ImportFailed, Field: "surname", Reason: "employee surname is required"}, report.Rows[2])
+		assert.Equal(t, 1, count())
+	})
+
+	t.Run("Success: all-or-nothing rolls back on a duplicate", func(t *testing.T) {
+		rec := upload("", strings.Join(strings.Split(file, "\n")[:3], "\n"))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report domain.ImportReport
+		decode(t, rec, &report)
+		assert.True(t, report.RolledBack)
+		assert.Equal(t, domain.ImportSkipped, report.Rows[0].Status)
+		assert.Equal(t, "phone", report.Rows[1].Field)
+		assert.Equal(t, 1, count())
+	})
+
+	t.Run("Success: best-effort creates, skips and fails rows", func(t *testing.T) {
+		rec := upload("?mode=best-effort", file)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report domain.ImportReport
+		decode(t, rec, &report)
+		assert.False(t, report.RolledBack)
+		assert.Equal(t, []domain.ImportRowStatus{domain.ImportCreated, domain.ImportSkipped, domain.ImportFailed},
+			[]domain.ImportRowStatus{report.Rows[0].Status, report.Rows[1].Status, report.Rows[2].Status})
+		assert.NotZero(t, report.Rows[0].EmployeeID)
+		assert.Equal(t, 2, count())
+	})
+
+	t.Run("Success: background job reports when done", func(t *testing.T) {
+		rec := upload("?async=true&delimiter=%3B", "name;surname;phone;passportNumber;departmentName;departmentPhone\nAnn;Lee;+4;4;Engineering;+100\n")
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+		var job domain.ImportJob
+		decode(t, rec, &job)
+		location := rec.Header().Get("Location")
+		assert.Equal(t, path+"/"+job.ID, location)
+
+		require.Eventually(t, func() bool {
+			decode(t, doRequest(t, router, http.MethodGet, location, nil), &job)
+			return job.Status != domain.ImportJobRunning
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, domain.ImportJobCompleted, job.Status)
+		require.NotNil(t, job.Report)
+		assert.Equal(t, 1, job.Report.Created)
+		assert.Equal(t, 3, count())
+
+		rec = doRequest(t, router, http.MethodGet, path+"/unknown", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Error: file problems", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			query        string
+			body         string
+			expectedCode int
+		}{
+			{"unknown column", "", "name,surname,salary\n", http.StatusUnprocessableEntity},
+			{"missing column", "", "name,surname,phone\nJane,Roe,+5\n", http.StatusUnprocessableEntity},
+			{"no rows", "", "name,surname,phone,passportNumber,departmentName,departmentPhone\n", http.StatusUnprocessableEntity},
+			{"malformed CSV", "", "name,\"surname\n", http.StatusBadRequest},
+			{"unknown mode", "?mode=some", file, http.StatusUnprocessableEntity},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rec := upload(tt.query, tt.body)
+				assert.Equal(t, tt.expectedCode, rec.Code, rec.Body.String())
+			})
+		}
+	})
+}
+
+func TestEmployeeHandlers_ExportEmployees(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+	path := "/companies/" + strconv.Itoa(companyID) + "/employees/export"
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "4510123456"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	body := employeeBody(companyID, "+2", "99")
+	body["name"] = "Jane, Jr."
+	body["surname"] = `=HYPERLINK("http://evil.example")`
+	rec = doRequest(t, router, http.MethodPost, "/employees", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = doRequestWithHeaders(t, router, http.MethodPatch, "/employees/2", map[string]interface{}{"departmentId": nil},
+		map[string]string{"If-Match": "*"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	t.Run("Success: CSV with department columns and formulas escaped", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "id,name,surname,phone,companyId,passportType,passportNumber,departmentId,departmentName,departmentPhone\n"+
+			"1,John,Doe,+1,1,internal,4510123456,1,Engineering,+100\n"+
+			"2,\"Jane, Jr.\",\"'=HYPERLINK(\"\"http://evil.example\"\")\",+2,1,internal,99,,,\n", rec.Body.String())
+	})
+
+	t.Run("Success: CSV without escaping", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, path+"?fields=surname,phone&escapeFormulas=false", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "surname,phone\nDoe,+1\n\"=HYPERLINK(\"\"http://evil.example\"\")\",+2\n", rec.Body.String())
+	})
+
+	t.Run("Success: NDJSON with selected fields and masked passports", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, path+"?format=ndjson&fields=id,passportNumber,departmentName&maskPassport=true", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `{"id":1,"passportNumber":"******3456","departmentName":"Engineering"}`+"\n"+
+			`{"id":2,"passportNumber":"**","departmentName":null}`+"\n", rec.Body.String())
+	})
+
+	t.Run("Success: filters apply", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, path+"?fields=id&departmentId=null", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "id\n2\n", rec.Body.String())
+	})
+
+	t.Run("Error: bad requests", func(t *testing.T) {
+		for query, code := range map[string]int{
+			"?format=xml":           http.StatusUnprocessableEntity,
+			"?fields=id,salary":     http.StatusUnprocessableEntity,
+			"?maskPassport=maybe":   http.StatusUnprocessableEntity,
+			"?escapeFormulas=maybe": http.StatusUnprocessableEntity,
+		} {
+			rec := doRequest(t, router, http.MethodGet, path+query, nil)
+			assert.Equal(t, code, rec.Code, query)
+		}
+
+		rec := doRequest(t, router, http.MethodGet, "/companies/9999/employees/export", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestEmployeeHandlers_ExportImportRoundTrip(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	body := employeeBody(companyID, "+79991234567", "4510123456")
+	body["surname"] = "+Doe"
+	rec := doRequest(t, router, http.MethodPost, "/employees", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodGet, "/companies/"+strconv.Itoa(companyID)+
+		"/employees/export?fields=name,surname,phone,passportType,passportNumber,departmentName,departmentPhone", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The file goes into a fresh service, where the phones are still free.
+	target := newTestRouter()
+	targetID := createCompany(t, target)
+	req := httptest.NewRequest(http.MethodPost, "/companies/"+strconv.Itoa(targetID)+"/employees/import", rec.Body)
+	req.Header.Set("Content-Type", "text/csv")
+	rec = httptest.NewRecorder()
+	target.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var report domain.ImportReport
+	decode(t, rec, &report)
+	require.Equal(t, 1, report.Created, rec.Body.String())
+
+	rec = doRequest(t, target, http.MethodGet, "/employees/"+strconv.Itoa(report.Rows[0].EmployeeID), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var emp domain.Employee
+	decode(t, rec, &emp)
+	assert.Equal(t, "+79991234567", emp.Phone)
+	assert.Equal(t, "'+Doe", emp.Surname, "other formulas stay escaped")
+	require.NotNil(t, emp.Department)
+	assert.Equal(t, "+100", emp.Department.Phone)
+}
+
+func TestDepartmentHandlers_Lifecycle(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequest(t, router, http.MethodPost, "/departments", map[string]interface{}{
+		"companyId": companyID, "name": "HR", "phone": "+100",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodPatch, "/departments/1", map[string]interface{}{"name": "People"})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodPatch, "/departments/1", map[string]interface{}{})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodGet, "/companies/1/departments", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `[{"id":1,"companyId":1,"name":"People","phone":"+100","parentId":null,"employeeCount":0}]`, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodDelete, "/departments/1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, router, http.MethodGet, "/departments/1", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+	for _, path := range []string{"/companies/9999/departments", "/companies/9999/departments/tree"} {
+		rec = doRequest(t, router, http.MethodGet, path, nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, path+": "+rec.Body.String())
+	}
+}
+
+func TestWebhookHandlers_Subscriptions(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+	base := fmt.Sprintf("/companies/%d/webhooks", companyID)
+
+	rec := doRequest(t, router, http.MethodPost, base, map[string]interface{}{
+		"url":        "https://partner.example.com/hooks",
+		"eventTypes": []string{"EmployeeCreated", "EmployeeDeleted"},
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created domain.WebhookSubscription
+	decode(t, rec, &created)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	assert.True(t, created.Active)
+	path := fmt.Sprintf("%s/%d", base, created.ID)
+
+	t.Run("Success: secret is not returned again", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Empty(t, mustField(t, rec, "secret"))
+
+		rec = doRequest(t, router, http.MethodGet, base, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.NotContains(t, rec.Body.String(), created.Secret)
+	})
+
+	t.Run("Success: patch changes filters and deactivates", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPatch, path, map[string]interface{}{
+			"eventTypes": []string{}, "active": false,
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `[]`, mustField(t, rec, "eventTypes"))
+		assert.Equal(t, "false", mustField(t, rec, "active"))
+	})
+
+	t.Run("Error: invalid subscriptions", func(t *testing.T) {
+		for field, body := range map[string]map[string]interface{}{
+			"url":        {"url": "ftp://partner.example.com"},
+			"secret":     {"url": "https://partner.example.com", "secret": "short"},
+			"eventTypes": {"url": "https://partner.example.com", "eventTypes": []string{"EmployeeFired"}},
+		} {
+			rec := doRequest(t, router, http.MethodPost, base, body)
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+			assert.Equal(t, `"`+field+`"`, mustField(t, rec, "field"))
+		}
+
+		for _, url := range []string{
+			"http://169.254.169.254/latest/meta-data",
+			"http://localhost:8080/hooks",
+			"http://127.0.0.1/hooks",
+			"http://10.0.0.5/hooks",
+			"http://[::1]/hooks",
+			"http://[::ffff:192.168.0.1]/hooks",
+		} {
+			rec := doRequest(t, router, http.MethodPost, base, map[string]interface{}{"url": url})
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, url)
+			assert.Equal(t, `"url"`, mustField(t, rec, "field"), url)
+		}
+	})
+
+	t.Run("Error: subscription of another company", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, fmt.Sprintf("/companies/%d/webhooks/%d", companyID+1, created.ID), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: deliveries with unknown status", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, path+"/deliveries?status=lost", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Success: delete", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodDelete, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodGet, path+"/deliveries", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+}
+
+func mustField(t *testing.T, rec *httptest.ResponseRecorder, field string) string {
+	t.Helper()
+
+	var body map[string]json.RawMessage
+	decode(t, rec, &body)
+	return string(body[field])
+}
+
+func TestDocumentHandlers_Documents(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	var employees [2]int
+	for i := range employees {
+		rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, fmt.Sprintf("+%d", i+1), strconv.Itoa(i+1)))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var resp rest.IDResponse
+		decode(t, rec, &resp)
+		employees[i] = resp.ID
+	}
+	base := fmt.Sprintf("/employees/%d/documents", employees[0])
+	today := domain.Today()
+
+	create := func(t *testing.T, path string, body map[string]interface{}) domain.IdentityDocument {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodPost, path, body)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var doc domain.IdentityDocument
+		decode(t, rec, &doc)
+		return doc
+	}
+
+	passport := create(t, base, map[string]interface{}{
+		"type": "internal_passport", "number": "4510 123456", "issuingCountry": "RU",
+		"issuedOn": "2015-03-01",
+	})
+	permit := create(t, base, map[string]interface{}{
+		"type": "work_permit", "number": "4510 123456", "issuingCountry": "RU",
+		"expiresOn": today.AddDays(10).String(),
+	})
+	create(t, fmt.Sprintf("/employees/%d/documents", employees[1]), map[string]interface{}{
+		"type": "foreign_passport", "number": "75 1234567", "issuingCountry": "RU",
+		"expiresOn": today.AddDays(40).String(),
+	})
+
+	t.Run("Success: documents of the employee", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, base, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var list rest.DocumentListResponse
+		decode(t, rec, &list)
+		require.Len(t, list.Items, 3)
+		assert.Equal(t, "1", list.Items[0].Number, "created with the employee")
+		assert.True(t, list.Items[0].Primary)
+		assert.Equal(t, passport.ID, list.Items[1].ID)
+		assert.False(t, list.Items[1].Primary)
+		assert.Equal(t, "2015-03-01", list.Items[1].IssuedOn.String())
+		assert.False(t, list.Items[2].Primary)
+	})
+
+	t.Run("Error: primary document changes only with the employee", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, base, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var list rest.DocumentListResponse
+		decode(t, rec, &list)
+		primary := list.Items[0]
+		primaryPath := fmt.Sprintf("%s/%d", base, primary.ID)
+
+		rec = doRequest(t, router, http.MethodPost, base, map[string]interface{}{
+			"type": "visa", "number": "V-1", "issuingCountry": "RU", "primary": true,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"primary"`, mustField(t, rec, "field"))
+
+		rec = doRequest(t, router, http.MethodPut, fmt.Sprintf("%s/%d", base, permit.ID), map[string]interface{}{
+			"type": "work_permit", "number": "4510 123456", "issuingCountry": "RU", "primary": true,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"primary"`, mustField(t, rec, "field"))
+
+		rec = doRequest(t, router, http.MethodPut, primaryPath, map[string]interface{}{
+			"type": primary.Type, "number": "999", "issuingCountry": "RU", "primary": true,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"number"`, mustField(t, rec, "field"))
+
+		rec = doRequest(t, router, http.MethodDelete, primaryPath, nil)
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodPut, primaryPath, map[string]interface{}{
+			"type": primary.Type, "number": primary.Number, "issuingCountry": "KZ", "primary": true,
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodGet, fmt.Sprintf("/employees/%d", employees[0]), nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `"1"`, mustField(t, rec, "passportNumber"))
+	})
+
+	t.Run("Success: employee passport is the primary document", func(t *testing.T) {
+		path := fmt.Sprintf("/employees/%d", employees[1])
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path,
+			map[string]interface{}{"passportType": "foreign_passport", "passportNumber": "75 7654321"}, map[string]string{"If-Match": "*"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodGet, path+"/documents", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var list rest.DocumentListResponse
+		decode(t, rec, &list)
+		require.Len(t, list.Items, 2)
+		assert.True(t, list.Items[0].Primary)
+		assert.Equal(t, "foreign_passport", list.Items[0].Type)
+		assert.Equal(t, "75 7654321", list.Items[0].Number)
+		assert.Equal(t, "RU", list.Items[0].IssuingCountry)
+		assert.False(t, list.Items[1].Primary)
+
+		// The same number is free for another type of document.
+		body := employeeBody(companyID, "+3", "75 7654321")
+		rec = doRequest(t, router, http.MethodPost, "/employees", body)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		body = employeeBody(companyID, "+4", "75 7654321")
+		body["passportType"] = "foreign_passport"
+		rec = doRequest(t, router, http.MethodPost, "/employees", body)
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+		assert.Equal(t, `"passportNumber"`, mustField(t, rec, "field"))
+	})
+
+	t.Run("Error: type and number are unique together", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPost, fmt.Sprintf("/employees/%d/documents", employees[1]), map[string]interface{}{
+			"type": "work_permit", "number": "4510 123456", "issuingCountry": "KZ",
+		})
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+		assert.Equal(t, `"number"`, mustField(t, rec, "field"))
+	})
+
+	t.Run("Error: invalid document", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPost, base, map[string]interface{}{
+			"type": "visa", "number": "1", "issuingCountry": "Russia",
+			"issuedOn": "2020-01-02", "expiresOn": "2020-01-01",
+		})
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+		var problem rest.ProblemDetails
+		decode(t, rec, &problem)
+		require.Len(t, problem.Errors, 2)
+		assert.Equal(t, "/issuingCountry", problem.Errors[0].Field)
+		assert.Equal(t, "/expiresOn", problem.Errors[1].Field)
+	})
+
+	t.Run("Success: expiring documents of the company", func(t *testing.T) {
+		path := fmt.Sprintf("/companies/%d/documents/expiring", companyID)
+
+		rec := doRequest(t, router, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var expiring rest.ExpiringDocumentListResponse
+		decode(t, rec, &expiring)
+		require.Len(t, expiring.Items, 1)
+		assert.Equal(t, permit.ID, expiring.Items[0].ID)
+		assert.Equal(t, "John", expiring.Items[0].EmployeeName)
+
+		rec = doRequest(t, router, http.MethodGet, path+"?days=60", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		decode(t, rec, &expiring)
+		assert.Len(t, expiring.Items, 2)
+
+		rec = doRequest(t, router, http.MethodGet, path+"?days=0", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Success: replace and delete", func(t *testing.T) {
+		path := fmt.Sprintf("%s/%d", base, passport.ID)
+		rec := doRequest(t, router, http.MethodPut, path, map[string]interface{}{
+			"type": "internal_passport", "number": "4520 654321", "issuingCountry": "RU",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "null", mustField(t, rec, "issuedOn"))
+
+		rec = doRequest(t, router, http.MethodDelete, path, nil)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		rec = doRequest(t, router, http.MethodGet, path, nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: document of another employee", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodGet, fmt.Sprintf("/employees/%d/documents/%d", employees[1], permit.ID), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+}
+
+func TestEmployeeHandlers_Transfers(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	rec := doRequest(t, router, http.MethodPost, "/employees", employeeBody(companyID, "+1", "1"))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created rest.IDResponse
+	decode(t, rec, &created)
+	path := "/employees/" + strconv.Itoa(created.ID)
+
+	rec = doRequest(t, router, http.MethodPost, "/departments", map[string]interface{}{
+		"companyId": companyID, "name": "HR", "phone": "+200",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var hr rest.IDResponse
+	decode(t, rec, &hr)
+
+	today := domain.Today()
+	later := today.AddDays(5)
+
+	departmentEmployees := func(t *testing.T, deptID int, asOf domain.Date) int {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodGet,
+			fmt.Sprintf("/companies/%d/departments/%d/employees?asOf=%s", companyID, deptID, asOf), nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page struct {
+			Items []domain.Employee `json:"items"`
+		}
+		decode(t, rec, &page)
+		return len(page.Items)
+	}
+
+	t.Run("Success: a transfer effective today moves the employee", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPost, path+"/transfers", map[string]interface{}{"departmentId": hr.ID})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var assignment domain.Assignment
+		decode(t, rec, &assignment)
+		assert.Equal(t, hr.ID, assignment.DepartmentID)
+		assert.Equal(t, today.String(), assignment.StartDate.String())
+
+		rec = doRequest(t, router, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, strconv.Itoa(hr.ID), mustField(t, rec, "departmentId"))
+	})
+
+	t.Run("Success: a future transfer stays pending", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPost, path+"/transfers", map[string]interface{}{
+			"departmentId": 1, "effectiveDate": later.String(),
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodGet, path+"/assignments", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var list rest.AssignmentListResponse
+		decode(t, rec, &list)
+		require.Len(t, list.Items, 2)
+		assert.Equal(t, hr.ID, list.Items[0].DepartmentID)
+		require.NotNil(t, list.Items[0].EndDate)
+		assert.Equal(t, later.String(), list.Items[0].EndDate.String())
+		assert.Equal(t, 1, list.Items[1].DepartmentID)
+		assert.Nil(t, list.Items[1].EndDate)
+
+		rec = doRequest(t, router, http.MethodGet, path, nil)
+		assert.Equal(t, strconv.Itoa(hr.ID), mustField(t, rec, "departmentId"))
+	})
+
+	t.Run("Success: department employees as of a date", func(t *testing.T) {
+		assert.Equal(t, 1, departmentEmployees(t, hr.ID, today))
+		assert.Equal(t, 0, departmentEmployees(t, 1, today))
+		assert.Equal(t, 0, departmentEmployees(t, hr.ID, later))
+		assert.Equal(t, 1, departmentEmployees(t, 1, later))
+		assert.Equal(t, 0, departmentEmployees(t, hr.ID, today.AddDays(-1)))
+	})
+
+	t.Run("Error: department changes conflict with a pending transfer", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodPatch, path, map[string]interface{}{"departmentId": nil},
+			map[string]string{"If-Match": "*"})
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodPost, path+"/transfers", map[string]interface{}{
+			"departmentId": hr.ID, "effectiveDate": today.AddDays(2).String(),
+		})
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: invalid transfer", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPost, path+"/transfers", map[string]interface{}{
+			"departmentId": 1, "effectiveDate": today.AddDays(-1).String(),
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"effectiveDate"`, mustField(t, rec, "field"))
+
+		rec = doRequest(t, router, http.MethodPost, path+"/transfers", map[string]interface{}{})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"departmentId"`, mustField(t, rec, "field"))
+
+		rec = doRequest(t, router, http.MethodPost, "/employees/9999/transfers", map[string]interface{}{"departmentId": 1})
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+		rec = doRequest(t, router, http.MethodGet,
+			fmt.Sprintf("/companies/%d/departments/1/employees?asOf=tomorrow", companyID), nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	})
+}
+
+func TestEmployeeHandlers_Managers(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	create := func(t *testing.T, body map[string]interface{}) int {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodPost, "/employees", body)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var resp rest.IDResponse
+		decode(t, rec, &resp)
+		return resp.ID
+	}
+	patchManager := func(t *testing.T, id int, managerID interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		return doRequestWithHeaders(t, router, http.MethodPatch, "/employees/"+strconv.Itoa(id),
+			map[string]interface{}{"managerId": managerID}, map[string]string{"If-Match": "*"})
+	}
+	ids := func(t *testing.T, path string) []int {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var list rest.EmployeeListResponse
+		decode(t, rec, &list)
+		ids := []int{}
+		for _, emp := range list.Items {
+			ids = append(ids, emp.ID)
+		}
+		return ids
+	}
+
+	// ceo <- cto <- {dev1, dev2}
+	ceo := create(t, employeeBody(companyID, "+1", "1"))
+	cto := create(t, employeeBody(companyID, "+2", "2"))
+	require.Equal(t, http.StatusOK, patchManager(t, cto, ceo).Code)
+	devs := [2]int{}
+	for i := range devs {
+		body := employeeBody(companyID, fmt.Sprintf("+%d", i+3), strconv.Itoa(i+3))
+		body["managerId"] = cto
+		devs[i] = create(t, body)
+	}
+
+	t.Run("Success: reporting lines", func(t *testing.T) {
+		assert.Equal(t, []int{cto}, ids(t, fmt.Sprintf("/employees/%d/reports", ceo)))
+		assert.Equal(t, []int{cto, ceo}, ids(t, fmt.Sprintf("/employees/%d/chain", devs[1])))
+		assert.Equal(t, []int{}, ids(t, fmt.Sprintf("/employees/%d/chain", ceo)))
+
+		rec := doRequest(t, router, http.MethodGet, fmt.Sprintf("/employees/%d/subordinates", ceo), nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var list rest.SubordinateListResponse
+		decode(t, rec, &list)
+		require.Len(t, list.Items, 3)
+		assert.Equal(t, cto, list.Items[0].ID)
+		assert.Equal(t, 1, list.Items[0].Depth)
+		assert.Equal(t, devs[0], list.Items[1].ID)
+		assert.Equal(t, 2, list.Items[2].Depth)
+		require.NotNil(t, list.Items[2].ManagerID)
+		assert.Equal(t, cto, *list.Items[2].ManagerID)
+	})
+
+	t.Run("Error: invalid managers", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPost, "/companies", map[string]interface{}{
+			"legalName": "Other LLC", "taxId": "7709999999", "defaultCountry": "RU",
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var other rest.IDResponse
+		decode(t, rec, &other)
+		body := employeeBody(other.ID, "+9", "9")
+		body["department"] = map[string]interface{}{"companyId": other.ID, "name": "Sales", "phone": "+900"}
+		outsider := create(t, body)
+
+		for name, managerID := range map[string]int{
+			"cycle":         devs[0],
+			"self":          ceo,
+			"missing":       9999,
+			"other company": outsider,
+		} {
+			rec := patchManager(t, ceo, managerID)
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, name+": "+rec.Body.String())
+			assert.Equal(t, `"managerId"`, mustField(t, rec, "field"), name)
+		}
+
+		rec = doRequestWithHeaders(t, router, http.MethodPatch, "/employees/"+strconv.Itoa(cto),
+			map[string]interface{}{"companyId": other.ID, "managerId": nil, "department": nil, "departmentId": nil},
+			map[string]string{"If-Match": "*"})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"companyId"`, mustField(t, rec, "field"))
+
+		rec = doRequest(t, router, http.MethodGet, "/employees/9999/chain", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Success: deleted managers end reporting lines", func(t *testing.T) {
+		rec := doRequestWithHeaders(t, router, http.MethodDelete, "/employees/"+strconv.Itoa(cto), nil,
+			map[string]string{"If-Match": "*"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		assert.Equal(t, []int{}, ids(t, fmt.Sprintf("/employees/%d/chain", devs[0])))
+		assert.Equal(t, []int{}, ids(t, fmt.Sprintf("/employees/%d/reports", ceo)))
+		assert.Equal(t, http.StatusOK, patchManager(t, devs[0], nil).Code)
+	})
+
+	t.Run("Error: deleted managers still close cycles", func(t *testing.T) {
+		// devs[1] reports to the deleted cto, who reports to ceo.
+		rec := patchManager(t, ceo, devs[1])
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"managerId"`, mustField(t, rec, "field"))
+	})
+}
+
+func TestDepartmentHandlers_Hierarchy(t *testing.T) {
+	router := newTestRouter()
+	companyID := createCompany(t, router)
+
+	createDept := func(t *testing.T, name, phone string, parentID interface{}) int {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodPost, "/departments", map[string]interface{}{
+			"companyId": companyID, "name": name, "phone": phone, "parentId": parentID,
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp rest.IDResponse
+		decode(t, rec, &resp)
+		return resp.ID
+	}
+	hireInto := func(t *testing.T, name, deptPhone, phone string) {
+		t.Helper()
+		body := employeeBody(companyID, phone, phone)
+		body["department"] = map[string]interface{}{"companyId": companyID, "name": name, "phone": deptPhone}
+		rec := doRequest(t, router, http.MethodPost, "/employees", body)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	tree := func(t *testing.T) []*domain.DepartmentNode {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodGet, fmt.Sprintf("/companies/%d/departments/tree", companyID), nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var roots []*domain.DepartmentNode
+		decode(t, rec, &roots)
+		return roots
+	}
+
+	division := createDept(t, "Division", "+100", nil)
+	department := createDept(t, "Department", "+200", division)
+	team := createDept(t, "Team", "+300", department)
+	createDept(t, "Other", "+400", nil)
+	hireInto(t, "Division", "+100", "+1")
+	hireInto(t, "Team", "+300", "+2")
+	hireInto(t, "Team", "+300", "+3")
+
+	t.Run("Success: tree with rolled up headcounts", func(t *testing.T) {
+		roots := tree(t)
+		require.Len(t, roots, 2)
+		assert.Equal(t, "Division", roots[0].Name)
+		assert.Equal(t, 1, roots[0].EmployeeCount)
+		assert.Equal(t, 3, roots[0].TotalEmployeeCount)
+		require.Len(t, roots[0].Children, 1)
+		assert.Equal(t, 2, roots[0].Children[0].TotalEmployeeCount)
+		require.Len(t, roots[0].Children[0].Children, 1)
+		assert.Equal(t, team, roots[0].Children[0].Children[0].ID)
+		assert.Empty(t, roots[1].Children)
+	})
+
+	t.Run("Success: recursive department employees", func(t *testing.T) {
+		path := fmt.Sprintf("/companies/%d/departments/%d/employees", companyID, division)
+		for query, want := range map[string]int{"": 1, "?recursive=true": 3, "?recursive=false": 1} {
+			rec := doRequest(t, router, http.MethodGet, path+query, nil)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var page struct {
+				Items []domain.Employee `json:"items"`
+			}
+			decode(t, rec, &page)
+			assert.Len(t, page.Items, want, query)
+		}
+
+		rec := doRequest(t, router, http.MethodGet, path+"?recursive=maybe", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	})
+
+	t.Run("Error: invalid parents", func(t *testing.T) {
+		for name, parentID := range map[string]int{"cycle": team, "self": division} {
+			rec := doRequest(t, router, http.MethodPatch, "/departments/"+strconv.Itoa(division),
+				map[string]interface{}{"parentId": parentID})
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, name+": "+rec.Body.String())
+			assert.Equal(t, `"parentId"`, mustField(t, rec, "field"), name)
+		}
+
+		rec := doRequest(t, router, http.MethodPost, "/departments", map[string]interface{}{
+			"companyId": companyID, "name": "Orphan", "phone": "+500", "parentId": 9999,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		assert.Equal(t, `"parentId"`, mustField(t, rec, "field"))
+	})
+
+	t.Run("Success: move departments", func(t *testing.T) {
+		rec := doRequest(t, router, http.MethodPatch, "/departments/"+strconv.Itoa(team),
+			map[string]interface{}{"parentId": nil})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Len(t, tree(t), 3)
+
+		rec = doRequest(t, router, http.MethodPatch, "/departments/"+strconv.Itoa(team),
+			map[string]interface{}{"parentId": division})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		roots := tree(t)
+		require.Len(t, roots, 2)
+		assert.Len(t, roots[0].Children, 2)
+
+		// Employees and children are not detached behind their back.
+		rec = doRequest(t, router, http.MethodDelete, "/departments/"+strconv.Itoa(division), nil)
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+		rec = doRequest(t, router, http.MethodDelete, "/departments/"+strconv.Itoa(team), nil)
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+		assert.Len(t, tree(t), 2)
+	})
+}
+
+// staticVerifier accepts one token, issued to subject.
+type staticVerifier struct {
+	token, subject string
+}
+
+func (v staticVerifier) Verify(token string) (*auth.Principal, error) {
+	if token != v.token {
+		return nil, fmt.Errorf("%w: token has expired", auth.ErrInvalidToken)
+	}
+	return &auth.Principal{Subject: v.subject}, nil
+}
+
+func TestAuthentication(t *testing.T) {
+	router := newTestRouterWithVerifier(staticVerifier{token: "good", subject: "svc-reports"})
+	bearer := map[string]string{"Authorization": "Bearer good", "X-Actor": "mallory"}
+
+	rec := doRequest(t, router, http.MethodGet, "/companies", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer realm="employee-service"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rec.Header().Get("X-Request-ID"))
+
+	rec = doRequestWithHeaders(t, router, http.MethodGet, "/companies", nil, map[string]string{"Authorization": "Basic Zm9vOmJhcg=="})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = doRequestWithHeaders(t, router, http.MethodGet, "/companies", nil, map[string]string{"Authorization": "Bearer stale"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer realm="employee-service", error="invalid_token", error_description="invalid token: token has expired"`,
+		rec.Header().Get("WWW-Authenticate"))
+
+	rec = doRequestWithHeaders(t, router, http.MethodGet, "/companies/1/employees/export", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = doRequestWithHeaders(t, router, http.MethodPost, "/companies", map[string]interface{}{
+		"legalName": "Acme LLC", "taxId": "7701234567", "defaultCountry": "RU",
+	}, bearer)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var company rest.IDResponse
+	decode(t, rec, &company)
+
+	rec = doRequestWithHeaders(t, router, http.MethodPost, "/employees", employeeBody(company.ID, "+1", "1"), bearer)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created rest.IDResponse
+	decode(t, rec, &created)
+
+	// The verified subject is the actor, whatever X-Actor claims.
+	rec = doRequestWithHeaders(t, router, http.MethodGet, fmt.Sprintf("/employees/%d/history", created.ID), nil,
+		map[string]string{"Authorization": "bearer good"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var history rest.HistoryResponse
+	decode(t, rec, &history)
+	require.Len(t, history.Items, 1)
+	assert.Equal(t, "svc-reports", history.Items[0].Actor)
+}
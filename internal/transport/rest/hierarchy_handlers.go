@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type EmployeeListResponse struct {
+	Items []*domain.Employee `json:"items"`
+}
+
+type SubordinateListResponse struct {
+	Items []*domain.Subordinate `json:"items"`
+}
+
+func (h *EmployeeHandlers) GetDirectReports(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	reports, err := h.service.GetDirectReports(r.Context(), id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, EmployeeListResponse{Items: reports})
+}
+
+// GetManagementChain lists the managers of the employee, nearest first.
+func (h *EmployeeHandlers) GetManagementChain(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	chain, err := h.service.GetManagementChain(r.Context(), id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, EmployeeListResponse{Items: chain})
+}
+
+func (h *EmployeeHandlers) GetSubordinates(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	subordinates, err := h.service.GetSubordinates(r.Context(), id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SubordinateListResponse{Items: subordinates})
+}
@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+const (
+	// maxImportSize caps the size of an uploaded import file.
+	maxImportSize = 10 << 20
+	// maxSyncImportRows is the largest import run while the client waits;
+	// bigger files are imported by a background job.
+	maxSyncImportRows = 100
+)
+
+type ImportHandlers struct {
+	service ImportService
+}
+
+func NewImportHandlers(s ImportService) *ImportHandlers {
+	return &ImportHandlers{service: s}
+}
+
+// ImportEmployees imports a CSV file into the company. Small files are
+// imported right away and answered with the report; large ones, or any file
+// with async=true, start a job and are answered with 202 and its location.
+func (h *ImportHandlers) ImportEmployees(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	switch mediaType(r) {
+	case "", "text/csv", "application/csv":
+	default:
+		respondWithError(w, http.StatusUnsupportedMediaType, "Import file must be text/csv")
+		return
+	}
+
+	opts, err := parseImportOptions(r)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	rows, err := parseImportCSV(http.MaxBytesReader(w, r.Body, maxImportSize), companyID, opts.delimiter)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			respondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Import file exceeds %d bytes", maxImportSize))
+		case isCSVError(err):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondWithDomainError(w, err)
+		}
+		return
+	}
+
+	if opts.async || len(rows) > maxSyncImportRows {
+		job, err := h.service.StartImport(r.Context(), companyID, rows, opts.mode)
+		if err != nil {
+			respondWithDomainError(w, err)
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/companies/%d/employees/import/%s", companyID, job.ID))
+		respondWithJSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	report, err := h.service.ImportEmployees(r.Context(), companyID, rows, opts.mode)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+func (h *ImportHandlers) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	job, err := h.service.GetImportJob(r.Context(), companyID, r.PathValue("jobId"))
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, job)
+}
+
+type importOptions struct {
+	mode      domain.ImportMode
+	async     bool
+	delimiter rune
+}
+
+func parseImportOptions(r *http.Request) (importOptions, error) {
+	query := r.URL.Query()
+	opts := importOptions{mode: domain.ImportAllOrNothing, delimiter: ','}
+
+	if mode := query.Get("mode"); mode != "" {
+		opts.mode = domain.ImportMode(mode)
+	}
+
+	if async := query.Get("async"); async != "" {
+		b, err := strconv.ParseBool(async)
+		if err != nil {
+			return opts, domain.NewValidationError("async", "async must be a boolean")
+		}
+		opts.async = b
+	}
+
+	if delimiter := query.Get("delimiter"); delimiter != "" {
+		d, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || d == '"' || d == '\r' || d == '\n' || d == utf8.RuneError {
+			return opts, domain.NewValidationError("delimiter", "delimiter must be a single character other than a quote or line break")
+		}
+		opts.delimiter = d
+	}
+
+	return opts, nil
+}
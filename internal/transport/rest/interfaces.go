@@ -1,17 +1,73 @@
 package rest
 
-import "github.com/Hexes-rgb/employee-service/internal/domain"
+import (
+	"context"
+
+	"github.com/Hexes-rgb/employee-service/internal/auth"
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
 
 type EmployeeService interface {
-	CreateEmployee(emp *domain.Employee) (int, error)
-	GetEmployee(id int) (*domain.Employee, error)
-	UpdateEmployee(emp *domain.Employee) error
-	DeleteEmployee(id int) error
-	GetCompanyEmployees(companyID int) ([]*domain.Employee, error)
-	GetDepartmentEmployees(companyID, deptId int) ([]*domain.Employee, error)
+	CreateEmployee(ctx context.Context, emp *domain.Employee) (int, error)
+	GetEmployee(ctx context.Context, id int) (*domain.Employee, error)
+	UpdateEmployee(ctx context.Context, id, version int, patch domain.Patch) (*domain.Employee, error)
+	DeleteEmployee(ctx context.Context, id, version int) error
+	RestoreEmployee(ctx context.Context, id int) error
+	GetEmployeeHistory(ctx context.Context, id int) ([]*domain.AuditEntry, error)
+	TransferEmployee(ctx context.Context, id int, transfer *domain.Transfer) (*domain.Assignment, error)
+	GetEmployeeAssignments(ctx context.Context, id int) ([]*domain.Assignment, error)
+	GetDirectReports(ctx context.Context, id int) ([]*domain.Employee, error)
+	GetManagementChain(ctx context.Context, id int) ([]*domain.Employee, error)
+	GetSubordinates(ctx context.Context, id int) ([]*domain.Subordinate, error)
+	GetCompanyEmployees(ctx context.Context, companyID int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error)
+	GetDepartmentEmployees(ctx context.Context, companyID, deptId int, filter domain.EmployeeFilter, page domain.PageRequest) (*domain.EmployeePage, error)
+	SearchEmployees(ctx context.Context, companyID int, q string, limit int) ([]*domain.SearchResult, error)
+	ExportEmployees(ctx context.Context, companyID int, filter domain.EmployeeFilter, fn func(*domain.Employee) error) error
+}
+
+type ImportService interface {
+	ImportEmployees(ctx context.Context, companyID int, rows []domain.ImportRow, mode domain.ImportMode) (*domain.ImportReport, error)
+	StartImport(ctx context.Context, companyID int, rows []domain.ImportRow, mode domain.ImportMode) (*domain.ImportJob, error)
+	GetImportJob(ctx context.Context, companyID int, id string) (*domain.ImportJob, error)
 }
 
 type DepartmentService interface {
-	GetOrCreate(dept *domain.Department) (int, error)
-	GetDepartment(id int) (*domain.Department, error)
+	GetOrCreate(ctx context.Context, dept *domain.Department) (int, error)
+	GetDepartment(ctx context.Context, id int) (*domain.Department, error)
+	GetCompanyDepartments(ctx context.Context, companyID int) ([]*domain.DepartmentSummary, error)
+	GetDepartmentTree(ctx context.Context, companyID int) ([]*domain.DepartmentNode, error)
+	UpdateDepartment(ctx context.Context, dept *domain.Department) error
+	DeleteDepartment(ctx context.Context, id int) error
+}
+
+type DocumentService interface {
+	CreateDocument(ctx context.Context, employeeID int, doc *domain.IdentityDocument) (*domain.IdentityDocument, error)
+	GetDocument(ctx context.Context, employeeID, id int) (*domain.IdentityDocument, error)
+	ListDocuments(ctx context.Context, employeeID int) ([]*domain.IdentityDocument, error)
+	UpdateDocument(ctx context.Context, employeeID int, doc *domain.IdentityDocument) (*domain.IdentityDocument, error)
+	DeleteDocument(ctx context.Context, employeeID, id int) error
+	GetExpiringDocuments(ctx context.Context, companyID, days int) ([]*domain.ExpiringDocument, error)
+}
+
+type CompanyService interface {
+	CreateCompany(ctx context.Context, company *domain.Company) (int, error)
+	GetCompany(ctx context.Context, id int) (*domain.Company, error)
+	ListCompanies(ctx context.Context) ([]*domain.Company, error)
+	UpdateCompany(ctx context.Context, id int, patch *domain.CompanyPatch) error
+	DeleteCompany(ctx context.Context, id int) error
+}
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, companyID, id int) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, companyID int) ([]*domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, companyID, id int, patch *domain.WebhookSubscriptionPatch) (*domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, companyID, id int) error
+	ListDeliveries(ctx context.Context, companyID, subscriptionID int, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, companyID, subscriptionID int, id int64) (*domain.WebhookDelivery, error)
+}
+
+// TokenVerifier checks bearer tokens and returns who they were issued to.
+type TokenVerifier interface {
+	Verify(token string) (*auth.Principal, error)
 }
@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/auth"
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	actorHeader     = "X-Actor"
+	// anonymousActor is recorded for requests that do not name their actor.
+	anonymousActor = "anonymous"
+	// unverifiedActorPrefix marks actors named by the client in X-Actor, which
+	// anyone can set, apart from the subjects of verified tokens.
+	unverifiedActorPrefix = "unverified:"
+	// maxActorLength bounds client supplied actors.
+	maxActorLength = 128
+	// maxRequestIDLength bounds client supplied request IDs.
+	maxRequestIDLength = 128
+	// authRealm is announced in WWW-Authenticate challenges.
+	authRealm = "employee-service"
+)
+
+func withTimeout(timeout time.Duration, next http.Handler) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withRequestInfo stores the request ID and the actor in the request context
+// for the audit trail. A valid X-Request-ID from the client is kept,
+// otherwise a new one is generated; either way it is echoed back. The actor
+// is taken from X-Actor, prefixed with "unverified:" as the client can claim
+// to be anyone; withAuthentication replaces it with the token subject.
+func withRequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		actor := anonymousActor
+		if claimed := strings.TrimSpace(r.Header.Get(actorHeader)); claimed != "" {
+			if len(claimed) > maxActorLength {
+				claimed = claimed[:maxActorLength]
+			}
+			actor = unverifiedActorPrefix + strings.ToValidUTF8(claimed, "")
+		}
+
+		ctx := domain.WithRequestID(r.Context(), id)
+		ctx = domain.WithActor(ctx, actor)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withAuthentication requires a bearer token accepted by verifier and stores
+// its principal in the request context. The token subject replaces the
+// X-Actor header as the actor of the audit trail, so it must run after
+// withRequestInfo. A nil verifier leaves the routes open.
+func withAuthentication(verifier TokenVerifier, next http.Handler) http.Handler {
+	if verifier == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+authRealm+`"`)
+			respondWithError(w, http.StatusUnauthorized, "Bearer token required")
+			return
+		}
+
+		principal, err := verifier.Verify(token)
+		if err != nil {
+			description := "token is invalid"
+			if errors.Is(err, auth.ErrInvalidToken) {
+				description = err.Error()
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+authRealm+`", error="invalid_token", error_description="`+quoteSafe(description)+`"`)
+			respondWithError(w, http.StatusUnauthorized, "Invalid bearer token")
+			return
+		}
+
+		ctx := auth.WithPrincipal(r.Context(), principal)
+		ctx = domain.WithActor(ctx, principal.Subject)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header. The
+// scheme is case insensitive (RFC 7235).
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// quoteSafe drops the characters that would end or escape a quoted-string
+// header parameter.
+func quoteSafe(s string) string {
+	return strings.NewReplacer(`"`, "", `\`, "").Replace(s)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
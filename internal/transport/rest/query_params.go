@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+func parsePageRequest(r *http.Request) (domain.PageRequest, error) {
+	query := r.URL.Query()
+	page := domain.PageRequest{Cursor: query.Get("cursor")}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > domain.MaxPageLimit {
+			return page, domain.NewValidationError("limit",
+				"limit must be between 1 and "+strconv.Itoa(domain.MaxPageLimit))
+		}
+		page.Limit = n
+	}
+
+	if includeTotal := query.Get("includeTotal"); includeTotal != "" {
+		b, err := strconv.ParseBool(includeTotal)
+		if err != nil {
+			return page, domain.NewValidationError("includeTotal", "includeTotal must be a boolean")
+		}
+		page.IncludeTotal = b
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		if !domain.IsEmployeeSortField(sortBy) {
+			return page, domain.NewValidationError("sort",
+				"sort must be one of: "+strings.Join(domain.EmployeeSortFields, ", "))
+		}
+		page.SortBy = sortBy
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		page.SortDesc = true
+	default:
+		return page, domain.NewValidationError("order", "order must be asc or desc")
+	}
+
+	return page, nil
+}
+
+func parseEmployeeFilter(r *http.Request) (domain.EmployeeFilter, error) {
+	query := r.URL.Query()
+	filter := domain.EmployeeFilter{
+		NamePrefix:    query.Get("name"),
+		SurnamePrefix: query.Get("surname"),
+		Phone:         query.Get("phone"),
+		PassportType:  query.Get("passportType"),
+	}
+
+	switch deptID := query.Get("departmentId"); deptID {
+	case "":
+	case "null":
+		filter.NoDepartment = true
+	default:
+		id, err := strconv.Atoi(deptID)
+		if err != nil {
+			return filter, domain.NewValidationError("departmentId", "departmentId must be an integer or null")
+		}
+		filter.DepartmentID = &id
+	}
+
+	if includeDeleted := query.Get("includeDeleted"); includeDeleted != "" {
+		b, err := strconv.ParseBool(includeDeleted)
+		if err != nil {
+			return filter, domain.NewValidationError("includeDeleted", "includeDeleted must be a boolean")
+		}
+		filter.IncludeDeleted = b
+	}
+
+	return filter, nil
+}
@@ -2,26 +2,107 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/validation"
 )
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// ProblemDetails is the RFC 7807 error body returned by every endpoint.
+type ProblemDetails struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Field  string `json:"field,omitempty"`
+	// Errors lists every problem with the request body of a 422 response.
+	Errors []validation.Violation `json:"errors,omitempty"`
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	writeJSON(w, code, "application/json", payload)
+}
+
+func writeJSON(w http.ResponseWriter, code int, contentType string, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
+		log.Printf("Encoding response failed: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
+		w.Write([]byte(internalErrorDetail))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(code)
 	w.Write(response)
 }
 
+func respondWithProblem(w http.ResponseWriter, problem ProblemDetails) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	writeJSON(w, problem.Status, "application/problem+json", problem)
+}
+
 func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, ErrorResponse{Error: message})
+	respondWithProblem(w, ProblemDetails{Status: code, Detail: message})
+}
+
+// internalErrorDetail and unavailableDetail replace the messages of 500 and
+// 503 responses, which may carry driver, SQL and network details.
+const (
+	internalErrorDetail = "internal server error"
+	unavailableDetail   = "storage unavailable, retry later"
+)
+
+// respondWithDomainError is the single place where service errors are turned
+// into HTTP statuses. Anything that is not a known domain error is logged and
+// reported as a 500 without its message; so are storage outages as 503.
+func respondWithDomainError(w http.ResponseWriter, err error) {
+	problem := ProblemDetails{Status: http.StatusInternalServerError, Detail: err.Error()}
+
+	var conflictErr *domain.ConflictError
+	var violationsErr *validation.Error
+	var validationErr *domain.ValidationError
+
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		problem.Status = http.StatusNotFound
+	case errors.Is(err, domain.ErrVersionMismatch):
+		problem.Status = http.StatusPreconditionFailed
+	case errors.As(err, &conflictErr):
+		problem.Status = http.StatusConflict
+		problem.Field = conflictErr.Field
+	case errors.Is(err, domain.ErrConflict):
+		problem.Status = http.StatusConflict
+	case errors.As(err, &violationsErr):
+		// Field keeps naming the first violation for older clients.
+		problem.Status = http.StatusUnprocessableEntity
+		problem.Field = validation.Dotted(violationsErr.Violations[0].Field)
+		problem.Errors = violationsErr.Violations
+	case errors.As(err, &validationErr):
+		problem.Status = http.StatusUnprocessableEntity
+		problem.Field = validationErr.Field
+		problem.Errors = []validation.Violation{{
+			Field:   validation.Pointer(validationErr.Field),
+			Code:    validation.CodeInvalid,
+			Message: validationErr.Message,
+		}}
+	case errors.Is(err, domain.ErrValidation):
+		problem.Status = http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrUnavailable):
+		log.Printf("Storage unavailable: %v", err)
+		problem.Status = http.StatusServiceUnavailable
+		problem.Detail = unavailableDetail
+	default:
+		log.Printf("Request failed: %v", err)
+		problem.Detail = internalErrorDetail
+	}
+
+	respondWithProblem(w, problem)
 }
@@ -1,26 +1,84 @@
 package rest
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 func NewRouter(
 	empService EmployeeService,
 	deptService DepartmentService,
+	companyService CompanyService,
+	importService ImportService,
+	webhookService WebhookService,
+	documentService DocumentService,
+	verifier TokenVerifier,
+	requestTimeout time.Duration,
 ) *http.ServeMux {
 	router := http.NewServeMux()
 	empHandlers := NewEmployeeHandlers(empService)
 	deptHandlers := NewDepartmentHandlers(deptService)
+	companyHandlers := NewCompanyHandlers(companyService)
+	importHandlers := NewImportHandlers(importService)
+	webhookHandlers := NewWebhookHandlers(webhookService)
+	documentHandlers := NewDocumentHandlers(documentService)
+
+	handle := func(pattern string, handler http.HandlerFunc) {
+		router.Handle(pattern, withRequestInfo(withAuthentication(verifier, withTimeout(requestTimeout, handler))))
+	}
 
 	// Employee routes
-	router.HandleFunc("POST /employees", empHandlers.CreateEmployee)
-	router.HandleFunc("GET /employees/{id}", empHandlers.GetEmployee)
-	router.HandleFunc("PATCH /employees/{id}", empHandlers.UpdateEmployee)
-	router.HandleFunc("DELETE /employees/{id}", empHandlers.DeleteEmployee)
-	router.HandleFunc("GET /companies/{companyId}/employees", empHandlers.GetCompanyEmployees)
-	router.HandleFunc("GET /companies/{companyId}/departments/{departmentId}/employees", empHandlers.GetDepartmentEmployees)
+	handle("POST /employees", empHandlers.CreateEmployee)
+	handle("GET /employees/{id}", empHandlers.GetEmployee)
+	handle("PATCH /employees/{id}", empHandlers.UpdateEmployee)
+	handle("DELETE /employees/{id}", empHandlers.DeleteEmployee)
+	handle("POST /employees/{id}/restore", empHandlers.RestoreEmployee)
+	handle("GET /employees/{id}/history", empHandlers.GetEmployeeHistory)
+	handle("POST /employees/{id}/transfers", empHandlers.TransferEmployee)
+	handle("GET /employees/{id}/assignments", empHandlers.GetEmployeeAssignments)
+	handle("GET /employees/{id}/reports", empHandlers.GetDirectReports)
+	handle("GET /employees/{id}/chain", empHandlers.GetManagementChain)
+	handle("GET /employees/{id}/subordinates", empHandlers.GetSubordinates)
+	handle("GET /companies/{companyId}/employees", empHandlers.GetCompanyEmployees)
+	handle("GET /companies/{companyId}/employees/search", empHandlers.SearchEmployees)
+	handle("GET /companies/{companyId}/departments/{departmentId}/employees", empHandlers.GetDepartmentEmployees)
+	// Exports stream for as long as the client reads, so they are not bound
+	// by the request timeout.
+	router.Handle("GET /companies/{companyId}/employees/export", withRequestInfo(withAuthentication(verifier, http.HandlerFunc(empHandlers.ExportEmployees))))
+	handle("POST /companies/{companyId}/employees/import", importHandlers.ImportEmployees)
+	handle("GET /companies/{companyId}/employees/import/{jobId}", importHandlers.GetImportJob)
+
+	// Identity document routes
+	handle("POST /employees/{id}/documents", documentHandlers.CreateDocument)
+	handle("GET /employees/{id}/documents", documentHandlers.ListDocuments)
+	handle("GET /employees/{id}/documents/{documentId}", documentHandlers.GetDocument)
+	handle("PUT /employees/{id}/documents/{documentId}", documentHandlers.UpdateDocument)
+	handle("DELETE /employees/{id}/documents/{documentId}", documentHandlers.DeleteDocument)
+	handle("GET /companies/{companyId}/documents/expiring", documentHandlers.GetExpiringDocuments)
 
 	// Department routes
-	router.HandleFunc("POST /departments", deptHandlers.GetOrCreateDepartment)
-	router.HandleFunc("GET /departments/{id}", deptHandlers.GetDepartment)
+	handle("POST /departments", deptHandlers.GetOrCreateDepartment)
+	handle("GET /departments/{id}", deptHandlers.GetDepartment)
+	handle("PATCH /departments/{id}", deptHandlers.UpdateDepartment)
+	handle("DELETE /departments/{id}", deptHandlers.DeleteDepartment)
+	handle("GET /companies/{companyId}/departments", deptHandlers.GetCompanyDepartments)
+	handle("GET /companies/{companyId}/departments/tree", deptHandlers.GetDepartmentTree)
+
+	// Company routes
+	handle("POST /companies", companyHandlers.CreateCompany)
+	handle("GET /companies", companyHandlers.ListCompanies)
+	handle("GET /companies/{id}", companyHandlers.GetCompany)
+	handle("PATCH /companies/{id}", companyHandlers.UpdateCompany)
+	handle("DELETE /companies/{id}", companyHandlers.DeleteCompany)
+
+	// Webhook routes
+	handle("POST /companies/{companyId}/webhooks", webhookHandlers.CreateSubscription)
+	handle("GET /companies/{companyId}/webhooks", webhookHandlers.ListSubscriptions)
+	handle("GET /companies/{companyId}/webhooks/{id}", webhookHandlers.GetSubscription)
+	handle("PATCH /companies/{companyId}/webhooks/{id}", webhookHandlers.UpdateSubscription)
+	handle("DELETE /companies/{companyId}/webhooks/{id}", webhookHandlers.DeleteSubscription)
+	handle("GET /companies/{companyId}/webhooks/{id}/deliveries", webhookHandlers.ListDeliveries)
+	handle("POST /companies/{companyId}/webhooks/{id}/deliveries/{deliveryId}/replay", webhookHandlers.ReplayDelivery)
 
 	return router
 }
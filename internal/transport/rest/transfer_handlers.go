@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type AssignmentListResponse struct {
+	Items []*domain.Assignment `json:"items"`
+}
+
+// TransferEmployee schedules a move to another department. The response is
+// the assignment the transfer starts.
+func (h *EmployeeHandlers) TransferEmployee(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var transfer domain.Transfer
+	if err := json.NewDecoder(r.Body).Decode(&transfer); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validateTransfer(&transfer); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	assignment, err := h.service.TransferEmployee(r.Context(), id, &transfer)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, assignment)
+}
+
+func (h *EmployeeHandlers) GetEmployeeAssignments(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	assignments, err := h.service.GetEmployeeAssignments(r.Context(), id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, AssignmentListResponse{Items: assignments})
+}
@@ -1,42 +1,128 @@
 package rest
 
 import (
-	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
 
 	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/validation"
+	"github.com/Hexes-rgb/employee-service/internal/webhook"
 )
 
+const (
+	maxWebhookURLLength  = 2048
+	minWebhookSecretSize = 16
+)
+
+// countryCodeMessage is reported for a defaultCountry that is not a code.
+const countryCodeMessage = "company defaultCountry must be an ISO 3166-1 alpha-2 code"
+
 func validateDepartment(dept *domain.Department) error {
-	if dept == nil {
-		return errors.New("department is required")
+	v := validation.New()
+	validation.Department(v, dept)
+	return v.Err()
+}
+
+// validateEmployee checks the body of POST /employees, which must include
+// the department.
+func validateEmployee(emp *domain.Employee) error {
+	v := validation.New()
+	validation.Employee(v, emp)
+	if emp.Department == nil {
+		v.Add("department", validation.CodeRequired, "department is required")
 	}
-	if dept.CompanyID == 0 {
-		return errors.New("department companyId is required")
+	return v.Err()
+}
+
+func validateDepartmentUpdate(dept *domain.Department) error {
+	v := validation.New()
+	validation.DepartmentUpdate(v, dept)
+	return v.Err()
+}
+
+func validateDocument(doc *domain.IdentityDocument) error {
+	v := validation.New()
+	validation.Document(v, doc)
+	return v.Err()
+}
+
+func validateTransfer(transfer *domain.Transfer) error {
+	v := validation.New()
+	v.Check(transfer.DepartmentID > 0, "departmentId", validation.CodeRequired, "transfer departmentId is required")
+	return v.Err()
+}
+
+func validateCompany(company *domain.Company) error {
+	v := validation.New()
+	v.Text("legalName", company.LegalName, validation.MaxNameLength, "company legalName")
+	v.Text("taxId", company.TaxID, validation.MaxTaxIDLength, "company taxId")
+	v.Check(validation.IsCountryCode(company.DefaultCountry), "defaultCountry", validation.CodeInvalid, countryCodeMessage)
+	return v.Err()
+}
+
+func validateCompanyPatch(patch *domain.CompanyPatch) error {
+	v := validation.New()
+	if patch.LegalName != nil && v.Check(*patch.LegalName != "", "legalName", validation.CodeRequired, "company legalName cannot be empty") {
+		v.MaxLength("legalName", *patch.LegalName, validation.MaxNameLength, "company legalName")
 	}
-	if dept.Name == "" {
-		return errors.New("department name is required")
+	if patch.TaxID != nil && v.Check(*patch.TaxID != "", "taxId", validation.CodeRequired, "company taxId cannot be empty") {
+		v.MaxLength("taxId", *patch.TaxID, validation.MaxTaxIDLength, "company taxId")
 	}
-	if dept.Phone == "" {
-		return errors.New("department phone is required")
+	if patch.DefaultCountry != nil {
+		v.Check(validation.IsCountryCode(*patch.DefaultCountry), "defaultCountry", validation.CodeInvalid, countryCodeMessage)
 	}
-	return nil
+	return v.Err()
 }
 
-func validateEmployee(emp *domain.Employee) error {
-	if emp.Name == "" {
-		return errors.New("employee name is required")
+func validateWebhookSubscription(sub *domain.WebhookSubscription) error {
+	v := validation.New()
+	validateWebhookURL(v, sub.URL)
+	if sub.Secret != "" {
+		validateWebhookSecret(v, sub.Secret)
 	}
-	if emp.Surname == "" {
-		return errors.New("employee surname is required")
+	validateEventTypes(v, sub.EventTypes)
+	return v.Err()
+}
+
+func validateWebhookSubscriptionPatch(patch *domain.WebhookSubscriptionPatch) error {
+	v := validation.New()
+	if patch.URL != nil {
+		validateWebhookURL(v, *patch.URL)
 	}
-	if emp.Phone == "" {
-		return errors.New("employee phone is required")
+	if patch.Secret != nil {
+		validateWebhookSecret(v, *patch.Secret)
 	}
-	if emp.CompanyID == 0 {
-		return errors.New("employee companyId is required")
+	if patch.EventTypes != nil {
+		validateEventTypes(v, *patch.EventTypes)
 	}
-	if emp.PassportNumber == "" {
-		return errors.New("employee passport number is required")
+	return v.Err()
+}
+
+func validateWebhookURL(v *validation.Validator, raw string) {
+	if !v.Required("url", raw, "webhook url is required") || !v.MaxLength("url", raw, maxWebhookURLLength, "webhook url") {
+		return
+	}
+	u, err := url.Parse(raw)
+	if !v.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		"url", validation.CodeInvalid, "webhook url must be an absolute http or https URL") {
+		return
+	}
+	// Host names are checked again after resolution when delivering.
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	addr, err := netip.ParseAddr(host)
+	v.Check(host != "localhost" && !strings.HasSuffix(host, ".localhost") && (err != nil || webhook.PublicAddr(addr)),
+		"url", validation.CodeInvalid, "webhook url must not point to a loopback, private or link-local address")
+}
+
+func validateWebhookSecret(v *validation.Validator, secret string) {
+	v.Check(len(secret) >= minWebhookSecretSize, "secret", validation.CodeInvalid,
+		fmt.Sprintf("webhook secret must be at least %d characters", minWebhookSecretSize))
+}
+
+func validateEventTypes(v *validation.Validator, types []domain.EventType) {
+	for _, t := range types {
+		v.Check(t.Valid(), "eventTypes", validation.CodeInvalid, fmt.Sprintf("unknown event type %q", t))
 	}
-	return nil
 }
@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+type WebhookHandlers struct {
+	service WebhookService
+}
+
+type WebhookListResponse struct {
+	Items []*domain.WebhookSubscription `json:"items"`
+}
+
+type DeliveryListResponse struct {
+	Items []*domain.WebhookDelivery `json:"items"`
+}
+
+func NewWebhookHandlers(s WebhookService) *WebhookHandlers {
+	return &WebhookHandlers{service: s}
+}
+
+func (h *WebhookHandlers) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	var sub domain.WebhookSubscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	sub.CompanyID = companyID
+
+	if err := validateWebhookSubscription(&sub); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	created, err := h.service.CreateSubscription(r.Context(), &sub)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *WebhookHandlers) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	subs, err := h.service.ListSubscriptions(r.Context(), companyID)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, WebhookListResponse{Items: subs})
+}
+
+func (h *WebhookHandlers) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	companyID, id, ok := webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := h.service.GetSubscription(r.Context(), companyID, id)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, sub)
+}
+
+func (h *WebhookHandlers) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	companyID, id, ok := webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	var patch domain.WebhookSubscriptionPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := validateWebhookSubscriptionPatch(&patch); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	sub, err := h.service.UpdateSubscription(r.Context(), companyID, id, &patch)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, sub)
+}
+
+func (h *WebhookHandlers) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	companyID, id, ok := webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSubscription(r.Context(), companyID, id); err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, MessageResponse{Message: "Webhook subscription deleted successfully"})
+}
+
+func (h *WebhookHandlers) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	companyID, id, ok := webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	status := domain.WebhookDeliveryStatus(query.Get("status"))
+	if status != "" && !status.Valid() {
+		respondWithDomainError(w, domain.NewValidationError("status", "status must be pending, succeeded or dead"))
+		return
+	}
+
+	var limit int
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			respondWithDomainError(w, domain.NewValidationError("limit", "limit must be a positive integer"))
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), companyID, id, status, limit)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, DeliveryListResponse{Items: deliveries})
+}
+
+func (h *WebhookHandlers) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	companyID, id, ok := webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(r.PathValue("deliveryId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.service.ReplayDelivery(r.Context(), companyID, id, deliveryID)
+	if err != nil {
+		respondWithDomainError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, delivery)
+}
+
+// webhookPath parses the company and subscription IDs of a webhook route,
+// responding with 400 if either is invalid.
+func webhookPath(w http.ResponseWriter, r *http.Request) (companyID, id int, ok bool) {
+	companyID, err := strconv.Atoi(r.PathValue("companyId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid company ID")
+		return 0, 0, false
+	}
+	id, err = strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook subscription ID")
+		return 0, 0, false
+	}
+	return companyID, id, true
+}
@@ -0,0 +1,64 @@
+package validation
+
+import "github.com/Hexes-rgb/employee-service/internal/domain"
+
+// Employee checks an employee as it is about to be written. The department,
+// if any, is checked under /department.
+func Employee(v *Validator, emp *domain.Employee) {
+	v.Text("name", emp.Name, MaxNameLength, "employee name")
+	v.Text("surname", emp.Surname, MaxNameLength, "employee surname")
+	v.Text("phone", emp.Phone, MaxPhoneLength, "employee phone")
+	v.Check(emp.CompanyID > 0, "companyId", CodeRequired, "employee companyId is required")
+	v.MaxLength("passportType", emp.PassportType, MaxPassportTypeLength, "employee passportType")
+	v.Text("passportNumber", emp.PassportNumber, MaxPassportNumberLength, "employee passport number")
+
+	if emp.Department != nil {
+		Department(v.At("department"), emp.Department)
+	}
+}
+
+// Department checks a department that is looked up or created by name.
+func Department(v *Validator, dept *domain.Department) {
+	v.Check(dept.CompanyID != 0, "companyId", CodeRequired, "department companyId is required")
+	v.Text("name", dept.Name, MaxNameLength, "department name")
+	v.Text("phone", dept.Phone, MaxPhoneLength, "department phone")
+	if dept.ParentID != nil {
+		v.Check(*dept.ParentID > 0, "parentId", CodeInvalid, "department parentId must be a department ID")
+	}
+}
+
+// DepartmentUpdate checks the body of a department update, where empty
+// fields are left as they are and a parentId of 0 stands for the top level.
+func DepartmentUpdate(v *Validator, dept *domain.Department) {
+	v.Check(dept.CompanyID == 0, "companyId", CodeInvalid, "department companyId cannot be changed")
+	v.Check(dept.Name != "" || dept.Phone != "" || dept.ParentID != nil, "", CodeRequired,
+		"department name, phone or parentId is required")
+	if dept.ParentID != nil {
+		v.Check(*dept.ParentID >= 0, "parentId", CodeInvalid, "department parentId must be a department ID or null")
+	}
+	v.MaxLength("name", dept.Name, MaxNameLength, "department name")
+	v.MaxLength("phone", dept.Phone, MaxPhoneLength, "department phone")
+}
+
+// Document checks an identity document as it is about to be written.
+func Document(v *Validator, doc *domain.IdentityDocument) {
+	v.Text("type", doc.Type, MaxDocumentTypeLength, "document type")
+	v.Text("number", doc.Number, MaxDocumentNumberLength, "document number")
+	v.Check(IsCountryCode(doc.IssuingCountry), "issuingCountry", CodeInvalid, "document issuingCountry must be an ISO 3166-1 alpha-2 code")
+	if doc.IssuedOn != nil && doc.ExpiresOn != nil {
+		v.Check(!doc.ExpiresOn.Before(doc.IssuedOn.Time), "expiresOn", CodeInvalid, "document expiresOn must not be before issuedOn")
+	}
+}
+
+// IsCountryCode reports whether s is an ISO 3166-1 alpha-2 code, such as RU.
+func IsCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
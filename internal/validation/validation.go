@@ -0,0 +1,140 @@
+// Package validation collects every problem with a request body instead of
+// stopping at the first one, so that clients can show them all at once.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+// Violation codes.
+const (
+	CodeRequired = "required"
+	CodeTooLong  = "too_long"
+	CodeInvalid  = "invalid"
+)
+
+// Maximum lengths, in characters, of the text columns of the schema. Longer
+// values are rejected before they reach the database.
+const (
+	MaxNameLength           = 255
+	MaxPhoneLength          = 20
+	MaxPassportTypeLength   = 20
+	MaxPassportNumberLength = 50
+	MaxTaxIDLength          = 20
+	MaxDocumentTypeLength   = 20
+	MaxDocumentNumberLength = 50
+)
+
+// Violation is one problem with a request body. Field is a JSON pointer
+// (RFC 6901) to the offending value, empty for the body as a whole.
+type Violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error lists every violation found in a request body. It matches
+// domain.ErrValidation.
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *Error) Is(target error) bool {
+	return target == domain.ErrValidation
+}
+
+// Validator records violations under a JSON pointer prefix. Validators made
+// with At share the violations of their parent.
+type Validator struct {
+	prefix     string
+	violations *[]Violation
+}
+
+func New() *Validator {
+	return &Validator{violations: &[]Violation{}}
+}
+
+// At returns a validator for the object under field, such as the department
+// of an employee.
+func (v *Validator) At(field string) *Validator {
+	return &Validator{prefix: v.pointer(field), violations: v.violations}
+}
+
+// Add records a violation of field.
+func (v *Validator) Add(field, code, message string) {
+	*v.violations = append(*v.violations, Violation{Field: v.pointer(field), Code: code, Message: message})
+}
+
+// Check records a violation of field unless ok holds, and returns ok.
+func (v *Validator) Check(ok bool, field, code, message string) bool {
+	if !ok {
+		v.Add(field, code, message)
+	}
+	return ok
+}
+
+// Required records a violation if value is empty.
+func (v *Validator) Required(field, value, message string) bool {
+	return v.Check(value != "", field, CodeRequired, message)
+}
+
+// MaxLength records a violation if value has more than max characters.
+func (v *Validator) MaxLength(field, value string, max int, subject string) bool {
+	return v.Check(utf8.RuneCountInString(value) <= max, field, CodeTooLong,
+		fmt.Sprintf("%s must be at most %d characters", subject, max))
+}
+
+// Text checks a required text field against its column length.
+func (v *Validator) Text(field, value string, max int, subject string) bool {
+	return v.Required(field, value, subject+" is required") && v.MaxLength(field, value, max, subject)
+}
+
+// Err returns the violations recorded so far as an *Error, or nil if there
+// are none.
+func (v *Validator) Err() error {
+	if len(*v.violations) == 0 {
+		return nil
+	}
+	return &Error{Violations: append([]Violation(nil), *v.violations...)}
+}
+
+func (v *Validator) pointer(field string) string {
+	if field == "" {
+		return v.prefix
+	}
+	return v.prefix + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(field)
+}
+
+// Pointer turns a dotted field name such as "department.phone" into a JSON
+// pointer, for errors that name their field the older way.
+func Pointer(dotted string) string {
+	if dotted == "" {
+		return ""
+	}
+	v := New()
+	for _, part := range strings.Split(dotted, ".") {
+		v = v.At(part)
+	}
+	return v.prefix
+}
+
+// Dotted turns a JSON pointer back into a dotted field name.
+func Dotted(pointer string) string {
+	parts := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, p := range parts {
+		parts[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(p)
+	}
+	return strings.Join(parts, ".")
+}
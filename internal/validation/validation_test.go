@@ -0,0 +1,56 @@
+package validation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmployee(t *testing.T) {
+	t.Run("Success: valid employee", func(t *testing.T) {
+		v := validation.New()
+		validation.Employee(v, &domain.Employee{
+			Name: "John", Surname: "Doe", Phone: "+1", CompanyID: 1, PassportNumber: "1",
+			Department: &domain.Department{CompanyID: 1, Name: "HR", Phone: "+2"},
+		})
+		assert.NoError(t, v.Err())
+	})
+
+	t.Run("Error: every violation is reported", func(t *testing.T) {
+		v := validation.New()
+		validation.Employee(v, &domain.Employee{
+			Surname:        strings.Repeat("я", validation.MaxNameLength+1),
+			Phone:          "+1",
+			CompanyID:      1,
+			PassportNumber: strings.Repeat("1", validation.MaxPassportNumberLength),
+			Department:     &domain.Department{CompanyID: 1, Name: "HR", Phone: strings.Repeat("1", 21)},
+		})
+
+		err := v.Err()
+		require.ErrorIs(t, err, domain.ErrValidation)
+
+		var validationErr *validation.Error
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []validation.Violation{
+			{Field: "/name", Code: validation.CodeRequired, Message: "employee name is required"},
+			{Field: "/surname", Code: validation.CodeTooLong, Message: "employee surname must be at most 255 characters"},
+			{Field: "/department/phone", Code: validation.CodeTooLong, Message: "department phone must be at most 20 characters"},
+		}, validationErr.Violations)
+	})
+}
+
+func TestPointer(t *testing.T) {
+	assert.Equal(t, "", validation.Pointer(""))
+	assert.Equal(t, "/department/phone", validation.Pointer("department.phone"))
+	assert.Equal(t, "department.phone", validation.Dotted("/department/phone"))
+
+	v := validation.New()
+	v.At("a/b").Add("c~d", validation.CodeInvalid, "invalid")
+	var validationErr *validation.Error
+	require.ErrorAs(t, v.Err(), &validationErr)
+	assert.Equal(t, "/a~1b/c~0d", validationErr.Violations[0].Field)
+}
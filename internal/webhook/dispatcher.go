@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+)
+
+const (
+	// maxRetryDelay caps the exponential backoff between attempts.
+	maxRetryDelay = 6 * time.Hour
+	// maxDrainLength bounds how much of a response body is read so that the
+	// connection can be reused.
+	maxDrainLength = 512
+)
+
+// Store is the delivery storage as seen by the dispatcher.
+type Store interface {
+	// ClaimDueDeliveries returns up to limit pending deliveries due at now
+	// and postpones them by lease so that they are not claimed twice.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error)
+	GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+}
+
+type Config struct {
+	PollInterval time.Duration
+	// Timeout bounds one attempt, including reading the response status.
+	Timeout time.Duration
+	// MaxAttempts is how many attempts a delivery gets before it is dead.
+	MaxAttempts int
+	// RetryDelay is the wait after the first failed attempt; it doubles
+	// after every further failure, up to maxRetryDelay.
+	RetryDelay time.Duration
+	BatchSize  int
+	Workers    int
+}
+
+// Dispatcher POSTs due deliveries to their subscriptions. Any 2xx response
+// is a success; anything else, including timeouts, is retried with
+// exponential backoff until MaxAttempts, after which the delivery is dead.
+// Deliveries may arrive more than once and out of order: receivers
+// deduplicate and order by the event ID.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	logger *log.Logger
+	cfg    Config
+	now    func() time.Time
+}
+
+func NewDispatcher(store Store, client *http.Client, logger *log.Logger, cfg Config) *Dispatcher {
+	return &Dispatcher{store: store, client: client, logger: logger, cfg: cfg, now: time.Now}
+}
+
+// Run dispatches deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		n, err := d.DispatchOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Printf("Webhook dispatch failed: %v", err)
+		}
+
+		wait := d.cfg.PollInterval
+		if n == d.cfg.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries and returns its size.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// The lease outlasts an attempt, so a delivery is only claimed again if
+	// this instance died while sending it.
+	lease := 2*d.cfg.Timeout + time.Minute
+	deliveries, err := d.store.ClaimDueDeliveries(ctx, d.now(), lease, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sem := make(chan struct{}, max(d.cfg.Workers, 1))
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery *domain.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := d.dispatch(ctx, delivery); err != nil && ctx.Err() == nil {
+				d.logger.Printf("Webhook delivery %d failed: %v", delivery.ID, err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	sub, err := d.store.GetSubscription(ctx, delivery.SubscriptionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		// Deleted together with its deliveries in the meantime.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var statusCode int
+	if sub.Active {
+		statusCode, err = d.send(ctx, sub, delivery)
+	} else {
+		err = errors.New("subscription is disabled")
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the lease expires and the attempt is made again.
+		return nil
+	}
+
+	now := d.now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err == nil:
+		delivery.Status = domain.WebhookSucceeded
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= d.cfg.MaxAttempts || !sub.Active:
+		delivery.Status = domain.WebhookDead
+		delivery.NextAttemptAt = nil
+		delivery.LastError = err.Error()
+	default:
+		next := now.Add(d.retryDelay(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = err.Error()
+	}
+
+	if err := d.store.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		return fmt.Errorf("failed to record attempt: %w", err)
+	}
+	return err
+}
+
+// retryDelay returns the wait after the given number of failed attempts.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.cfg.RetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// send POSTs the delivery and returns the response status, with an error
+// unless it is 2xx.
+func (d *Dispatcher) send(ctx context.Context, sub *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "employee-service-webhooks")
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set("X-Webhook-Event-Id", strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// The body is not kept: LastError is shown to API clients, who must not
+	// be able to read responses through a subscription.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainLength))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, errors.New(resp.Status)
+}
@@ -0,0 +1,218 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Hexes-rgb/employee-service/internal/domain"
+	"github.com/Hexes-rgb/employee-service/internal/repository/memory"
+	"github.com/Hexes-rgb/employee-service/internal/service"
+	"github.com/Hexes-rgb/employee-service/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a webhook endpoint that answers with the queued statuses, then
+// with 204, and verifies every signature.
+type receiver struct {
+	t      *testing.T
+	secret string
+
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	assert.NoError(rc.t, webhook.Verify(rc.secret, r.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute))
+	assert.Equal(rc.t, "EmployeeCreated", r.Header.Get("X-Webhook-Event"))
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusNoContent
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	const secret = "0123456789abcdef0123"
+
+	setup := func(t *testing.T, statuses ...int) (*service.WebhookService, *webhook.Dispatcher, *memory.WebhookRepo, *receiver, *domain.WebhookSubscription) {
+		store := memory.NewStore()
+		companyID, err := memory.NewCompanyRepo(store).Create(ctx, &domain.Company{LegalName: "Acme", TaxID: "1", DefaultCountry: "RU", Active: true})
+		require.NoError(t, err)
+
+		rc := &receiver{t: t, secret: secret, statuses: statuses}
+		srv := httptest.NewServer(rc)
+		t.Cleanup(srv.Close)
+
+		repo := memory.NewWebhookRepo(store)
+		svc := service.NewWebhookService(repo, memory.NewCompanyRepo(store))
+		sub, err := svc.CreateSubscription(ctx, &domain.WebhookSubscription{
+			CompanyID:  companyID,
+			URL:        srv.URL,
+			Secret:     secret,
+			EventTypes: []domain.EventType{domain.EventEmployeeCreated},
+		})
+		require.NoError(t, err)
+
+		for i, typ := range []domain.EventType{domain.EventEmployeeCreated, domain.EventEmployeeDeleted} {
+			err := svc.EnqueueEvent(ctx, &domain.Event{
+				ID: int64(i + 1), Type: typ, AggregateType: domain.AggregateEmployee, AggregateID: 1, CompanyID: companyID,
+				Payload: json.RawMessage(`{}`),
+			})
+			require.NoError(t, err)
+		}
+
+		dispatcher := webhook.NewDispatcher(repo, srv.Client(), log.New(io.Discard, "", 0), webhook.Config{
+			Timeout:     time.Second,
+			MaxAttempts: 2,
+			// Due at once, so that tests do not have to wait.
+			RetryDelay: -time.Hour,
+			BatchSize:  10,
+			Workers:    2,
+		})
+		return svc, dispatcher, repo, rc, sub
+	}
+
+	deliveries := func(t *testing.T, svc *service.WebhookService, sub *domain.WebhookSubscription) []*domain.WebhookDelivery {
+		t.Helper()
+		list, err := svc.ListDeliveries(ctx, sub.CompanyID, sub.ID, "", 0)
+		require.NoError(t, err)
+		return list
+	}
+
+	t.Run("Success: filtered event is delivered once, signed", func(t *testing.T) {
+		svc, dispatcher, _, rc, sub := setup(t)
+
+		n, err := dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		n, err = dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		require.Len(t, rc.bodies, 1)
+		var event domain.Event
+		require.NoError(t, json.Unmarshal(rc.bodies[0], &event))
+		assert.Equal(t, int64(1), event.ID)
+
+		list := deliveries(t, svc, sub)
+		require.Len(t, list, 1)
+		assert.Equal(t, domain.WebhookSucceeded, list[0].Status)
+		assert.Equal(t, http.StatusNoContent, list[0].LastStatusCode)
+		assert.NotNil(t, list[0].DeliveredAt)
+	})
+
+	t.Run("Success: event relayed twice is delivered once", func(t *testing.T) {
+		svc, _, _, _, sub := setup(t)
+		require.NoError(t, svc.EnqueueEvent(ctx, &domain.Event{ID: 1, Type: domain.EventEmployeeCreated, CompanyID: sub.CompanyID}))
+
+		assert.Len(t, deliveries(t, svc, sub), 1)
+	})
+
+	t.Run("Error: failing endpoint is retried, then dead, then replayed", func(t *testing.T) {
+		svc, dispatcher, _, rc, sub := setup(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+		_, err := dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		list := deliveries(t, svc, sub)
+		assert.Equal(t, domain.WebhookPending, list[0].Status)
+		assert.Equal(t, 1, list[0].Attempts)
+		assert.Equal(t, http.StatusInternalServerError, list[0].LastStatusCode)
+
+		_, err = dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		list = deliveries(t, svc, sub)
+		assert.Equal(t, domain.WebhookDead, list[0].Status)
+		assert.Contains(t, list[0].LastError, "502")
+
+		n, err := dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "dead deliveries are not retried")
+
+		replayed, err := svc.ReplayDelivery(ctx, sub.CompanyID, sub.ID, list[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.WebhookPending, replayed.Status)
+
+		_, err = dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		list = deliveries(t, svc, sub)
+		assert.Equal(t, domain.WebhookSucceeded, list[0].Status)
+		assert.Len(t, rc.bodies, 3)
+
+		_, err = svc.ReplayDelivery(ctx, sub.CompanyID, sub.ID, list[0].ID)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("Error: disabled subscription gets no deliveries", func(t *testing.T) {
+		svc, dispatcher, _, rc, sub := setup(t)
+		active := false
+		_, err := svc.UpdateSubscription(ctx, sub.CompanyID, sub.ID, &domain.WebhookSubscriptionPatch{Active: &active})
+		require.NoError(t, err)
+
+		_, err = dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+
+		list := deliveries(t, svc, sub)
+		assert.Equal(t, domain.WebhookDead, list[0].Status)
+		assert.Empty(t, rc.bodies)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		assert.Equal(t, public, webhook.PublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer srv.Close()
+
+	client := webhook.NewClient()
+	_, err := client.Post(srv.URL, "application/json", nil)
+	assert.ErrorContains(t, err, "is not allowed")
+	assert.Zero(t, hits, "loopback is refused before connecting")
+
+	assert.ErrorIs(t, client.CheckRedirect(nil, nil), http.ErrUseLastResponse, "redirects are not followed")
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":1}`)
+	header := webhook.Sign("secret", now, body)
+
+	assert.NoError(t, webhook.Verify("secret", header, body, now, time.Minute))
+	assert.Error(t, webhook.Verify("other", header, body, now, time.Minute))
+	assert.Error(t, webhook.Verify("secret", header, []byte(`{"id":2}`), now, time.Minute))
+	assert.Error(t, webhook.Verify("secret", header, body, now.Add(time.Hour), time.Minute))
+	assert.Error(t, webhook.Verify("secret", "garbage", body, now, time.Minute))
+}